@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
+	"fullcycle-auction_go/configuration/auth"
 	"fullcycle-auction_go/configuration/database/mongodb"
 	"fullcycle-auction_go/internal/infra/api"
 	"fullcycle-auction_go/internal/infra/database"
+	"fullcycle-auction_go/internal/infra/events"
+	"fullcycle-auction_go/internal/infra/memory"
+	"fullcycle-auction_go/internal/infra/notifier"
+	"fullcycle-auction_go/internal/infra/rpc"
+	"fullcycle-auction_go/internal/infra/storage"
+	"fullcycle-auction_go/internal/infra/webhook"
+	"fullcycle-auction_go/internal/repository"
 	"fullcycle-auction_go/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
 	"log"
+	"net"
+	"os"
 )
 
 func main() {
@@ -17,30 +29,178 @@ func main() {
 		return
 	}
 
-	router := makeRouter(databaseConnection)
+	jwtVerifier, err := auth.NewJWTVerifier()
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	policy, err := auth.NewPolicy()
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	imageStorage, err := storage.NewImageStorage()
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	auctionNotifier, err := notifier.NewNotifier()
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+
+	router, grpcServer := makeServers(databaseConnection, jwtVerifier, policy, imageStorage, auctionNotifier)
+
+	listener, err := net.Listen("tcp", getGRPCAddress())
+	if err != nil {
+		log.Fatal(err.Error())
+		return
+	}
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("Error trying to start gRPC server: %s", err.Error())
+		}
+	}()
+
 	if err = router.Run(":8080"); err != nil {
 		log.Fatalf("Error trying to start server: %s", err.Error())
 	}
 }
 
-func makeRouter(databaseConnection *mongo.Database) *gin.Engine {
+// makeServers builds the REST router and the gRPC server on top of the same
+// use cases.
+func makeServers(
+	databaseConnection *mongo.Database,
+	jwtVerifier *auth.JWTVerifier,
+	policy *auth.Policy,
+	imageStorage repository.ImageStorage,
+	auctionNotifier repository.Notifier,
+) (*gin.Engine, *grpc.Server) {
 	router := gin.Default()
+	if localStorage, ok := imageStorage.(*storage.LocalImageStorage); ok {
+		router.Static(localStorage.BaseURL(), localStorage.Dir())
+	}
 
-	auctionRepository := database.NewAuctionRepository(databaseConnection)
+	auctionEvents := events.NewBus()
+	auctionRepository := database.NewAuctionRepository(databaseConnection, auctionEvents)
 	bidRepository := database.NewBidRepository(databaseConnection, auctionRepository)
-	userRepository := database.NewUserRepository(databaseConnection)
+	userRepository := memory.NewCachedUserRepository(database.NewUserRepository(databaseConnection))
+	apiKeyRepository := database.NewAPIKeyRepository(databaseConnection)
+	idempotencyRepository := database.NewIdempotencyRepository(databaseConnection)
+	auctionSearchRepository := database.NewAuctionSearchRepository(databaseConnection)
+	categoryRepository := database.NewCategoryRepository(databaseConnection)
+	questionRepository := database.NewQuestionRepository(databaseConnection, auctionRepository)
+	watchlistRepository := database.NewWatchlistRepository(databaseConnection, auctionRepository)
+	notificationPreferencesRepository := database.NewNotificationPreferencesRepository(databaseConnection)
+	webhookRepository := database.NewWebhookRepository(databaseConnection)
+
+	apiKeyUseCase := usecase.NewAPIKeyUseCase(apiKeyRepository, userRepository)
+	authUseCase := usecase.NewAuthUseCase(jwtVerifier, apiKeyUseCase)
+	optionalAuth := api.OptionalAuthMiddleware(authUseCase)
+	idempotencyUseCase := usecase.NewIdempotencyUseCase(idempotencyRepository)
+	idempotency := api.IdempotencyMiddleware(idempotencyUseCase)
+
+	userUseCase := usecase.NewUserUseCase(userRepository)
+	userController := api.NewUserController(userUseCase, policy)
+	bidUseCase := usecase.NewBidUseCase(bidRepository, auctionRepository, userRepository)
+	go auctionRepository.StartAuctionCloser(context.Background(), bidUseCase)
+	auctionUseCase := usecase.NewAuctionUseCase(
+		auctionRepository, bidRepository, bidUseCase, userRepository, auctionSearchRepository, categoryRepository)
+	auctionController := api.NewAuctionController(auctionUseCase, policy)
+	auctionImageController := api.NewAuctionImageController(
+		auctionUseCase, usecase.NewAuctionImageUseCase(auctionRepository, imageStorage), policy)
+	questionController := api.NewQuestionController(
+		usecase.NewQuestionUseCase(questionRepository, auctionRepository), auctionUseCase, policy)
+	watchlistController := api.NewWatchlistController(
+		usecase.NewWatchlistUseCase(watchlistRepository, auctionRepository, userRepository), policy)
+	notificationUseCase := usecase.NewNotificationUseCase(
+		auctionNotifier, notificationPreferencesRepository, auctionRepository, userRepository)
+	auctionEvents.Subscribe("notifications", notificationUseCase.HandleAuctionEvent)
+	notificationController := api.NewNotificationController(notificationUseCase, policy)
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepository, auctionRepository, webhook.NewHTTPWebhookSender())
+	auctionEvents.Subscribe("webhooks", webhookUseCase.HandleAuctionEvent)
+	webhookController := api.NewWebhookController(webhookUseCase, policy)
+	auctionStreamUseCase := usecase.NewAuctionStreamUseCase(auctionRepository)
+	auctionEvents.Subscribe("auction-stream", auctionStreamUseCase.HandleAuctionEvent)
+	auctionStreamController := api.NewAuctionStreamController(auctionStreamUseCase, auctionUseCase, policy)
+	apiKeyController := api.NewAPIKeyController(apiKeyUseCase, policy)
+	categoryUseCase := usecase.NewCategoryUseCase(categoryRepository, auctionRepository)
+	categoryUseCase.BackfillAuctionCategories(context.Background())
+	categoryController := api.NewCategoryController(categoryUseCase, policy)
+	bidController := api.NewBidController(bidUseCase, auctionUseCase, policy)
+	liveBiddingController := api.NewLiveBiddingController(
+		bidUseCase, auctionUseCase, auctionStreamUseCase, authUseCase, userUseCase, idempotencyUseCase, policy)
 
-	userController := api.NewUserController(usecase.NewUserUseCase(userRepository))
-	auctionController := api.NewAuctionController(usecase.NewAuctionUseCase(auctionRepository, bidRepository))
-	bidController := api.NewBidController(usecase.NewBidUseCase(bidRepository))
+	router.GET("/auction", optionalAuth, auctionController.FindAuctions)
+	router.GET("/auction/search", auctionController.SearchAuctions)
+	router.GET("/auction/live", liveBiddingController.Connect)
+	router.GET("/auction/:auctionId", optionalAuth, auctionController.FindAuctionById)
+	router.GET("/auction/winner/:auctionId", optionalAuth, auctionController.FindWinningBidByAuctionId)
+	router.GET("/auction/:auctionId/questions", optionalAuth, questionController.FindQuestions)
+	router.GET("/auction/:auctionId/events", optionalAuth, auctionStreamController.StreamEvents)
+	router.GET("/bid/:auctionId", optionalAuth, bidController.FindBidByAuctionId)
+	router.POST("/user", userController.CreateUser)
+	router.GET("/user", optionalAuth, userController.FindUsers)
+	router.GET("/user/:userId", optionalAuth, userController.FindUserById)
+	router.GET("/user/:userId/auctions", optionalAuth, auctionController.FindAuctionsBySellerId)
+	router.GET("/category", categoryController.FindCategories)
+	router.GET("/category/:categoryId", categoryController.FindCategoryById)
 
-	router.GET("/auction", auctionController.FindAuctions)
-	router.GET("/auction/:auctionId", auctionController.FindAuctionById)
-	router.POST("/auction", auctionController.CreateAuction)
-	router.GET("/auction/winner/:auctionId", auctionController.FindWinningBidByAuctionId)
-	router.POST("/bid", bidController.CreateBid)
-	router.GET("/bid/:auctionId", bidController.FindBidByAuctionId)
-	router.GET("/user/:userId", userController.FindUserById)
+	authenticated := router.Group("", api.AuthMiddleware(authUseCase))
+	authenticated.POST("/auction", idempotency, auctionController.CreateAuction)
+	authenticated.PATCH("/auction/:auctionId", auctionController.UpdateAuction)
+	authenticated.POST("/auction/:auctionId/submit", auctionController.SubmitAuction)
+	authenticated.POST("/auction/:auctionId/cancel", auctionController.CancelAuction)
+	authenticated.POST("/auction/:auctionId/images", auctionImageController.UploadImage)
+	authenticated.PUT("/auction/:auctionId/images/order", auctionImageController.ReorderImages)
+	authenticated.POST("/auction/:auctionId/images/:imageId/primary", auctionImageController.SetPrimaryImage)
+	authenticated.DELETE("/auction/:auctionId/images/:imageId", auctionImageController.DeleteImage)
+	authenticated.POST("/auction/:auctionId/questions", questionController.AskQuestion)
+	authenticated.POST("/auction/:auctionId/questions/:questionId/answer", questionController.AnswerQuestion)
+	authenticated.POST("/auction/:auctionId/questions/:questionId/flag", questionController.FlagQuestion)
+	authenticated.POST("/bid", idempotency, bidController.CreateBid)
+	authenticated.PATCH("/user/:userId", userController.UpdateUser)
+	authenticated.GET("/user/:userId/notification-preferences", notificationController.FindPreferences)
+	authenticated.PUT("/user/:userId/notification-preferences", notificationController.UpdatePreferences)
+	authenticated.GET("/user/:userId/watchlist", watchlistController.FindWatchlist)
+	authenticated.POST("/user/:userId/watchlist/:auctionId", watchlistController.WatchAuction)
+	authenticated.DELETE("/user/:userId/watchlist/:auctionId", watchlistController.UnwatchAuction)
+	authenticated.GET("/moderation/auction", auctionController.FindPendingAuctions)
+	authenticated.POST("/moderation/auction/:auctionId/approve", auctionController.ApproveAuction)
+	authenticated.POST("/moderation/auction/:auctionId/reject", auctionController.RejectAuction)
+	authenticated.POST("/moderation/auction/:auctionId/close", auctionController.CloseAuction)
+	authenticated.POST("/moderation/auction/:auctionId/reopen", auctionController.ReopenAuction)
+	authenticated.GET("/moderation/question", questionController.FindFlaggedQuestions)
+	authenticated.POST("/moderation/question/:questionId/hide", questionController.HideQuestion)
+	authenticated.POST("/moderation/question/:questionId/restore", questionController.RestoreQuestion)
+	authenticated.POST("/api-key", apiKeyController.CreateAPIKey)
+	authenticated.GET("/api-key", apiKeyController.FindAPIKeys)
+	authenticated.POST("/api-key/:apiKeyId/rotate", apiKeyController.RotateAPIKey)
+	authenticated.DELETE("/api-key/:apiKeyId", apiKeyController.RevokeAPIKey)
+	authenticated.POST("/category", categoryController.CreateCategory)
+	authenticated.PATCH("/category/:categoryId", categoryController.UpdateCategory)
+	authenticated.DELETE("/category/:categoryId", categoryController.DeleteCategory)
+	authenticated.POST("/webhook", webhookController.CreateWebhook)
+	authenticated.GET("/webhook", webhookController.FindWebhooks)
+	authenticated.GET("/webhook/:webhookId", webhookController.FindWebhookById)
+	authenticated.DELETE("/webhook/:webhookId", webhookController.DeleteWebhook)
+	authenticated.GET("/webhook/:webhookId/deliveries", webhookController.FindDeliveries)
+	authenticated.POST("/webhook/:webhookId/deliveries/:deliveryId/redeliver", webhookController.Redeliver)
 
-	return router
+	grpcServer := rpc.NewServer(
+		auctionUseCase, bidUseCase, userUseCase, auctionStreamUseCase, authUseCase, policy)
+
+	return router, grpcServer
+}
+
+func getGRPCAddress() string {
+	address := os.Getenv("GRPC_ADDRESS")
+	if address == "" {
+		return ":9090"
+	}
+	return address
 }
@@ -5,8 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"fullcycle-auction_go/configuration/auth"
 	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/infra/notifier"
+	"fullcycle-auction_go/internal/infra/storage"
 	"fullcycle-auction_go/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/mongodb"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -26,11 +30,22 @@ func TestAuctionFlow(t *testing.T) {
 	require.NoError(t, err)
 	defer mongoContainer.Terminate(ctx)
 
+	t.Setenv("JWT_HS256_SECRET", testJWTSecret)
+	jwtVerifier, err := auth.NewJWTVerifier()
+	require.NoError(t, err)
+
 	databaseConnection := mongoClient.Database("test")
-	router := makeRouter(databaseConnection)
+	policy, err := auth.NewPolicy()
+	require.NoError(t, err)
 
-	auctionJSON := `{ "product_name": "Product Test", "category": "Category Test", "description": "Description Test", "condition": 1 }`
-	req := httptest.NewRequest("POST", "/auction", bytes.NewBufferString(auctionJSON))
+	t.Setenv("IMAGE_STORAGE_DIR", t.TempDir())
+	imageStorage, err := storage.NewLocalImageStorage()
+	require.NoError(t, err)
+
+	router, _ := makeServers(databaseConnection, jwtVerifier, policy, imageStorage, notifier.NewLogNotifier())
+
+	userJSON := `{ "name": "Seller Test", "email": "seller.test@example.com" }`
+	req := httptest.NewRequest("POST", "/user", bytes.NewBufferString(userJSON))
 	req.Header.Set("Content-Type", "application/json")
 
 	rec := httptest.NewRecorder()
@@ -38,18 +53,53 @@ func TestAuctionFlow(t *testing.T) {
 
 	require.Equal(t, http.StatusCreated, rec.Code)
 
-	req = httptest.NewRequest("GET", "/auction?status=0", nil)
+	var seller usecase.UserOutputDTO
+	err = json.Unmarshal(rec.Body.Bytes(), &seller)
+	require.NoError(t, err)
+
+	authorization := "Bearer " + signToken(t, seller.Id, auth.RoleSeller, auth.RoleAdmin)
+
+	categoryJSON := `{ "name": "Category Test" }`
+	req = httptest.NewRequest("POST", "/category", bytes.NewBufferString(categoryJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+
 	rec = httptest.NewRecorder()
 	router.ServeHTTP(rec, req)
 
-	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, http.StatusCreated, rec.Code)
 
-	var auctions []usecase.AuctionOutputDTO
-	err = json.Unmarshal(rec.Body.Bytes(), &auctions)
+	auctionJSON := `{ "product_name": "Product Test", "category": "Category Test", "description": "Description Test", "condition": 1 }`
+	req = httptest.NewRequest("POST", "/auction", bytes.NewBufferString(auctionJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", authorization)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var createdAuction usecase.AuctionOutputDTO
+	err = json.Unmarshal(rec.Body.Bytes(), &createdAuction)
 	require.NoError(t, err)
-	require.NotEmpty(t, auctions)
+	require.Equal(t, usecase.AuctionStatus(entity.Draft), createdAuction.Status)
+
+	auctionId := createdAuction.Id
+	require.Equal(t, fmt.Sprintf("/auction/%s", auctionId), rec.Header().Get("Location"))
+
+	req = httptest.NewRequest("POST", fmt.Sprintf("/auction/%s/submit", auctionId), nil)
+	req.Header.Set("Authorization", authorization)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
 
-	auctionId := auctions[0].Id
+	req = httptest.NewRequest("POST", fmt.Sprintf("/moderation/auction/%s/approve", auctionId), nil)
+	req.Header.Set("Authorization", authorization)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
 
 	time.Sleep(1 * time.Minute)
 
@@ -66,6 +116,20 @@ func TestAuctionFlow(t *testing.T) {
 	require.Equal(t, entity.Completed, auction.Status)
 }
 
+const testJWTSecret = "test-secret"
+
+func signToken(t *testing.T, subject string, roles ...string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	})
+
+	signed, err := token.SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return signed
+}
+
 func startMongoContainer(t *testing.T, ctx context.Context) (*mongodb.MongoDBContainer, string) {
 	mongoContainer, err := mongodb.Run(ctx, "mongo:6")
 	require.NoError(t, err)
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	_jwtHS256Secret    = "JWT_HS256_SECRET"
+	_jwtRS256PublicKey = "JWT_RS256_PUBLIC_KEY_FILE"
+	_jwtJWKSFile       = "JWT_JWKS_FILE"
+	_jwtIssuer         = "JWT_ISSUER"
+	_jwtAudience       = "JWT_AUDIENCE"
+	_pemKeyId          = ""
+
+	// placeholderSecret is the value shipped in the sample environment, which
+	// must never end up verifying tokens.
+	placeholderSecret = "change-me"
+)
+
+type (
+	// JWTVerifier validates HS256 tokens against a shared secret and RS256
+	// tokens against public keys loaded from a PEM file or a local JWKS file.
+	JWTVerifier struct {
+		hmacSecret []byte
+		rsaKeys    map[string]*rsa.PublicKey
+		parser     *jwt.Parser
+	}
+
+	// Principal is the authenticated user and its roles. ExpiresAt is zero
+	// for credentials that do not expire.
+	Principal struct {
+		UserId    string
+		Roles     []string
+		ExpiresAt time.Time
+	}
+
+	claims struct {
+		jwt.RegisteredClaims
+		Roles []string `json:"roles"`
+	}
+
+	jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+
+	principalKey struct{}
+)
+
+// NewJWTVerifier fails when no key is configured, or when the HS256 secret is
+// still the placeholder of the sample environment.
+func NewJWTVerifier() (*JWTVerifier, error) {
+	if os.Getenv(_jwtHS256Secret) == placeholderSecret {
+		return nil, fmt.Errorf("%s is still set to the placeholder value", _jwtHS256Secret)
+	}
+
+	verifier := &JWTVerifier{
+		hmacSecret: []byte(os.Getenv(_jwtHS256Secret)),
+		rsaKeys:    make(map[string]*rsa.PublicKey),
+	}
+
+	if publicKeyFile := os.Getenv(_jwtRS256PublicKey); publicKeyFile != "" {
+		content, err := os.ReadFile(publicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading RS256 public key: %w", err)
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing RS256 public key: %w", err)
+		}
+		verifier.rsaKeys[_pemKeyId] = publicKey
+	}
+
+	if jwksFile := os.Getenv(_jwtJWKSFile); jwksFile != "" {
+		if err := verifier.loadJWKS(jwksFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(verifier.hmacSecret) == 0 && len(verifier.rsaKeys) == 0 {
+		return nil, errors.New("no JWT verification key configured")
+	}
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+	}
+	if issuer := os.Getenv(_jwtIssuer); issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(issuer))
+	}
+	if audience := os.Getenv(_jwtAudience); audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(audience))
+	}
+	verifier.parser = jwt.NewParser(parserOptions...)
+
+	return verifier, nil
+}
+
+// Verify checks the token signature and standard claims and returns the
+// principal it identifies.
+func (v *JWTVerifier) Verify(tokenString string) (*Principal, error) {
+	var tokenClaims claims
+	if _, err := v.parser.ParseWithClaims(tokenString, &tokenClaims, v.keyFor); err != nil {
+		return nil, err
+	}
+	if tokenClaims.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	return &Principal{
+		UserId:    tokenClaims.Subject,
+		Roles:     tokenClaims.Roles,
+		ExpiresAt: tokenClaims.ExpiresAt.Time,
+	}, nil
+}
+
+func (v *JWTVerifier) keyFor(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if len(v.hmacSecret) == 0 {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		return v.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		keyId, _ := token.Header["kid"].(string)
+		if publicKey, ok := v.rsaKeys[keyId]; ok {
+			return publicKey, nil
+		}
+		if publicKey, ok := v.rsaKeys[_pemKeyId]; ok {
+			return publicKey, nil
+		}
+		return nil, fmt.Errorf("unknown RS256 key id %q", keyId)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+func (v *JWTVerifier) loadJWKS(jwksFile string) error {
+	content, err := os.ReadFile(jwksFile)
+	if err != nil {
+		return fmt.Errorf("reading JWKS file: %w", err)
+	}
+
+	var keySet jwks
+	if err := json.Unmarshal(content, &keySet); err != nil {
+		return fmt.Errorf("parsing JWKS file: %w", err)
+	}
+
+	for _, key := range keySet.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+
+		modulus, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return fmt.Errorf("decoding modulus of key %q: %w", key.Kid, err)
+		}
+		exponent, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return fmt.Errorf("decoding exponent of key %q: %w", key.Kid, err)
+		}
+
+		v.rsaKeys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(modulus),
+			E: int(new(big.Int).SetBytes(exponent).Int64()),
+		}
+	}
+
+	return nil
+}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Expired reports whether the credentials identifying the principal are no
+// longer valid at now.
+func (p *Principal) Expired(now time.Time) bool {
+	return !p.ExpiresAt.IsZero() && !now.Before(p.ExpiresAt)
+}
+
+// SubjectFromContext returns the authenticated user id, if any.
+func SubjectFromContext(ctx context.Context) (string, bool) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return principal.UserId, true
+}
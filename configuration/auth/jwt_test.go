@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret"
+
+func TestNewJWTVerifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		publicKey bool
+		wantErr   bool
+	}{
+		{name: "HS256 secret", secret: testSecret},
+		{name: "RS256 key only", publicKey: true},
+		{name: "no key at all", wantErr: true},
+		{name: "placeholder secret", secret: "change-me", wantErr: true},
+		{name: "placeholder secret next to RS256 key", secret: "change-me", publicKey: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(_jwtHS256Secret, tt.secret)
+			t.Setenv(_jwtJWKSFile, "")
+			t.Setenv(_jwtRS256PublicKey, "")
+			if tt.publicKey {
+				_, keyFile := writeTestRSAKey(t)
+				t.Setenv(_jwtRS256PublicKey, keyFile)
+			}
+
+			verifier, err := NewJWTVerifier()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, verifier)
+		})
+	}
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	t.Setenv(_jwtHS256Secret, testSecret)
+	t.Setenv(_jwtJWKSFile, "")
+	privateKey, keyFile := writeTestRSAKey(t)
+	t.Setenv(_jwtRS256PublicKey, keyFile)
+	verifier, err := NewJWTVerifier()
+	require.NoError(t, err)
+
+	validClaims := jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(time.Hour).Unix(), "roles": []string{RoleBidder}}
+
+	tests := []struct {
+		name    string
+		token   func() (string, error)
+		wantErr bool
+	}{
+		{name: "HS256", token: func() (string, error) {
+			return jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims).SignedString([]byte(testSecret))
+		}},
+		{name: "RS256", token: func() (string, error) {
+			return jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims).SignedString(privateKey)
+		}},
+		{name: "wrong secret", wantErr: true, token: func() (string, error) {
+			return jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims).SignedString([]byte("other-secret"))
+		}},
+		{name: "expired", wantErr: true, token: func() (string, error) {
+			claims := jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(-time.Hour).Unix()}
+			return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		}},
+		{name: "no expiry", wantErr: true, token: func() (string, error) {
+			claims := jwt.MapClaims{"sub": "user-1"}
+			return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		}},
+		{name: "no subject", wantErr: true, token: func() (string, error) {
+			claims := jwt.MapClaims{"exp": time.Now().Add(time.Hour).Unix()}
+			return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.token()
+			require.NoError(t, err)
+
+			principal, err := verifier.Verify(token)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "user-1", principal.UserId)
+			require.Equal(t, []string{RoleBidder}, principal.Roles)
+			require.Equal(t, time.Unix(validClaims["exp"].(int64), 0), principal.ExpiresAt)
+		})
+	}
+}
+
+func TestPrincipalExpired(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      bool
+	}{
+		{name: "valid token", expiresAt: now.Add(time.Minute)},
+		{name: "expired token", expiresAt: now.Add(-time.Minute), want: true},
+		{name: "expiring now", expiresAt: now, want: true},
+		{name: "no expiry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := &Principal{UserId: "user-1", ExpiresAt: tt.expiresAt}
+			require.Equal(t, tt.want, principal.Expired(now))
+		})
+	}
+}
+
+func writeTestRSAKey(t *testing.T) (*rsa.PrivateKey, string) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	keyFile := filepath.Join(t.TempDir(), "public.pem")
+	content := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+	require.NoError(t, os.WriteFile(keyFile, content, 0o600))
+	return privateKey, keyFile
+}
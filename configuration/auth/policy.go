@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/internal/internal_error"
+	"os"
+	"slices"
+)
+
+const _authorizationPolicyFile = "AUTHORIZATION_POLICY_FILE"
+
+const (
+	// RoleReader is granted no action by the default rules, so it only reads
+	// what is public.
+	RoleReader = "reader"
+	RoleBidder = "bidder"
+	RoleSeller = "seller"
+	RoleAdmin  = "admin"
+)
+
+const (
+	ActionAuctionCreate    = "auction:create"
+	ActionAuctionUpdate    = "auction:update"
+	ActionAuctionCancel    = "auction:cancel"
+	ActionAuctionSubmit    = "auction:submit"
+	ActionAuctionReadDraft = "auction:read_draft"
+	ActionAuctionModerate  = "auction:moderate"
+	ActionAuctionClose     = "auction:close"
+	ActionAuctionReopen    = "auction:reopen"
+	ActionBidCreate        = "bid:create"
+	ActionUserUpdate       = "user:update"
+	ActionUserModerate     = "user:moderate"
+	ActionUserReadPrivate  = "user:read_private"
+	ActionAPIKeyManage     = "api_key:manage"
+	ActionCategoryManage   = "category:manage"
+	ActionQuestionAsk      = "question:ask"
+	ActionQuestionAnswer   = "question:answer"
+	ActionQuestionFlag     = "question:flag"
+	ActionQuestionModerate = "question:moderate"
+	ActionWatchlistManage  = "watchlist:manage"
+	ActionWebhookManage    = "webhook:manage"
+)
+
+type (
+	// Policy maps each action to the roles allowed to perform it. Roles listed
+	// in OwnerOnly are only allowed on resources owned by the caller.
+	Policy struct {
+		rules map[string]Rule
+	}
+
+	Rule struct {
+		Roles     []string `json:"roles"`
+		OwnerOnly []string `json:"owner_only"`
+	}
+)
+
+var defaultRules = map[string]Rule{
+	ActionAuctionCreate:    {Roles: []string{RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleSeller}},
+	ActionAuctionUpdate:    {Roles: []string{RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleSeller}},
+	ActionAuctionCancel:    {Roles: []string{RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleSeller}},
+	ActionAuctionSubmit:    {Roles: []string{RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleSeller}},
+	ActionAuctionReadDraft: {Roles: []string{RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleSeller}},
+	ActionAuctionModerate:  {Roles: []string{RoleAdmin}},
+	ActionAuctionClose:     {Roles: []string{RoleAdmin}},
+	ActionAuctionReopen:    {Roles: []string{RoleAdmin}},
+	ActionBidCreate:        {Roles: []string{RoleBidder}},
+	ActionUserUpdate:       {Roles: []string{RoleBidder, RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleBidder, RoleSeller}},
+	ActionUserModerate:     {Roles: []string{RoleAdmin}},
+	ActionUserReadPrivate:  {Roles: []string{RoleBidder, RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleBidder, RoleSeller}},
+	ActionAPIKeyManage:     {Roles: []string{RoleAdmin}},
+	ActionCategoryManage:   {Roles: []string{RoleAdmin}},
+	ActionQuestionAsk:      {Roles: []string{RoleBidder}},
+	ActionQuestionAnswer:   {Roles: []string{RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleSeller}},
+	ActionQuestionFlag:     {Roles: []string{RoleBidder, RoleSeller, RoleAdmin}},
+	ActionQuestionModerate: {Roles: []string{RoleAdmin}},
+	ActionWatchlistManage:  {Roles: []string{RoleBidder, RoleSeller, RoleAdmin}, OwnerOnly: []string{RoleBidder, RoleSeller}},
+	ActionWebhookManage:    {Roles: []string{RoleAdmin}},
+}
+
+// NewPolicy loads the rules from AUTHORIZATION_POLICY_FILE, a JSON object of
+// action to rule, falling back to the built-in rules when it is not set.
+func NewPolicy() (*Policy, error) {
+	policyFile := os.Getenv(_authorizationPolicyFile)
+	if policyFile == "" {
+		return &Policy{rules: defaultRules}, nil
+	}
+
+	content, err := os.ReadFile(policyFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading authorization policy: %w", err)
+	}
+
+	var rules map[string]Rule
+	if err := json.Unmarshal(content, &rules); err != nil {
+		return nil, fmt.Errorf("parsing authorization policy: %w", err)
+	}
+
+	return &Policy{rules: rules}, nil
+}
+
+// Authorize checks that the caller in ctx may perform action on a resource
+// owned by ownerId. Unknown actions are denied.
+func (p *Policy) Authorize(ctx context.Context, action, ownerId string) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return internal_error.NewForbiddenError("Authentication is required for this action")
+	}
+
+	rule, ok := p.rules[action]
+	if ok {
+		for _, role := range principal.Roles {
+			if !slices.Contains(rule.Roles, role) {
+				continue
+			}
+			if !slices.Contains(rule.OwnerOnly, role) || ownerId == principal.UserId {
+				return nil
+			}
+		}
+	}
+
+	return internal_error.NewForbiddenError(fmt.Sprintf("Not allowed to perform %s", action))
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicyAuthorize(t *testing.T) {
+	t.Setenv(_authorizationPolicyFile, "")
+	policy, err := NewPolicy()
+	require.NoError(t, err)
+
+	seller := &Principal{UserId: "seller-1", Roles: []string{RoleSeller}}
+	admin := &Principal{UserId: "admin-1", Roles: []string{RoleAdmin}}
+
+	tests := []struct {
+		name      string
+		principal *Principal
+		action    string
+		ownerId   string
+		wantErr   bool
+	}{
+		{name: "no principal", action: ActionAuctionCreate, ownerId: "seller-1", wantErr: true},
+		{name: "owner", principal: seller, action: ActionAuctionCancel, ownerId: "seller-1"},
+		{name: "owner-only role on another resource", principal: seller, action: ActionAuctionCancel,
+			ownerId: "seller-2", wantErr: true},
+		{name: "admin on another resource", principal: admin, action: ActionAuctionCancel, ownerId: "seller-2"},
+		{name: "role not allowed", principal: seller, action: ActionAuctionModerate, wantErr: true},
+		{name: "unknown action", principal: admin, action: "auction:unknown", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = WithPrincipal(ctx, tt.principal)
+			}
+
+			err := policy.Authorize(ctx, tt.action, tt.ownerId)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNewPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+		allowed bool
+	}{
+		{name: "rules from file", content: `{"bid:create": {"roles": ["seller"]}}`, allowed: true},
+		{name: "action missing from file", content: `{}`},
+		{name: "invalid JSON", content: `{"bid:create":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policyFile := filepath.Join(t.TempDir(), "policy.json")
+			require.NoError(t, os.WriteFile(policyFile, []byte(tt.content), 0o600))
+			t.Setenv(_authorizationPolicyFile, policyFile)
+
+			policy, err := NewPolicy()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			ctx := WithPrincipal(context.Background(), &Principal{UserId: "seller-1", Roles: []string{RoleSeller}})
+			require.Equal(t, tt.allowed, policy.Authorize(ctx, ActionBidCreate, "") == nil)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv(_authorizationPolicyFile, filepath.Join(t.TempDir(), "missing.json"))
+
+		_, err := NewPolicy()
+
+		require.Error(t, err)
+	})
+}
@@ -31,3 +31,8 @@ func Error(message string, err error, tags ...zap.Field) {
 	log.Error(message, tags...)
 	log.Sync()
 }
+
+func Info(message string, tags ...zap.Field) {
+	log.Info(message, tags...)
+	log.Sync()
+}
@@ -25,13 +25,21 @@ func (r *RestErr) Error() string {
 func ConvertError(err error) *RestErr {
 	var internalError *internal_error.InternalError
 	if !errors.As(err, &internalError) {
-		return NewInternalServerError(internalError.Error())
+		return NewInternalServerError("internal error")
 	}
 	switch internalError.Err {
 	case "bad_request":
 		return NewBadRequestError(internalError.Error())
 	case "not_found":
 		return NewNotFoundError(internalError.Error())
+	case "conflict":
+		return NewConflictError(internalError.Error())
+	case "version_conflict":
+		return NewVersionConflictError(internalError.Error())
+	case "forbidden":
+		return NewForbiddenError(internalError.Error())
+	case "unauthorized":
+		return NewUnauthorizedError(internalError.Error())
 	default:
 		return NewInternalServerError(internalError.Error())
 	}
@@ -63,3 +71,48 @@ func NewNotFoundError(message string) *RestErr {
 		Causes:  nil,
 	}
 }
+
+func NewConflictError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "conflict",
+		Code:    http.StatusConflict,
+		Causes:  nil,
+	}
+}
+
+func NewVersionConflictError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "version_conflict",
+		Code:    http.StatusConflict,
+		Causes:  nil,
+	}
+}
+
+func NewUnauthorizedError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "unauthorized",
+		Code:    http.StatusUnauthorized,
+		Causes:  nil,
+	}
+}
+
+func NewForbiddenError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "forbidden",
+		Code:    http.StatusForbidden,
+		Causes:  nil,
+	}
+}
+
+func NewTooManyRequestsError(message string) *RestErr {
+	return &RestErr{
+		Message: message,
+		Err:     "too_many_requests",
+		Code:    http.StatusTooManyRequests,
+		Causes:  nil,
+	}
+}
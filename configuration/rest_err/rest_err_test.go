@@ -0,0 +1,36 @@
+package rest_err
+
+import (
+	"errors"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+func TestConvertError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    int
+		wantErr     string
+		wantMessage string
+	}{
+		{name: "internal error kind", err: internal_error.NewNotFoundError("Auction not found"),
+			wantCode: http.StatusNotFound, wantErr: "not_found", wantMessage: "Auction not found"},
+		{name: "version conflict", err: internal_error.NewVersionConflictError("Auction was modified"),
+			wantCode: http.StatusConflict, wantErr: "version_conflict", wantMessage: "Auction was modified"},
+		{name: "other error", err: errors.New("connection refused"),
+			wantCode: http.StatusInternalServerError, wantErr: "internal_server", wantMessage: "internal error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restErr := ConvertError(tt.err)
+
+			require.Equal(t, tt.wantCode, restErr.Code)
+			require.Equal(t, tt.wantErr, restErr.Err)
+			require.Equal(t, tt.wantMessage, restErr.Message)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package entity
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	APIKeyScopeRead  APIKeyScope = "read"
+	APIKeyScopeBid   APIKeyScope = "bid"
+	APIKeyScopeAdmin APIKeyScope = "admin"
+)
+
+const apiKeyPrefix = "ak_"
+
+type (
+	// APIKey is a machine credential acting on behalf of UserId. Only the
+	// SHA-256 hash of the key is kept; the plain key is shown once on issue.
+	APIKey struct {
+		Id            string
+		Name          string
+		UserId        string
+		Prefix        string
+		Hash          string
+		Scopes        []APIKeyScope
+		RotatedFromId string
+		CreatedAt     time.Time
+		RevokedAt     time.Time
+	}
+
+	APIKeyScope string
+)
+
+func CreateAPIKey(name, userId string, scopes []APIKeyScope) (*APIKey, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", internal_error.NewInternalServerError("Error trying to generate api key")
+	}
+	plainKey := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := &APIKey{
+		Id:        uuid.New().String(),
+		Name:      name,
+		UserId:    userId,
+		Prefix:    plainKey[:len(apiKeyPrefix)+6],
+		Hash:      HashAPIKey(plainKey),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+
+	if err := apiKey.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, plainKey, nil
+}
+
+func HashAPIKey(plainKey string) string {
+	hash := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func (k *APIKey) Validate() error {
+	if len(k.Name) <= 2 {
+		return internal_error.NewBadRequestError("Name is not a valid value")
+	}
+	if err := uuid.Validate(k.UserId); err != nil {
+		return internal_error.NewBadRequestError("UserId is not a valid id")
+	}
+	if len(k.Scopes) == 0 {
+		return internal_error.NewBadRequestError("At least one scope is required")
+	}
+	for _, scope := range k.Scopes {
+		if !scope.IsValid() {
+			return internal_error.NewBadRequestError("Scope is not a valid value")
+		}
+	}
+	return nil
+}
+
+func (s APIKeyScope) IsValid() bool {
+	return s == APIKeyScopeRead || s == APIKeyScopeBid || s == APIKeyScopeAdmin
+}
+
+func (k *APIKey) IsRevoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+func (k *APIKey) Revoke() error {
+	if k.IsRevoked() {
+		return internal_error.NewBadRequestError("API key is already revoked")
+	}
+
+	k.RevokedAt = time.Now()
+	return nil
+}
+
+// Rotate revokes the key and issues its replacement with the same name,
+// owner and scopes, returning the new plain key.
+func (k *APIKey) Rotate() (*APIKey, string, error) {
+	if err := k.Revoke(); err != nil {
+		return nil, "", err
+	}
+
+	rotated, plainKey, err := CreateAPIKey(k.Name, k.UserId, k.Scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	rotated.RotatedFromId = k.Id
+
+	return rotated, plainKey, nil
+}
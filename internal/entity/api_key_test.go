@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCreateAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyName string
+		userId  string
+		scopes  []APIKeyScope
+		wantErr bool
+	}{
+		{name: "bid scope", keyName: "partner", userId: uuid.NewString(), scopes: []APIKeyScope{APIKeyScopeBid}},
+		{name: "all scopes", keyName: "partner", userId: uuid.NewString(),
+			scopes: []APIKeyScope{APIKeyScopeBid, APIKeyScopeAdmin}},
+		{name: "read scope", keyName: "partner", userId: uuid.NewString(), scopes: []APIKeyScope{APIKeyScopeRead}},
+		{name: "unknown scope", keyName: "partner", userId: uuid.NewString(),
+			scopes: []APIKeyScope{"write"}, wantErr: true},
+		{name: "no scope", keyName: "partner", userId: uuid.NewString(), wantErr: true},
+		{name: "invalid user", keyName: "partner", userId: "user", scopes: []APIKeyScope{APIKeyScopeBid}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKey, plainKey, err := CreateAPIKey(tt.keyName, tt.userId, tt.scopes)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, HashAPIKey(plainKey), apiKey.Hash)
+			require.Equal(t, plainKey[:len(apiKey.Prefix)], apiKey.Prefix)
+		})
+	}
+}
+
+func TestAPIKeyRotate(t *testing.T) {
+	scopes := []APIKeyScope{APIKeyScopeRead, APIKeyScopeBid}
+	apiKey := &APIKey{Id: uuid.NewString(), Name: "partner", UserId: uuid.NewString(), Scopes: scopes}
+
+	rotated, _, err := apiKey.Rotate()
+
+	require.NoError(t, err)
+	require.True(t, apiKey.IsRevoked())
+	require.Equal(t, scopes, rotated.Scopes)
+	require.Equal(t, apiKey.Id, rotated.RotatedFromId)
+}
@@ -0,0 +1,272 @@
+package entity
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/internal_error"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeInteger AttributeType = "integer"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeEnum    AttributeType = "enum"
+)
+
+const maxAttributeStringLength = 100
+
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,29}$`)
+
+type (
+	// AttributeDefinition describes one typed product attribute of a category.
+	// Enum attributes only accept one of their Options.
+	AttributeDefinition struct {
+		Key      string
+		Label    string
+		Type     AttributeType
+		Required bool
+		Options  []string
+	}
+
+	// AttributeFilter narrows a listing down by one attribute, either to a set
+	// of values or, for numeric attributes, to a range.
+	AttributeFilter struct {
+		Key    string
+		Values []interface{}
+		Min    *float64
+		Max    *float64
+	}
+
+	// AttributeFacet summarizes an attribute over the auctions of a listing:
+	// how many auctions have each value, or the range of numeric values.
+	AttributeFacet struct {
+		Definition AttributeDefinition
+		Values     []FacetValue
+		Min        *float64
+		Max        *float64
+	}
+
+	FacetValue struct {
+		Value interface{}
+		Count int64
+	}
+
+	AttributeType string
+)
+
+func (ad *AttributeDefinition) Validate() error {
+	if !attributeKeyPattern.MatchString(ad.Key) {
+		return internal_error.NewBadRequestError(
+			fmt.Sprintf("Attribute key %q must be lowercase letters, digits and underscores", ad.Key))
+	}
+
+	switch ad.Type {
+	case AttributeString, AttributeNumber, AttributeInteger, AttributeBoolean:
+		if len(ad.Options) > 0 {
+			return internal_error.NewBadRequestError(fmt.Sprintf("Attribute %s only takes options when it is an enum", ad.Key))
+		}
+	case AttributeEnum:
+		if len(ad.Options) == 0 {
+			return internal_error.NewBadRequestError(fmt.Sprintf("Enum attribute %s needs options", ad.Key))
+		}
+	default:
+		return internal_error.NewBadRequestError(fmt.Sprintf("Attribute %s has an unknown type", ad.Key))
+	}
+
+	return nil
+}
+
+// IsNumeric reports whether the attribute is filtered and summarized by range.
+func (ad *AttributeDefinition) IsNumeric() bool {
+	return ad.Type == AttributeNumber || ad.Type == AttributeInteger
+}
+
+// normalize checks a value against the definition and returns it in its
+// stored form.
+func (ad *AttributeDefinition) normalize(value interface{}) (interface{}, error) {
+	invalid := internal_error.NewBadRequestError(fmt.Sprintf("Attribute %s must be of type %s", ad.Key, ad.Type))
+
+	switch ad.Type {
+	case AttributeString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, invalid
+		}
+		text = strings.TrimSpace(text)
+		if text == "" || len(text) > maxAttributeStringLength {
+			return nil, internal_error.NewBadRequestError(fmt.Sprintf("Attribute %s is not a valid value", ad.Key))
+		}
+		return text, nil
+	case AttributeEnum:
+		text, ok := value.(string)
+		if !ok || !slices.Contains(ad.Options, text) {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %s must be one of %s", ad.Key, strings.Join(ad.Options, ", ")))
+		}
+		return text, nil
+	case AttributeBoolean:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, invalid
+		}
+		return flag, nil
+	case AttributeInteger:
+		number, ok := NumberValue(value)
+		if !ok || number != math.Trunc(number) {
+			return nil, invalid
+		}
+		return int64(number), nil
+	default:
+		number, ok := NumberValue(value)
+		if !ok {
+			return nil, invalid
+		}
+		return number, nil
+	}
+}
+
+// ParseFilter reads a comma separated list of values or, for numeric
+// attributes, a "min..max" range where either bound may be left out.
+func (ad *AttributeDefinition) ParseFilter(raw string) (*AttributeFilter, error) {
+	filter := &AttributeFilter{Key: ad.Key}
+	invalid := internal_error.NewBadRequestError(fmt.Sprintf("Filter on attribute %s is not a valid value", ad.Key))
+
+	if ad.IsNumeric() && strings.Contains(raw, "..") {
+		lower, upper, _ := strings.Cut(raw, "..")
+		bounds := []struct {
+			text   string
+			target **float64
+		}{{lower, &filter.Min}, {upper, &filter.Max}}
+		for _, bound := range bounds {
+			if bound.text == "" {
+				continue
+			}
+			number, err := strconv.ParseFloat(strings.TrimSpace(bound.text), 64)
+			if err != nil {
+				return nil, invalid
+			}
+			*bound.target = &number
+		}
+		if filter.Min != nil && filter.Max != nil && *filter.Min > *filter.Max {
+			return nil, invalid
+		}
+		return filter, nil
+	}
+
+	for _, item := range strings.Split(raw, ",") {
+		var value interface{} = strings.TrimSpace(item)
+		switch {
+		case ad.IsNumeric():
+			number, err := strconv.ParseFloat(value.(string), 64)
+			if err != nil {
+				return nil, invalid
+			}
+			value = number
+		case ad.Type == AttributeBoolean:
+			flag, err := strconv.ParseBool(value.(string))
+			if err != nil {
+				return nil, invalid
+			}
+			value = flag
+		}
+		filter.Values = append(filter.Values, value)
+	}
+
+	return filter, nil
+}
+
+// ValidateAttributes checks product attributes against the category schema
+// and returns them normalized.
+func (c *Category) ValidateAttributes(values map[string]interface{}) (map[string]interface{}, error) {
+	normalized := make(map[string]interface{}, len(values))
+
+	for key, value := range values {
+		definition := c.FindAttribute(key)
+		if definition == nil {
+			return nil, internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %s is not defined for category %s", key, c.Slug))
+		}
+
+		normalizedValue, err := definition.normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		normalized[key] = normalizedValue
+	}
+
+	for _, definition := range c.Attributes {
+		if _, ok := normalized[definition.Key]; definition.Required && !ok {
+			return nil, internal_error.NewBadRequestError(fmt.Sprintf("Attribute %s is required", definition.Key))
+		}
+	}
+
+	return normalized, nil
+}
+
+// SubtreeAttributes merges the attribute schema of the category with the ones
+// of its descendants, keeping the nearest definition of each key.
+func (c *Category) SubtreeAttributes(descendants []Category) []AttributeDefinition {
+	byDepth := slices.Clone(descendants)
+	slices.SortStableFunc(byDepth, func(a, b Category) int {
+		return len(a.Ancestors) - len(b.Ancestors)
+	})
+
+	attributes := slices.Clone(c.Attributes)
+	for _, descendant := range byDepth {
+		for _, definition := range descendant.Attributes {
+			if !slices.ContainsFunc(attributes, func(defined AttributeDefinition) bool {
+				return defined.Key == definition.Key
+			}) {
+				attributes = append(attributes, definition)
+			}
+		}
+	}
+	return attributes
+}
+
+func (c *Category) FindAttribute(key string) *AttributeDefinition {
+	for index := range c.Attributes {
+		if c.Attributes[index].Key == key {
+			return &c.Attributes[index]
+		}
+	}
+	return nil
+}
+
+func validateAttributeDefinitions(definitions []AttributeDefinition) error {
+	seen := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		if err := definition.Validate(); err != nil {
+			return err
+		}
+		if seen[definition.Key] {
+			return internal_error.NewBadRequestError(fmt.Sprintf("Attribute %s is defined twice", definition.Key))
+		}
+		seen[definition.Key] = true
+	}
+	return nil
+}
+
+// NumberValue reads any of the numeric types attribute values come back as,
+// from JSON or from the database.
+func NumberValue(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case float64:
+		return number, true
+	case float32:
+		return float64(number), true
+	case int:
+		return float64(number), true
+	case int32:
+		return float64(number), true
+	case int64:
+		return float64(number), true
+	default:
+		return 0, false
+	}
+}
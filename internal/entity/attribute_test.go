@@ -0,0 +1,126 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAttributeDefinitionParseFilter(t *testing.T) {
+	year := AttributeDefinition{Key: "year", Type: AttributeInteger}
+	fuel := AttributeDefinition{Key: "fuel", Type: AttributeEnum, Options: []string{"petrol", "diesel"}}
+	manual := AttributeDefinition{Key: "manual", Type: AttributeBoolean}
+
+	tests := []struct {
+		name       string
+		definition AttributeDefinition
+		raw        string
+		want       *AttributeFilter
+		wantErr    bool
+	}{
+		{name: "values", definition: fuel, raw: "petrol, diesel",
+			want: &AttributeFilter{Key: "fuel", Values: []interface{}{"petrol", "diesel"}}},
+		{name: "range", definition: year, raw: "1960..1980",
+			want: &AttributeFilter{Key: "year", Min: float64Pointer(1960), Max: float64Pointer(1980)}},
+		{name: "open range", definition: year, raw: "..1980",
+			want: &AttributeFilter{Key: "year", Max: float64Pointer(1980)}},
+		{name: "boolean", definition: manual, raw: "true",
+			want: &AttributeFilter{Key: "manual", Values: []interface{}{true}}},
+		{name: "empty range", definition: year, raw: "1980..1960", wantErr: true},
+		{name: "invalid number", definition: year, raw: "old", wantErr: true},
+		{name: "invalid boolean", definition: manual, raw: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := tt.definition.ParseFilter(tt.raw)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, filter)
+		})
+	}
+}
+
+func TestCategoryValidateAttributes(t *testing.T) {
+	category := &Category{Slug: "cars", Attributes: []AttributeDefinition{
+		{Key: "brand", Type: AttributeString, Required: true},
+		{Key: "year", Type: AttributeInteger},
+		{Key: "engine", Type: AttributeNumber},
+		{Key: "fuel", Type: AttributeEnum, Options: []string{"petrol", "diesel"}},
+		{Key: "manual", Type: AttributeBoolean},
+	}}
+
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "every type",
+			values: map[string]interface{}{"brand": " Fiat ", "year": 1970.0, "engine": 1.6, "fuel": "petrol", "manual": true},
+			want:   map[string]interface{}{"brand": "Fiat", "year": int64(1970), "engine": 1.6, "fuel": "petrol", "manual": true},
+		},
+		{name: "required missing", values: map[string]interface{}{"year": 1970.0}, wantErr: true},
+		{name: "unknown attribute", values: map[string]interface{}{"brand": "Fiat", "color": "red"}, wantErr: true},
+		{name: "fractional integer", values: map[string]interface{}{"brand": "Fiat", "year": 1970.5}, wantErr: true},
+		{name: "unknown option", values: map[string]interface{}{"brand": "Fiat", "fuel": "steam"}, wantErr: true},
+		{name: "wrong type", values: map[string]interface{}{"brand": "Fiat", "manual": "yes"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := category.ValidateAttributes(tt.values)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, normalized)
+		})
+	}
+}
+
+func TestCategorySubtreeAttributes(t *testing.T) {
+	vehicles := &Category{Id: "vehicles", Attributes: []AttributeDefinition{{Key: "year", Type: AttributeInteger}}}
+	cars := Category{Id: "cars", Ancestors: []string{"vehicles"}, Attributes: []AttributeDefinition{
+		{Key: "fuel", Type: AttributeEnum, Options: []string{"petrol"}},
+		{Key: "year", Type: AttributeString},
+	}}
+	classics := Category{Id: "classics", Ancestors: []string{"vehicles", "cars"}, Attributes: []AttributeDefinition{
+		{Key: "fuel", Type: AttributeString},
+		{Key: "restored", Type: AttributeBoolean},
+	}}
+
+	tests := []struct {
+		name        string
+		descendants []Category
+		want        []AttributeDefinition
+	}{
+		{name: "no subcategories", want: vehicles.Attributes},
+		{
+			name:        "nearest definition first",
+			descendants: []Category{classics, cars},
+			want: []AttributeDefinition{
+				{Key: "year", Type: AttributeInteger},
+				{Key: "fuel", Type: AttributeEnum, Options: []string{"petrol"}},
+				{Key: "restored", Type: AttributeBoolean},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, vehicles.SubtreeAttributes(tt.descendants))
+			require.Len(t, vehicles.Attributes, 1)
+		})
+	}
+}
+
+func float64Pointer(value float64) *float64 {
+	return &value
+}
@@ -3,12 +3,20 @@ package entity
 import (
 	"fullcycle-auction_go/internal/internal_error"
 	"github.com/google/uuid"
+	"os"
+	"slices"
 	"time"
 )
 
+const defaultAuctionDuration = 30 * time.Second
+
 const (
 	Active AuctionStatus = iota
 	Completed
+	Cancelled
+	Draft
+	PendingApproval
+	Rejected
 )
 
 const (
@@ -19,28 +27,77 @@ const (
 
 type (
 	Auction struct {
-		Id          string
-		ProductName string
-		Category    string
-		Description string
-		Condition   ProductCondition
-		Status      AuctionStatus
-		Timestamp   time.Time
+		Id                 string
+		SellerId           string
+		ProductName        string
+		Category           string
+		Description        string
+		Condition          ProductCondition
+		Status             AuctionStatus
+		ReservePrice       float64
+		CancellationReason string
+		CancelledAt        time.Time
+		RejectionReason    string
+		RelistRule         RelistRule
+		RelistCount        int
+		RelistedFromId     string
+		RelistedAsId       string
+		Attributes         map[string]interface{}
+		Images             []AuctionImage
+		CurrentPrice       float64
+		BidCount           int64
+		AnsweredQuestions  int64
+		WatcherCount       int64
+		Version            int64
+		CreatedAt          time.Time
+		Timestamp          time.Time
+
+		// ExtendedUntil is set when late bids kept the auction open past its
+		// usual end.
+		ExtendedUntil time.Time
+	}
+
+	// RelistRule tells the closer how many times an unsold auction may be put
+	// back up and how much the reserve price drops, in percent, on each relist.
+	RelistRule struct {
+		MaxRelists            int
+		PriceReductionPercent float64
+	}
+
+	// AuctionChanges holds a partial update; nil fields are left untouched.
+	AuctionChanges struct {
+		ProductName  *string
+		Category     *string
+		Description  *string
+		Condition    *ProductCondition
+		ReservePrice *float64
+		Attributes   map[string]interface{}
 	}
 
 	ProductCondition int
 	AuctionStatus    int
 )
 
-func CreateAuction(productName, category, description string, condition ProductCondition) (*Auction, error) {
+func CreateAuction(
+	sellerId, productName, category, description string,
+	condition ProductCondition,
+	attributes map[string]interface{},
+	reservePrice float64,
+	relistRule RelistRule) (*Auction, error) {
 	auction := &Auction{
-		Id:          uuid.New().String(),
-		ProductName: productName,
-		Category:    category,
-		Description: description,
-		Condition:   condition,
-		Status:      Active,
-		Timestamp:   time.Now(),
+		Id:           uuid.New().String(),
+		SellerId:     sellerId,
+		ProductName:  productName,
+		Category:     category,
+		Description:  description,
+		Condition:    condition,
+		Attributes:   attributes,
+		Status:       Draft,
+		ReservePrice: reservePrice,
+		RelistRule:   relistRule,
+		Version:      1,
+		CreatedAt:    time.Now(),
+		Timestamp:    time.Now(),
 	}
 
 	if err := auction.Validate(); err != nil {
@@ -51,11 +108,251 @@ func CreateAuction(productName, category, description string, condition ProductC
 }
 
 func (au *Auction) Validate() error {
+	if err := uuid.Validate(au.SellerId); err != nil {
+		return internal_error.NewBadRequestError("SellerId is not a valid id")
+	}
 	if len(au.ProductName) <= 1 ||
 		len(au.Category) <= 2 ||
 		len(au.Description) <= 10 && (au.Condition != New && au.Condition != Refurbished && au.Condition != Used) {
 		return internal_error.NewBadRequestError("invalid auction object")
 	}
+	if au.ReservePrice < 0 {
+		return internal_error.NewBadRequestError("ReservePrice is not a valid value")
+	}
+	if au.RelistRule.MaxRelists < 0 ||
+		au.RelistRule.PriceReductionPercent < 0 ||
+		au.RelistRule.PriceReductionPercent >= 100 {
+		return internal_error.NewBadRequestError("RelistRule is not a valid value")
+	}
+
+	return nil
+}
+
+// ValidateBid checks that the auction is open for bidding and that the bidder
+// is not the seller trying to push up their own price.
+func (au *Auction) ValidateBid(bid *Bid) error {
+	if au.Status != Active {
+		return internal_error.NewBadRequestError("Auction is not accepting bids")
+	}
+	if bid.UserId == au.SellerId {
+		return internal_error.NewBadRequestError("Sellers cannot bid on their own auctions")
+	}
+	return nil
+}
+
+// IsPublic reports whether auctions in the status are shown to everyone.
+func (s AuctionStatus) IsPublic() bool {
+	return s == Active || s == Completed || s == Cancelled
+}
+
+// IsEditable reports whether the seller may still change or withdraw the
+// auction, which is the case until it completes or is cancelled.
+func (au *Auction) IsEditable() bool {
+	return au.Status == Active || au.Status == Draft || au.Status == PendingApproval || au.Status == Rejected
+}
+
+// Cancel withdraws an auction while no bid, nil when there are none, has
+// reached the reserve price.
+func (au *Auction) Cancel(reason string, highestBid *Bid) error {
+	if !au.IsEditable() {
+		return internal_error.NewBadRequestError("only open auctions can be cancelled")
+	}
+	if len(reason) <= 4 {
+		return internal_error.NewBadRequestError("a cancellation reason is required")
+	}
+	if highestBid != nil && (au.ReservePrice == 0 || highestBid.Amount >= au.ReservePrice) {
+		return internal_error.NewBadRequestError("auction has bids and can no longer be cancelled")
+	}
+
+	au.Status = Cancelled
+	au.CancellationReason = reason
+	au.CancelledAt = time.Now()
+	return nil
+}
+
+// Update applies changes to an active auction. Once bids exist only the
+// product name and description may change, since bidders relied on the rest.
+func (au *Auction) Update(changes AuctionChanges, hasBids bool) error {
+	if !au.IsEditable() {
+		return internal_error.NewBadRequestError("only open auctions can be edited")
+	}
+	if hasBids && changes.AffectsBidders() {
+		return internal_error.NewBadRequestError("only product name and description can be edited after the first bid")
+	}
+
+	if changes.ProductName != nil {
+		au.ProductName = *changes.ProductName
+	}
+	if changes.Category != nil {
+		au.Category = *changes.Category
+	}
+	if changes.Description != nil {
+		au.Description = *changes.Description
+	}
+	if changes.Condition != nil {
+		au.Condition = *changes.Condition
+	}
+	if changes.ReservePrice != nil {
+		au.ReservePrice = *changes.ReservePrice
+	}
+	if changes.Attributes != nil {
+		au.Attributes = changes.Attributes
+	}
+
+	if err := au.Validate(); err != nil {
+		return err
+	}
+
+	au.Version++
+	return nil
+}
+
+// AffectsBidders reports whether the changes touch what bidders relied on,
+// which is everything but the product name and description.
+func (ac *AuctionChanges) AffectsBidders() bool {
+	return ac.Category != nil || ac.Condition != nil || ac.ReservePrice != nil || ac.Attributes != nil
+}
+
+// Submit sends a draft, or a previously rejected auction, to the moderation queue.
+func (au *Auction) Submit() error {
+	if au.Status != Draft && au.Status != Rejected {
+		return internal_error.NewBadRequestError("only draft or rejected auctions can be submitted for review")
+	}
+
+	au.Status = PendingApproval
+	au.RejectionReason = ""
+	return nil
+}
+
+// Approve puts a reviewed auction live. Timestamp marks when bidding opens, so
+// it is reset here for the closer and bid path to count from approval.
+func (au *Auction) Approve() error {
+	if au.Status != PendingApproval {
+		return internal_error.NewBadRequestError("only auctions pending approval can be approved")
+	}
 
+	au.Status = Active
+	au.Timestamp = time.Now()
 	return nil
 }
+
+func (au *Auction) Reject(reason string) error {
+	if au.Status != PendingApproval {
+		return internal_error.NewBadRequestError("only auctions pending approval can be rejected")
+	}
+	if len(reason) <= 4 {
+		return internal_error.NewBadRequestError("a rejection reason is required")
+	}
+
+	au.Status = Rejected
+	au.RejectionReason = reason
+	return nil
+}
+
+// IsUnsold reports whether a completed auction ended without a bid reaching
+// the reserve price.
+func (au *Auction) IsUnsold(highestBid *Bid) bool {
+	return highestBid == nil || highestBid.Amount < au.ReservePrice
+}
+
+// ShouldRelist reports whether the closer must put an unsold auction back up.
+func (au *Auction) ShouldRelist(highestBid *Bid) bool {
+	return au.Status == Completed &&
+		au.RelistedAsId == "" &&
+		au.RelistCount < au.RelistRule.MaxRelists &&
+		au.IsUnsold(highestBid)
+}
+
+// Relist creates the next active auction of the chain, with an id derived
+// from the original so relisting twice yields the same auction.
+func (au *Auction) Relist() *Auction {
+	relisted := &Auction{
+		Id:             uuid.NewSHA1(uuid.MustParse(au.Id), []byte("relist")).String(),
+		SellerId:       au.SellerId,
+		ProductName:    au.ProductName,
+		Category:       au.Category,
+		Description:    au.Description,
+		Condition:      au.Condition,
+		Attributes:     au.Attributes,
+		Images:         slices.Clone(au.Images),
+		Status:         Active,
+		ReservePrice:   au.ReservePrice * (1 - au.RelistRule.PriceReductionPercent/100),
+		RelistRule:     au.RelistRule,
+		RelistCount:    au.RelistCount + 1,
+		RelistedFromId: au.Id,
+		Version:        1,
+		CreatedAt:      time.Now(),
+		Timestamp:      time.Now(),
+	}
+
+	au.RelistedAsId = relisted.Id
+	return relisted
+}
+
+// ForceClose completes an active auction ahead of its end time.
+func (au *Auction) ForceClose() error {
+	if au.Status != Active {
+		return internal_error.NewBadRequestError("only active auctions can be closed")
+	}
+
+	au.Status = Completed
+	return nil
+}
+
+// Reopen puts a completed or cancelled auction live again, restarting its
+// bidding period. Auctions already relisted cannot be reopened.
+func (au *Auction) Reopen() error {
+	if au.Status != Completed && au.Status != Cancelled {
+		return internal_error.NewBadRequestError("only completed or cancelled auctions can be reopened")
+	}
+	if au.RelistedAsId != "" {
+		return internal_error.NewBadRequestError("auction was already relisted and cannot be reopened")
+	}
+
+	au.Status = Active
+	au.CancellationReason = ""
+	au.CancelledAt = time.Time{}
+	au.Timestamp = time.Now()
+	au.ExtendedUntil = time.Time{}
+	return nil
+}
+
+// EndsAt is when the auction ends, given how long auctions last.
+func (au *Auction) EndsAt(duration time.Duration) time.Time {
+	endsAt := au.Timestamp.Add(duration)
+	if au.ExtendedUntil.After(endsAt) {
+		return au.ExtendedUntil
+	}
+	return endsAt
+}
+
+// Extend keeps the auction open for the window after a bid placed within the
+// window of its end, returning the new end and whether the bid moved it.
+func (au *Auction) Extend(bidAt time.Time, duration, window time.Duration) (time.Time, bool) {
+	closesAt := au.EndsAt(duration)
+	if window <= 0 || !bidAt.After(closesAt.Add(-window)) {
+		return time.Time{}, false
+	}
+
+	return bidAt.Add(window), true
+}
+
+// AuctionDuration is how long bidding stays open on an auction once it
+// starts, read from AUCTION_DURATION.
+func AuctionDuration() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("AUCTION_DURATION"))
+	if err != nil {
+		return defaultAuctionDuration
+	}
+	return duration
+}
+
+// AuctionExtensionWindow is how close to the end a bid must come to extend
+// the auction, read from AUCTION_EXTENSION_WINDOW; unset disables extensions.
+func AuctionExtensionWindow() time.Duration {
+	window, err := time.ParseDuration(os.Getenv("AUCTION_EXTENSION_WINDOW"))
+	if err != nil || window < 0 {
+		return 0
+	}
+	return window
+}
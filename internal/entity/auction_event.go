@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	EventAuctionCreated   AuctionEventType = "auction.created"
+	EventBidAccepted      AuctionEventType = "bid.accepted"
+	EventAuctionCompleted AuctionEventType = "auction.completed"
+	EventAuctionExtended  AuctionEventType = "auction.extended"
+)
+
+type (
+	// AuctionEvent is published by the repositories when an auction changes.
+	// PreviousBid is the bid a leading bid.accepted overtook, if any.
+	AuctionEvent struct {
+		Id          string
+		Type        AuctionEventType
+		AuctionId   string
+		Auction     *Auction
+		Bid         *Bid
+		PreviousBid *Bid
+		Leading     bool
+		EndsAt      time.Time
+		OccurredAt  time.Time
+	}
+
+	AuctionEventType string
+)
+
+func NewAuctionEvent(eventType AuctionEventType, auctionId string) AuctionEvent {
+	return AuctionEvent{
+		Id:         uuid.New().String(),
+		Type:       eventType,
+		AuctionId:  auctionId,
+		OccurredAt: time.Now(),
+	}
+}
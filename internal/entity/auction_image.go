@@ -0,0 +1,121 @@
+package entity
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"slices"
+	"time"
+)
+
+const MaxAuctionImages = 10
+
+// AuctionImage is a product photo of an auction. Key and ThumbnailKey locate
+// the files in the image storage; Position is the index in display order.
+type AuctionImage struct {
+	Id           string
+	Key          string
+	ThumbnailKey string
+	URL          string
+	ThumbnailURL string
+	ContentType  string
+	Size         int64
+	Width        int
+	Height       int
+	Position     int
+	Primary      bool
+	CreatedAt    time.Time
+}
+
+func NewAuctionImageId() string {
+	return uuid.New().String()
+}
+
+// AddImage appends an image to the gallery. The first image of an auction
+// becomes its primary image.
+func (au *Auction) AddImage(image AuctionImage) error {
+	if !au.IsEditable() {
+		return internal_error.NewBadRequestError("only open auctions can have their images changed")
+	}
+	if len(au.Images) >= MaxAuctionImages {
+		return internal_error.NewBadRequestError(fmt.Sprintf("an auction can have at most %d images", MaxAuctionImages))
+	}
+
+	image.Position = len(au.Images)
+	image.Primary = len(au.Images) == 0
+	au.Images = append(au.Images, image)
+	return nil
+}
+
+// RemoveImage takes an image out of the gallery and returns it. When the
+// primary image goes, the next one in order takes its place.
+func (au *Auction) RemoveImage(imageId string) (*AuctionImage, error) {
+	if !au.IsEditable() {
+		return nil, internal_error.NewBadRequestError("only open auctions can have their images changed")
+	}
+
+	index := au.imageIndex(imageId)
+	if index < 0 {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Image not found with this id = %s", imageId))
+	}
+
+	removed := au.Images[index]
+	au.Images = slices.Delete(slices.Clone(au.Images), index, index+1)
+	if removed.Primary && len(au.Images) > 0 {
+		au.Images[0].Primary = true
+	}
+	au.renumberImages()
+	return &removed, nil
+}
+
+// ReorderImages puts the gallery in the given order, which must list every
+// image of the auction exactly once.
+func (au *Auction) ReorderImages(imageIds []string) error {
+	if !au.IsEditable() {
+		return internal_error.NewBadRequestError("only open auctions can have their images changed")
+	}
+	if len(imageIds) != len(au.Images) {
+		return internal_error.NewBadRequestError("the new order must list every image of the auction once")
+	}
+
+	ordered := make([]AuctionImage, 0, len(imageIds))
+	for _, imageId := range imageIds {
+		index := au.imageIndex(imageId)
+		if index < 0 || slices.ContainsFunc(ordered, func(image AuctionImage) bool { return image.Id == imageId }) {
+			return internal_error.NewBadRequestError("the new order must list every image of the auction once")
+		}
+		ordered = append(ordered, au.Images[index])
+	}
+
+	au.Images = ordered
+	au.renumberImages()
+	return nil
+}
+
+func (au *Auction) SetPrimaryImage(imageId string) error {
+	if !au.IsEditable() {
+		return internal_error.NewBadRequestError("only open auctions can have their images changed")
+	}
+
+	index := au.imageIndex(imageId)
+	if index < 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("Image not found with this id = %s", imageId))
+	}
+
+	images := slices.Clone(au.Images)
+	for i := range images {
+		images[i].Primary = i == index
+	}
+	au.Images = images
+	return nil
+}
+
+func (au *Auction) imageIndex(imageId string) int {
+	return slices.IndexFunc(au.Images, func(image AuctionImage) bool { return image.Id == imageId })
+}
+
+func (au *Auction) renumberImages() {
+	for i := range au.Images {
+		au.Images[i].Position = i
+	}
+}
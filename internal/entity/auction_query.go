@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// AuctionQuery filters an auction listing. Zero values leave a criterion out,
+// and empty Statuses lists every public auction.
+type AuctionQuery struct {
+	Statuses    []AuctionStatus
+	Categories  []string
+	SellerId    string
+	Condition   *ProductCondition
+	ProductName string
+	Text        string
+	MinPrice    *float64
+	MaxPrice    *float64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	EndingFrom  time.Time
+	EndingTo    time.Time
+	HasBids     *bool
+	Attributes  []AttributeFilter
+}
+
+func (aq *AuctionQuery) Validate() error {
+	if aq.MinPrice != nil && aq.MaxPrice != nil && *aq.MinPrice > *aq.MaxPrice {
+		return internal_error.NewBadRequestError("min price must not be greater than max price")
+	}
+	if !aq.CreatedFrom.IsZero() && !aq.CreatedTo.IsZero() && aq.CreatedFrom.After(aq.CreatedTo) {
+		return internal_error.NewBadRequestError("created date range is empty")
+	}
+	if !aq.EndingFrom.IsZero() && !aq.EndingTo.IsZero() && aq.EndingFrom.After(aq.EndingTo) {
+		return internal_error.NewBadRequestError("ending date range is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	highlightOpen  = "<em>"
+	highlightClose = "</em>"
+)
+
+// AuctionSearchHit is an auction matched by a full-text search together with
+// its relevance; higher scores rank first.
+type AuctionSearchHit struct {
+	Auction Auction
+	Score   float64
+}
+
+// SearchTerms splits a search query into lower-cased words, leaving out
+// negated words ("-broken") that can never appear in a hit.
+func SearchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+
+		terms = append(terms, strings.FieldsFunc(strings.ToLower(field), isNotWordRune)...)
+	}
+
+	return terms
+}
+
+// MatchesTerm tells whether a word and a search term share a prefix, so that
+// "lenses" and "lens" find each other.
+func MatchesTerm(word, term string) bool {
+	word = strings.ToLower(word)
+	if strings.HasPrefix(word, term) {
+		return true
+	}
+
+	return len(word) >= 3 && strings.HasPrefix(term, word)
+}
+
+// Highlight wraps the words of text matching the terms in <em> tags and
+// escapes the rest, reporting false when nothing matched.
+func Highlight(text string, terms []string) (string, bool) {
+	var builder strings.Builder
+	matched := false
+
+	for _, segment := range splitWords(text) {
+		if !matchesAnyTerm(segment, terms) {
+			builder.WriteString(html.EscapeString(segment))
+			continue
+		}
+
+		matched = true
+		builder.WriteString(highlightOpen)
+		builder.WriteString(html.EscapeString(segment))
+		builder.WriteString(highlightClose)
+	}
+
+	return builder.String(), matched
+}
+
+// CountTermMatches counts the words of text matching any of the terms.
+func CountTermMatches(text string, terms []string) int {
+	count := 0
+	for _, word := range strings.FieldsFunc(text, isNotWordRune) {
+		if matchesAnyTerm(word, terms) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func matchesAnyTerm(word string, terms []string) bool {
+	for _, term := range terms {
+		if MatchesTerm(word, term) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitWords cuts text into alternating runs of word and non-word runes, so
+// joining the segments gives back the original text.
+func splitWords(text string) []string {
+	var segments []string
+	start, inWord := 0, false
+	for index, r := range text {
+		isWord := !isNotWordRune(r)
+		if index > 0 && isWord != inWord {
+			segments = append(segments, text[start:index])
+			start = index
+		}
+		inWord = isWord
+	}
+	if start < len(text) {
+		segments = append(segments, text[start:])
+	}
+
+	return segments
+}
+
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
@@ -0,0 +1,291 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAuctionCancel(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       AuctionStatus
+		reservePrice float64
+		reason       string
+		highestBid   *Bid
+		wantErr      bool
+	}{
+		{name: "active without bids", status: Active, reason: "Item was damaged"},
+		{name: "draft", status: Draft, reason: "Item was damaged"},
+		{name: "pending approval", status: PendingApproval, reason: "Item was damaged"},
+		{name: "bid below reserve", status: Active, reservePrice: 100, reason: "Item was damaged",
+			highestBid: &Bid{Amount: 99}},
+		{name: "bid reaching reserve", status: Active, reservePrice: 100, reason: "Item was damaged",
+			highestBid: &Bid{Amount: 100}, wantErr: true},
+		{name: "any bid without reserve", status: Active, reason: "Item was damaged",
+			highestBid: &Bid{Amount: 1}, wantErr: true},
+		{name: "completed", status: Completed, reason: "Item was damaged", wantErr: true},
+		{name: "already cancelled", status: Cancelled, reason: "Item was damaged", wantErr: true},
+		{name: "short reason", status: Active, reason: "no", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{Status: tt.status, ReservePrice: tt.reservePrice}
+
+			err := auction.Cancel(tt.reason, tt.highestBid)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tt.status, auction.Status)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, Cancelled, auction.Status)
+			require.Equal(t, tt.reason, auction.CancellationReason)
+			require.False(t, auction.CancelledAt.IsZero())
+		})
+	}
+}
+
+func TestAuctionUpdate(t *testing.T) {
+	productName := "Vintage camera"
+	category := "film-cameras"
+	condition := Refurbished
+	reservePrice := 80.0
+	negativePrice := -1.0
+
+	tests := []struct {
+		name    string
+		status  AuctionStatus
+		changes AuctionChanges
+		hasBids bool
+		wantErr bool
+	}{
+		{name: "product name", status: Active, changes: AuctionChanges{ProductName: &productName}},
+		{name: "product name with bids", status: Active, changes: AuctionChanges{ProductName: &productName},
+			hasBids: true},
+		{name: "category without bids", status: Draft, changes: AuctionChanges{Category: &category}},
+		{name: "category with bids", status: Active, changes: AuctionChanges{Category: &category},
+			hasBids: true, wantErr: true},
+		{name: "condition with bids", status: Active, changes: AuctionChanges{Condition: &condition},
+			hasBids: true, wantErr: true},
+		{name: "reserve price with bids", status: Active, changes: AuctionChanges{ReservePrice: &reservePrice},
+			hasBids: true, wantErr: true},
+		{name: "negative reserve price", status: Active, changes: AuctionChanges{ReservePrice: &negativePrice},
+			wantErr: true},
+		{name: "completed auction", status: Completed, changes: AuctionChanges{ProductName: &productName},
+			wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{
+				SellerId:     "a3c4f1d2-5b6e-4f70-8a9b-0c1d2e3f4a5b",
+				ProductName:  "Camera",
+				Category:     "cameras",
+				Description:  "A camera in good shape",
+				Condition:    Used,
+				Status:       tt.status,
+				ReservePrice: 100,
+				Version:      1,
+			}
+
+			err := auction.Update(tt.changes, tt.hasBids)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				require.EqualValues(t, 1, auction.Version)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, 2, auction.Version)
+		})
+	}
+}
+
+func TestAuctionStatusIsPublic(t *testing.T) {
+	tests := []struct {
+		status AuctionStatus
+		public bool
+	}{
+		{status: Active, public: true},
+		{status: Completed, public: true},
+		{status: Cancelled, public: true},
+		{status: Draft},
+		{status: PendingApproval},
+		{status: Rejected},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.public, tt.status.IsPublic(), "status %d", tt.status)
+	}
+}
+
+func TestAuctionShouldRelist(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       AuctionStatus
+		relistCount  int
+		relistedAsId string
+		highestBid   *Bid
+		want         bool
+	}{
+		{name: "unsold without bids", status: Completed, want: true},
+		{name: "unsold below reserve", status: Completed, highestBid: &Bid{Amount: 50}, want: true},
+		{name: "sold", status: Completed, highestBid: &Bid{Amount: 100}},
+		{name: "still active", status: Active},
+		{name: "cancelled", status: Cancelled},
+		{name: "already relisted", status: Completed, relistedAsId: "8f0c3a52-6d1e-4b9a-9a53-2f7d1c4e8b10"},
+		{name: "no relists left", status: Completed, relistCount: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{
+				Status:       tt.status,
+				ReservePrice: 100,
+				RelistRule:   RelistRule{MaxRelists: 2, PriceReductionPercent: 10},
+				RelistCount:  tt.relistCount,
+				RelistedAsId: tt.relistedAsId,
+			}
+
+			require.Equal(t, tt.want, auction.ShouldRelist(tt.highestBid))
+		})
+	}
+}
+
+func TestAuctionRelist(t *testing.T) {
+	auction := &Auction{
+		Id:           "8f0c3a52-6d1e-4b9a-9a53-2f7d1c4e8b10",
+		Status:       Completed,
+		ReservePrice: 100,
+		RelistRule:   RelistRule{MaxRelists: 2, PriceReductionPercent: 10},
+		RelistCount:  1,
+	}
+
+	relisted := auction.Relist()
+
+	require.Equal(t, Active, relisted.Status)
+	require.Equal(t, 90.0, relisted.ReservePrice)
+	require.Equal(t, 2, relisted.RelistCount)
+	require.Equal(t, auction.Id, relisted.RelistedFromId)
+	require.Equal(t, relisted.Id, auction.RelistedAsId)
+	require.Equal(t, relisted.Id, auction.Relist().Id, "relisting again must yield the same auction")
+}
+
+func TestAuctionValidateBid(t *testing.T) {
+	sellerId := "8f0c3a52-6d1e-4b9a-9a53-2f7d1c4e8b10"
+	bidderId := "1b7e2f90-3c4d-4e5f-8a6b-7c8d9e0f1a2b"
+
+	tests := []struct {
+		name    string
+		status  AuctionStatus
+		userId  string
+		wantErr bool
+	}{
+		{name: "bidder on active auction", status: Active, userId: bidderId},
+		{name: "seller on own auction", status: Active, userId: sellerId, wantErr: true},
+		{name: "completed auction", status: Completed, userId: bidderId, wantErr: true},
+		{name: "draft auction", status: Draft, userId: bidderId, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{SellerId: sellerId, Status: tt.status}
+
+			err := auction.ValidateBid(&Bid{UserId: tt.userId, Amount: 10})
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAuctionExtend(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	const (
+		duration = time.Minute
+		window   = 10 * time.Second
+	)
+
+	tests := []struct {
+		name          string
+		extendedUntil time.Time
+		bidAt         time.Time
+		window        time.Duration
+		want          time.Time
+		wantExtended  bool
+	}{
+		{name: "early bid", bidAt: start.Add(30 * time.Second), window: window},
+		{name: "bid at the edge of the window", bidAt: start.Add(50 * time.Second), window: window},
+		{name: "bid inside the window", bidAt: start.Add(55 * time.Second), window: window,
+			want: start.Add(65 * time.Second), wantExtended: true},
+		{name: "bid inside the window of an extension", extendedUntil: start.Add(65 * time.Second),
+			bidAt: start.Add(60 * time.Second), window: window, want: start.Add(70 * time.Second), wantExtended: true},
+		{name: "bid before the window of an extension", extendedUntil: start.Add(65 * time.Second),
+			bidAt: start.Add(52 * time.Second), window: window},
+		{name: "extension disabled", bidAt: start.Add(59 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{Status: Active, Timestamp: start, ExtendedUntil: tt.extendedUntil}
+
+			endsAt, extended := auction.Extend(tt.bidAt, duration, tt.window)
+
+			require.Equal(t, tt.wantExtended, extended)
+			require.Equal(t, tt.want, endsAt)
+		})
+	}
+}
+
+func TestAuctionEndsAt(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name          string
+		extendedUntil time.Time
+		want          time.Time
+	}{
+		{name: "not extended", want: start.Add(time.Minute)},
+		{name: "extended past its end", extendedUntil: start.Add(2 * time.Minute), want: start.Add(2 * time.Minute)},
+		{name: "extended before its end", extendedUntil: start.Add(30 * time.Second), want: start.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{Timestamp: start, ExtendedUntil: tt.extendedUntil}
+			require.Equal(t, tt.want, auction.EndsAt(time.Minute))
+		})
+	}
+}
+
+func TestAuctionReopenClearsExtension(t *testing.T) {
+	auction := &Auction{Status: Completed, ExtendedUntil: time.Now()}
+
+	require.NoError(t, auction.Reopen())
+	require.True(t, auction.ExtendedUntil.IsZero())
+}
+
+func TestAuctionExtensionWindow(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "15s", want: 15 * time.Second},
+		{value: ""},
+		{value: "-5s"},
+		{value: "late"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("AUCTION_EXTENSION_WINDOW", tt.value)
+			require.Equal(t, tt.want, AuctionExtensionWindow())
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"golang.org/x/text/unicode/norm"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type (
+	// Category is a node of the auction taxonomy. Ancestors holds the ids from
+	// the root down to the parent; the slug auctions reference never changes.
+	Category struct {
+		Id         string
+		Name       string
+		Slug       string
+		ParentId   string
+		Ancestors  []string
+		Attributes []AttributeDefinition
+		CreatedAt  time.Time
+	}
+)
+
+// CreateCategory creates a category under parent, or at the root when parent
+// is nil. An empty slug is derived from the name.
+func CreateCategory(name, slug string, parent *Category, attributes []AttributeDefinition) (*Category, error) {
+	category := &Category{
+		Id:         uuid.New().String(),
+		Name:       strings.TrimSpace(name),
+		Slug:       slug,
+		Attributes: attributes,
+		CreatedAt:  time.Now(),
+	}
+	if category.Slug == "" {
+		category.Slug = Slugify(category.Name)
+	}
+	category.setParent(parent)
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (c *Category) Validate() error {
+	if len(c.Name) <= 1 || len(c.Name) > 50 {
+		return internal_error.NewBadRequestError("Category name is not a valid value")
+	}
+	if !slugPattern.MatchString(c.Slug) {
+		return internal_error.NewBadRequestError("Category slug must be lowercase words separated by hyphens")
+	}
+	return validateAttributeDefinitions(c.Attributes)
+}
+
+func (c *Category) Rename(name string) error {
+	previous := c.Name
+	c.Name = strings.TrimSpace(name)
+	if err := c.Validate(); err != nil {
+		c.Name = previous
+		return err
+	}
+	return nil
+}
+
+// SetAttributes replaces the attribute schema. Auctions listed before keep
+// their attributes and are checked against the new schema when next edited.
+func (c *Category) SetAttributes(attributes []AttributeDefinition) error {
+	if err := validateAttributeDefinitions(attributes); err != nil {
+		return err
+	}
+
+	c.Attributes = attributes
+	return nil
+}
+
+// MoveTo re-parents the category, or makes it a root when parent is nil. A
+// category cannot be moved below itself or one of its descendants.
+func (c *Category) MoveTo(parent *Category) error {
+	if parent != nil && (parent.Id == c.Id || slices.Contains(parent.Ancestors, c.Id)) {
+		return internal_error.NewBadRequestError("Category cannot be moved below itself")
+	}
+
+	c.setParent(parent)
+	return nil
+}
+
+func (c *Category) setParent(parent *Category) {
+	if parent == nil {
+		c.ParentId = ""
+		c.Ancestors = nil
+		return
+	}
+
+	c.ParentId = parent.Id
+	c.Ancestors = append(slices.Clone(parent.Ancestors), parent.Id)
+}
+
+// Slugify turns a category name into its slug, dropping accents and joining
+// words with hyphens, so "Eletrônicos" and "eletronicos" end up the same.
+func Slugify(name string) string {
+	var builder strings.Builder
+	pendingHyphen := false
+
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if pendingHyphen && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			pendingHyphen = false
+			builder.WriteRune(r)
+		default:
+			pendingHyphen = true
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Cameras", want: "cameras"},
+		{name: "Eletrônicos", want: "eletronicos"},
+		{name: "  Cars & Motorbikes ", want: "cars-motorbikes"},
+		{name: "Hi-Fi 2000", want: "hi-fi-2000"},
+		{name: "!!!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, Slugify(tt.name))
+		})
+	}
+}
+
+func TestCategoryMoveTo(t *testing.T) {
+	root := mustCreateCategory(t, "Electronics", nil)
+	child := mustCreateCategory(t, "Cameras", root)
+	grandchild := mustCreateCategory(t, "Lenses", child)
+	other := mustCreateCategory(t, "Toys", nil)
+
+	tests := []struct {
+		name          string
+		category      *Category
+		parent        *Category
+		wantAncestors []string
+		wantErr       bool
+	}{
+		{name: "to another root", category: child, parent: other, wantAncestors: []string{other.Id}},
+		{name: "to the root", category: grandchild, wantAncestors: nil},
+		{name: "below a descendant", category: root, parent: grandchild, wantErr: true},
+		{name: "below itself", category: child, parent: child, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category := *tt.category
+
+			err := category.MoveTo(tt.parent)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, tt.category.Ancestors, category.Ancestors)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantAncestors, category.Ancestors)
+		})
+	}
+}
+
+func mustCreateCategory(t *testing.T, name string, parent *Category) *Category {
+	t.Helper()
+
+	category, err := CreateCategory(name, "", parent, nil)
+	require.NoError(t, err)
+	return category
+}
@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fullcycle-auction_go/internal/internal_error"
+	"time"
+)
+
+// IdempotencyRecord remembers the first request made with an Idempotency-Key
+// and, once it finishes, the response to replay for identical retries.
+type IdempotencyRecord struct {
+	Key         string
+	RequestHash string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func CreateIdempotencyRecord(key, requestHash string, ttl time.Duration) (*IdempotencyRecord, error) {
+	if len(key) == 0 || len(key) > 255 {
+		return nil, internal_error.NewBadRequestError("Idempotency-Key is not a valid value")
+	}
+
+	now := time.Now()
+	return &IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+	}, nil
+}
+
+func HashIdempotentRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Matches checks that a retry carries the same request as the original one.
+func (r *IdempotencyRecord) Matches(requestHash string) error {
+	if r.RequestHash != requestHash {
+		return internal_error.NewConflictError("Idempotency-Key was already used with a different request")
+	}
+	if !r.Completed {
+		return internal_error.NewConflictError("A request with this Idempotency-Key is still being processed")
+	}
+	return nil
+}
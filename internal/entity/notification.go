@@ -0,0 +1,60 @@
+package entity
+
+const (
+	NotificationOutbid       NotificationType = "outbid"
+	NotificationWinning      NotificationType = "winning"
+	NotificationAuctionWon   NotificationType = "auction_won"
+	NotificationAuctionEnded NotificationType = "auction_ended"
+)
+
+// NotificationTypes lists every kind of notification users can opt out of.
+var NotificationTypes = []NotificationType{
+	NotificationOutbid,
+	NotificationWinning,
+	NotificationAuctionWon,
+	NotificationAuctionEnded,
+}
+
+type (
+	// Notification is a message for one user about one of their auctions or
+	// bids.
+	Notification struct {
+		Type      NotificationType
+		UserId    string
+		Email     string
+		Name      string
+		AuctionId string
+		Subject   string
+		Body      string
+	}
+
+	// NotificationPreferences holds what a user opted out of; every type not
+	// listed in Disabled is sent.
+	NotificationPreferences struct {
+		UserId   string
+		Disabled map[NotificationType]bool
+	}
+
+	NotificationType string
+)
+
+// IsRateLimited tells whether notifications of the type count towards the
+// rate limit; won and ended notifications never do.
+func (t NotificationType) IsRateLimited() bool {
+	return t == NotificationOutbid || t == NotificationWinning
+}
+
+func (np *NotificationPreferences) IsEnabled(notificationType NotificationType) bool {
+	return !np.Disabled[notificationType]
+}
+
+func (np *NotificationPreferences) Set(notificationType NotificationType, enabled bool) {
+	if np.Disabled == nil {
+		np.Disabled = make(map[NotificationType]bool)
+	}
+	if enabled {
+		delete(np.Disabled, notificationType)
+		return
+	}
+	np.Disabled[notificationType] = true
+}
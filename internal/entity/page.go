@@ -0,0 +1,39 @@
+package entity
+
+const (
+	AuctionSortEndingSoonest = "ending_soonest"
+	AuctionSortNewest        = "newest"
+	AuctionSortPriceAsc      = "price_asc"
+	AuctionSortPriceDesc     = "price_desc"
+	AuctionSortBidCount      = "bid_count"
+)
+
+const (
+	BidSortNewest = "newest"
+	BidSortAmount = "amount"
+)
+
+const QuestionSortNewest = "newest"
+
+const WatchlistSortNewest = "newest"
+
+const DeliverySortNewest = "newest"
+
+type (
+	// PageRequest asks for one page of a listing. Cursor is the token of the
+	// previous page, empty for the first one.
+	PageRequest struct {
+		Cursor string
+		Limit  int
+		Sort   string
+	}
+
+	// Page is one slice of a listing. Total counts every match of the
+	// listing's filter, not only the items on this page.
+	Page[T any] struct {
+		Items      []T
+		NextCursor string
+		HasMore    bool
+		Total      int64
+	}
+)
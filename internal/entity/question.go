@@ -0,0 +1,162 @@
+package entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	QuestionVisible QuestionStatus = iota
+	QuestionHidden
+)
+
+const (
+	FlagSpam      FlagReason = "spam"
+	FlagOffensive FlagReason = "offensive"
+	FlagOffTopic  FlagReason = "off_topic"
+	FlagOther     FlagReason = "other"
+)
+
+// QuestionAutoHideFlags is how many flags hide a question until a moderator
+// reviews it.
+const QuestionAutoHideFlags = 3
+
+const (
+	minQuestionTextLength = 5
+	maxQuestionTextLength = 500
+)
+
+type (
+	// Question is asked on an active auction and answered by its seller.
+	// ReviewedFlags is the flag count at the last moderator review.
+	Question struct {
+		Id            string
+		AuctionId     string
+		AskerId       string
+		Text          string
+		Answer        *Answer
+		Status        QuestionStatus
+		Flags         []QuestionFlag
+		Reviewed      bool
+		ReviewedFlags int
+		Version       int64
+		CreatedAt     time.Time
+	}
+
+	Answer struct {
+		Text       string
+		AnsweredAt time.Time
+	}
+
+	// QuestionFlag is a report by a user that a question or its answer breaks
+	// the rules.
+	QuestionFlag struct {
+		UserId    string
+		Reason    FlagReason
+		CreatedAt time.Time
+	}
+
+	QuestionStatus int
+	FlagReason     string
+)
+
+func AskQuestion(auction *Auction, askerId, text string) (*Question, error) {
+	if !auction.IsOpenForQuestions() {
+		return nil, internal_error.NewBadRequestError("Auction is not open for questions")
+	}
+	if askerId == auction.SellerId {
+		return nil, internal_error.NewBadRequestError("Sellers cannot ask questions on their own auctions")
+	}
+
+	text, err := questionText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Question{
+		Id:        uuid.New().String(),
+		AuctionId: auction.Id,
+		AskerId:   askerId,
+		Text:      text,
+		Status:    QuestionVisible,
+		Version:   1,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// IsOpenForQuestions reports whether questions may still be asked and
+// answered on the auction.
+func (au *Auction) IsOpenForQuestions() bool {
+	return au.Status == Active
+}
+
+// AnswerWith sets the seller's answer, replacing any earlier one.
+func (q *Question) AnswerWith(auction *Auction, text string) error {
+	if !auction.IsOpenForQuestions() {
+		return internal_error.NewBadRequestError("Questions on this auction are closed")
+	}
+	if q.Status == QuestionHidden {
+		return internal_error.NewBadRequestError("Question was hidden by moderation and cannot be answered")
+	}
+
+	text, err := questionText(text)
+	if err != nil {
+		return err
+	}
+
+	q.Answer = &Answer{Text: text, AnsweredAt: time.Now()}
+	return nil
+}
+
+// Flag records a user's report and puts the question back in the moderation
+// queue. Once enough users flagged it since the last review, it is hidden.
+func (q *Question) Flag(userId string, reason FlagReason) error {
+	if slices.ContainsFunc(q.Flags, func(flag QuestionFlag) bool { return flag.UserId == userId }) {
+		return internal_error.NewConflictError("Question was already flagged by this user")
+	}
+
+	q.Flags = append(q.Flags, QuestionFlag{UserId: userId, Reason: reason, CreatedAt: time.Now()})
+	q.Reviewed = false
+	if q.PendingFlags() >= QuestionAutoHideFlags {
+		q.Status = QuestionHidden
+	}
+	return nil
+}
+
+// PendingFlags counts the flags no moderator has reviewed yet.
+func (q *Question) PendingFlags() int {
+	return len(q.Flags) - q.ReviewedFlags
+}
+
+// Hide and Restore settle the pending flags of a question after a moderator
+// reviewed it. The flags are kept, and new ones count from zero again.
+func (q *Question) Hide() {
+	q.review(QuestionHidden)
+}
+
+func (q *Question) Restore() {
+	q.review(QuestionVisible)
+}
+
+func (q *Question) review(status QuestionStatus) {
+	q.Status = status
+	q.Reviewed = true
+	q.ReviewedFlags = len(q.Flags)
+}
+
+// IsPubliclyAnswered reports whether the question counts as answered on the
+// auction page.
+func (q *Question) IsPubliclyAnswered() bool {
+	return q.Answer != nil && q.Status == QuestionVisible
+}
+
+func questionText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if len(text) < minQuestionTextLength || len(text) > maxQuestionTextLength {
+		return "", internal_error.NewBadRequestError("Text must have between 5 and 500 characters")
+	}
+	return text, nil
+}
@@ -0,0 +1,153 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAskQuestion(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  AuctionStatus
+		askerId string
+		text    string
+		wantErr bool
+	}{
+		{name: "active auction", status: Active, askerId: "bidder", text: "  Does it ship abroad?  "},
+		{name: "completed auction", status: Completed, askerId: "bidder", text: "Does it ship abroad?", wantErr: true},
+		{name: "seller asking", status: Active, askerId: "seller", text: "Does it ship abroad?", wantErr: true},
+		{name: "short text", status: Active, askerId: "bidder", text: " Why ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &Auction{Id: "auction", SellerId: "seller", Status: tt.status}
+
+			question, err := AskQuestion(auction, tt.askerId, tt.text)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "Does it ship abroad?", question.Text)
+			require.Equal(t, QuestionVisible, question.Status)
+			require.EqualValues(t, 1, question.Version)
+		})
+	}
+}
+
+func TestQuestionAnswerWith(t *testing.T) {
+	tests := []struct {
+		name          string
+		auctionStatus AuctionStatus
+		status        QuestionStatus
+		text          string
+		wantErr       bool
+	}{
+		{name: "visible question", auctionStatus: Active, status: QuestionVisible, text: "Yes, worldwide"},
+		{name: "hidden question", auctionStatus: Active, status: QuestionHidden, text: "Yes, worldwide", wantErr: true},
+		{name: "closed auction", auctionStatus: Completed, status: QuestionVisible, text: "Yes, worldwide",
+			wantErr: true},
+		{name: "short text", auctionStatus: Active, status: QuestionVisible, text: "Yes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := &Question{Status: tt.status}
+
+			err := question.AnswerWith(&Auction{Status: tt.auctionStatus}, tt.text)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Nil(t, question.Answer)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.text, question.Answer.Text)
+			require.Equal(t, tt.status == QuestionVisible, question.IsPubliclyAnswered())
+		})
+	}
+}
+
+// TestQuestionModeration runs a question through flags and reviews, checking
+// its state after each step.
+func TestQuestionModeration(t *testing.T) {
+	type step struct {
+		flagBy string
+		hide   bool
+		review bool
+
+		wantErr      bool
+		wantStatus   QuestionStatus
+		wantReviewed bool
+		wantFlags    int
+		wantPending  int
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "hidden once enough users flag", steps: []step{
+			{flagBy: "a", wantStatus: QuestionVisible, wantFlags: 1, wantPending: 1},
+			{flagBy: "b", wantStatus: QuestionVisible, wantFlags: 2, wantPending: 2},
+			{flagBy: "c", wantStatus: QuestionHidden, wantFlags: 3, wantPending: 3},
+		}},
+		{name: "same user flags once", steps: []step{
+			{flagBy: "a", wantStatus: QuestionVisible, wantFlags: 1, wantPending: 1},
+			{flagBy: "a", wantErr: true, wantStatus: QuestionVisible, wantFlags: 1, wantPending: 1},
+		}},
+		{name: "restore keeps the flags", steps: []step{
+			{flagBy: "a", wantStatus: QuestionVisible, wantFlags: 1, wantPending: 1},
+			{flagBy: "b", wantStatus: QuestionVisible, wantFlags: 2, wantPending: 2},
+			{flagBy: "c", wantStatus: QuestionHidden, wantFlags: 3, wantPending: 3},
+			{review: true, wantStatus: QuestionVisible, wantReviewed: true, wantFlags: 3},
+		}},
+		{name: "hide keeps the flags", steps: []step{
+			{flagBy: "a", wantStatus: QuestionVisible, wantFlags: 1, wantPending: 1},
+			{review: true, hide: true, wantStatus: QuestionHidden, wantReviewed: true, wantFlags: 1},
+		}},
+		{name: "flags after review count from zero", steps: []step{
+			{flagBy: "a", wantStatus: QuestionVisible, wantFlags: 1, wantPending: 1},
+			{flagBy: "b", wantStatus: QuestionVisible, wantFlags: 2, wantPending: 2},
+			{review: true, wantStatus: QuestionVisible, wantReviewed: true, wantFlags: 2},
+			{flagBy: "c", wantStatus: QuestionVisible, wantFlags: 3, wantPending: 1},
+			{flagBy: "d", wantStatus: QuestionVisible, wantFlags: 4, wantPending: 2},
+			{flagBy: "e", wantStatus: QuestionHidden, wantFlags: 5, wantPending: 3},
+		}},
+		{name: "reviewed user cannot flag again", steps: []step{
+			{flagBy: "a", wantStatus: QuestionVisible, wantFlags: 1, wantPending: 1},
+			{review: true, wantStatus: QuestionVisible, wantReviewed: true, wantFlags: 1},
+			{flagBy: "a", wantErr: true, wantStatus: QuestionVisible, wantReviewed: true, wantFlags: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			question := &Question{Status: QuestionVisible}
+
+			for i, step := range tt.steps {
+				var err error
+				switch {
+				case step.review && step.hide:
+					question.Hide()
+				case step.review:
+					question.Restore()
+				default:
+					err = question.Flag(step.flagBy, FlagSpam)
+				}
+
+				if step.wantErr {
+					require.Error(t, err, "step %d", i)
+				} else {
+					require.NoError(t, err, "step %d", i)
+				}
+				require.Equal(t, step.wantStatus, question.Status, "step %d", i)
+				require.Equal(t, step.wantReviewed, question.Reviewed, "step %d", i)
+				require.Len(t, question.Flags, step.wantFlags, "step %d", i)
+				require.Equal(t, step.wantPending, question.PendingFlags(), "step %d", i)
+			}
+		})
+	}
+}
@@ -1,6 +1,110 @@
 package entity
 
-type User struct {
-	Id   string
-	Name string
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+const (
+	UserActive UserStatus = iota
+	UserUnverified
+	UserSuspended
+)
+
+type (
+	User struct {
+		Id          string
+		Name        string
+		Email       string
+		DisplayName string
+		Status      UserStatus
+		CreatedAt   time.Time
+	}
+
+	// UserChanges holds a partial profile update; nil fields are left untouched.
+	UserChanges struct {
+		Name        *string
+		Email       *string
+		DisplayName *string
+		Status      *UserStatus
+	}
+
+	UserStatus int
+)
+
+func CreateUser(name, email, displayName string) (*User, error) {
+	user := &User{
+		Id:          uuid.New().String(),
+		Name:        strings.TrimSpace(name),
+		Email:       normalizeEmail(email),
+		DisplayName: strings.TrimSpace(displayName),
+		Status:      UserUnverified,
+		CreatedAt:   time.Now(),
+	}
+	if user.DisplayName == "" {
+		user.DisplayName = user.Name
+	}
+
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (u *User) Validate() error {
+	if len(u.Name) <= 1 {
+		return internal_error.NewBadRequestError("Name is not a valid value")
+	}
+	if _, err := mail.ParseAddress(u.Email); err != nil {
+		return internal_error.NewBadRequestError("Email is not a valid address")
+	}
+	if len(u.DisplayName) <= 1 {
+		return internal_error.NewBadRequestError("DisplayName is not a valid value")
+	}
+	if u.Status != UserActive && u.Status != UserUnverified && u.Status != UserSuspended {
+		return internal_error.NewBadRequestError("Status is not a valid value")
+	}
+	return nil
+}
+
+// CanBid checks that the user is in good standing to place bids.
+func (u *User) CanBid() error {
+	switch u.Status {
+	case UserSuspended:
+		return internal_error.NewBadRequestError("User is suspended and cannot place bids")
+	case UserUnverified:
+		return internal_error.NewBadRequestError("User must be verified before placing bids")
+	}
+	return nil
+}
+
+// Update applies a profile change. A new email address has to be verified
+// again, so an active user changing it goes back to unverified.
+func (u *User) Update(changes UserChanges) error {
+	if changes.Name != nil {
+		u.Name = strings.TrimSpace(*changes.Name)
+	}
+	if changes.Email != nil {
+		email := normalizeEmail(*changes.Email)
+		if email != u.Email && u.Status == UserActive {
+			u.Status = UserUnverified
+		}
+		u.Email = email
+	}
+	if changes.DisplayName != nil {
+		u.DisplayName = strings.TrimSpace(*changes.DisplayName)
+	}
+	if changes.Status != nil {
+		u.Status = *changes.Status
+	}
+
+	return u.Validate()
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
 }
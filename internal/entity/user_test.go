@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCreateUser(t *testing.T) {
+	tests := []struct {
+		name            string
+		userName        string
+		email           string
+		displayName     string
+		wantEmail       string
+		wantDisplayName string
+		wantErr         bool
+	}{
+		{name: "display name given", userName: "Ana Souza", email: "ana@example.com", displayName: "ana",
+			wantEmail: "ana@example.com", wantDisplayName: "ana"},
+		{name: "display name from name", userName: "Ana Souza", email: "ana@example.com",
+			wantEmail: "ana@example.com", wantDisplayName: "Ana Souza"},
+		{name: "email normalized", userName: "Ana Souza", email: "  Ana@Example.COM ",
+			wantEmail: "ana@example.com", wantDisplayName: "Ana Souza"},
+		{name: "invalid email", userName: "Ana Souza", email: "ana", wantErr: true},
+		{name: "short name", userName: "A", email: "ana@example.com", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := CreateUser(tt.userName, tt.email, tt.displayName)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, UserUnverified, user.Status)
+			require.Equal(t, tt.wantEmail, user.Email)
+			require.Equal(t, tt.wantDisplayName, user.DisplayName)
+		})
+	}
+}
+
+func TestUserUpdate(t *testing.T) {
+	email := "New@Example.com"
+	sameEmail := " ANA@example.com"
+	invalidEmail := "not-an-email"
+	active := UserActive
+	suspended := UserSuspended
+	unknownStatus := UserStatus(7)
+
+	tests := []struct {
+		name       string
+		status     UserStatus
+		changes    UserChanges
+		wantEmail  string
+		wantStatus UserStatus
+		wantErr    bool
+	}{
+		{name: "email", status: UserActive, changes: UserChanges{Email: &email},
+			wantEmail: "new@example.com", wantStatus: UserUnverified},
+		{name: "same email", status: UserActive, changes: UserChanges{Email: &sameEmail},
+			wantEmail: "ana@example.com", wantStatus: UserActive},
+		{name: "email of suspended user", status: UserSuspended, changes: UserChanges{Email: &email},
+			wantEmail: "new@example.com", wantStatus: UserSuspended},
+		{name: "email and status", status: UserActive, changes: UserChanges{Email: &email, Status: &active},
+			wantEmail: "new@example.com", wantStatus: UserActive},
+		{name: "status", status: UserActive, changes: UserChanges{Status: &suspended},
+			wantEmail: "ana@example.com", wantStatus: UserSuspended},
+		{name: "invalid email", changes: UserChanges{Email: &invalidEmail}, wantErr: true},
+		{name: "unknown status", changes: UserChanges{Status: &unknownStatus}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := CreateUser("Ana Souza", "ana@example.com", "")
+			require.NoError(t, err)
+			user.Status = tt.status
+
+			err = user.Update(tt.changes)
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantEmail, user.Email)
+			require.Equal(t, tt.wantStatus, user.Status)
+		})
+	}
+}
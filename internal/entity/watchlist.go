@@ -0,0 +1,28 @@
+package entity
+
+import (
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"time"
+)
+
+// WatchlistEntry records that a user follows an auction.
+type WatchlistEntry struct {
+	Id        string
+	UserId    string
+	AuctionId string
+	CreatedAt time.Time
+}
+
+func WatchAuction(auction *Auction, userId string) (*WatchlistEntry, error) {
+	if auction.Status != Active {
+		return nil, internal_error.NewBadRequestError("Only active auctions can be watched")
+	}
+
+	return &WatchlistEntry{
+		Id:        uuid.New().String(),
+		UserId:    userId,
+		AuctionId: auction.Id,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWatchAuction(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  AuctionStatus
+		wantErr bool
+	}{
+		{name: "active", status: Active},
+		{name: "completed", status: Completed, wantErr: true},
+		{name: "cancelled", status: Cancelled, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := WatchAuction(&Auction{Id: "auction", Status: tt.status}, "user")
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "auction", entry.AuctionId)
+			require.Equal(t, "user", entry.UserId)
+			require.NotEmpty(t, entry.Id)
+		})
+	}
+}
+
+func TestAuctionDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "5m", want: 5 * time.Minute},
+		{value: "", want: defaultAuctionDuration},
+		{value: "soon", want: defaultAuctionDuration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("AUCTION_DURATION", tt.value)
+			require.Equal(t, tt.want, AuctionDuration())
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+const minWebhookSecretLength = 16
+
+// WebhookEventTypes lists the events webhooks can subscribe to.
+var WebhookEventTypes = []AuctionEventType{EventAuctionCreated, EventBidAccepted, EventAuctionCompleted}
+
+type (
+	// Webhook is a subscription of an outside service to auction events.
+	// Deliveries are signed with Secret.
+	Webhook struct {
+		Id         string
+		OwnerId    string
+		URL        string
+		EventTypes []AuctionEventType
+		Secret     string
+		CreatedAt  time.Time
+	}
+
+	// WebhookDelivery is one event sent, or still to be sent, to one webhook.
+	// Pending deliveries are retried at NextAttemptAt.
+	WebhookDelivery struct {
+		Id            string
+		WebhookId     string
+		EventId       string
+		EventType     AuctionEventType
+		Payload       []byte
+		Status        DeliveryStatus
+		Attempts      []DeliveryAttempt
+		NextAttemptAt time.Time
+		RedeliveryOf  string
+		CreatedAt     time.Time
+	}
+
+	DeliveryAttempt struct {
+		At         time.Time
+		StatusCode int
+		Error      string
+		Duration   time.Duration
+	}
+
+	DeliveryStatus string
+)
+
+// CreateWebhook subscribes url to the given events. A secret is generated
+// when none is given.
+func CreateWebhook(ownerId, webhookURL string, eventTypes []AuctionEventType, secret string) (*Webhook, error) {
+	if secret == "" {
+		random := make([]byte, 32)
+		if _, err := rand.Read(random); err != nil {
+			return nil, internal_error.NewInternalServerError("Error trying to generate webhook secret")
+		}
+		secret = "whsec_" + hex.EncodeToString(random)
+	}
+
+	webhook := &Webhook{
+		Id:         uuid.New().String(),
+		OwnerId:    ownerId,
+		URL:        webhookURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	}
+
+	if err := webhook.Validate(); err != nil {
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+func (w *Webhook) Validate() error {
+	parsed, err := url.Parse(w.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return internal_error.NewBadRequestError("URL must be an absolute http or https URL")
+	}
+	if len(w.EventTypes) == 0 {
+		return internal_error.NewBadRequestError("At least one event type is required")
+	}
+	for _, eventType := range w.EventTypes {
+		if !slices.Contains(WebhookEventTypes, eventType) {
+			return internal_error.NewBadRequestError("Event type " + string(eventType) + " is not a valid value")
+		}
+	}
+	if len(w.Secret) < minWebhookSecretLength {
+		return internal_error.NewBadRequestError("Secret must have at least 16 characters")
+	}
+
+	return nil
+}
+
+func (w *Webhook) Subscribes(eventType AuctionEventType) bool {
+	return slices.Contains(w.EventTypes, eventType)
+}
+
+// NewWebhookDelivery queues an event for a webhook. The id is derived from
+// both, so queueing the same event twice yields the same delivery.
+func NewWebhookDelivery(webhook *Webhook, eventId string, eventType AuctionEventType, payload []byte) WebhookDelivery {
+	return WebhookDelivery{
+		Id:            uuid.NewSHA1(uuid.NameSpaceURL, []byte(webhook.Id+"/"+eventId)).String(),
+		WebhookId:     webhook.Id,
+		EventId:       eventId,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+	}
+}
+
+// Redeliver queues the same event again as a new delivery, keeping this one
+// and its log as they are.
+func (d *WebhookDelivery) Redeliver() WebhookDelivery {
+	return WebhookDelivery{
+		Id:            uuid.New().String(),
+		WebhookId:     d.WebhookId,
+		EventId:       d.EventId,
+		EventType:     d.EventType,
+		Payload:       d.Payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: time.Now(),
+		RedeliveryOf:  d.Id,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// RecordAttempt logs a try, retrying failures with exponential backoff
+// until maxAttempts is reached.
+func (d *WebhookDelivery) RecordAttempt(attempt DeliveryAttempt, maxAttempts int, backoff, maxBackoff time.Duration) {
+	d.Attempts = append(d.Attempts, attempt)
+
+	if attempt.Error == "" && attempt.StatusCode >= 200 && attempt.StatusCode < 300 {
+		d.Status = DeliverySucceeded
+		return
+	}
+	if len(d.Attempts) >= maxAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+
+	delay := backoff << (len(d.Attempts) - 1)
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	d.NextAttemptAt = attempt.At.Add(delay)
+}
+
+// SignWebhookPayload signs the timestamp and body together, so a captured
+// delivery cannot be replayed later under a fresh timestamp.
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,115 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":"event"}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp time.Time
+		payload   []byte
+		same      bool
+	}{
+		{name: "same delivery", secret: "whsec_0123456789abcdef", timestamp: timestamp, payload: payload, same: true},
+		{name: "other secret", secret: "whsec_fedcba9876543210", timestamp: timestamp, payload: payload},
+		{name: "replayed later", secret: "whsec_0123456789abcdef", timestamp: timestamp.Add(time.Second), payload: payload},
+		{name: "other payload", secret: "whsec_0123456789abcdef", timestamp: timestamp, payload: []byte(`{"id":"other"}`)},
+	}
+
+	mac := hmac.New(sha256.New, []byte("whsec_0123456789abcdef"))
+	mac.Write([]byte("1700000000." + string(payload)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := SignWebhookPayload(tt.secret, tt.timestamp, tt.payload)
+
+			if tt.same {
+				require.Equal(t, want, signature)
+			} else {
+				require.NotEqual(t, want, signature)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveryRecordAttempt(t *testing.T) {
+	const (
+		maxAttempts = 4
+		backoff     = 10 * time.Second
+		maxBackoff  = 30 * time.Second
+	)
+	at := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name          string
+		previous      int
+		attempt       DeliveryAttempt
+		wantStatus    DeliveryStatus
+		wantNextDelay time.Duration
+	}{
+		{name: "succeeds", attempt: DeliveryAttempt{At: at, StatusCode: 204}, wantStatus: DeliverySucceeded},
+		{name: "first failure backs off",
+			attempt: DeliveryAttempt{At: at, StatusCode: 500}, wantStatus: DeliveryPending, wantNextDelay: backoff},
+		{name: "backoff doubles", previous: 1,
+			attempt: DeliveryAttempt{At: at, StatusCode: 503}, wantStatus: DeliveryPending, wantNextDelay: 2 * backoff},
+		{name: "backoff is capped", previous: 2,
+			attempt: DeliveryAttempt{At: at, Error: "timeout"}, wantStatus: DeliveryPending, wantNextDelay: maxBackoff},
+		{name: "redirect is a failure",
+			attempt: DeliveryAttempt{At: at, StatusCode: 301}, wantStatus: DeliveryPending, wantNextDelay: backoff},
+		{name: "gives up after the last attempt", previous: maxAttempts - 1,
+			attempt: DeliveryAttempt{At: at, StatusCode: 500}, wantStatus: DeliveryFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := &WebhookDelivery{Status: DeliveryPending, NextAttemptAt: at}
+			for i := 0; i < tt.previous; i++ {
+				delivery.Attempts = append(delivery.Attempts, DeliveryAttempt{At: at, StatusCode: 500})
+			}
+
+			delivery.RecordAttempt(tt.attempt, maxAttempts, backoff, maxBackoff)
+
+			require.Equal(t, tt.wantStatus, delivery.Status)
+			require.Len(t, delivery.Attempts, tt.previous+1)
+			if tt.wantStatus == DeliveryPending {
+				require.Equal(t, at.Add(tt.wantNextDelay), delivery.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestNewWebhookDelivery(t *testing.T) {
+	webhook := &Webhook{Id: "webhook"}
+	delivery := NewWebhookDelivery(webhook, "event", EventBidAccepted, []byte("{}"))
+
+	tests := []struct {
+		name    string
+		webhook *Webhook
+		eventId string
+		same    bool
+	}{
+		{name: "same event again", webhook: webhook, eventId: "event", same: true},
+		{name: "other event", webhook: webhook, eventId: "other"},
+		{name: "other webhook", webhook: &Webhook{Id: "other"}, eventId: "event"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			other := NewWebhookDelivery(tt.webhook, tt.eventId, EventBidAccepted, []byte("{}"))
+
+			require.Equal(t, tt.same, other.Id == delivery.Id)
+			require.Equal(t, DeliveryPending, other.Status)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type APIKeyController struct {
+	apiKeyUseCase usecase.APIKeyUseCase
+	policy        *auth.Policy
+}
+
+func NewAPIKeyController(apiKeyUseCase usecase.APIKeyUseCase, policy *auth.Policy) *APIKeyController {
+	return &APIKeyController{
+		apiKeyUseCase: apiKeyUseCase,
+		policy:        policy,
+	}
+}
+
+func (u *APIKeyController) CreateAPIKey(c *gin.Context) {
+	var apiKeyInputDTO usecase.APIKeyInputDTO
+
+	if err := c.ShouldBindJSON(&apiKeyInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionAPIKeyManage, "") {
+		return
+	}
+
+	apiKeyData, err := u.apiKeyUseCase.CreateAPIKey(c.Request.Context(), apiKeyInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKeyData)
+}
+
+func (u *APIKeyController) FindAPIKeys(c *gin.Context) {
+	if !authorize(c, u.policy, auth.ActionAPIKeyManage, "") {
+		return
+	}
+
+	apiKeys, err := u.apiKeyUseCase.FindAPIKeys(c.Request.Context())
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeys)
+}
+
+func (u *APIKeyController) RotateAPIKey(c *gin.Context) {
+	apiKeyId, ok := uuidParam(c, "apiKeyId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionAPIKeyManage, "") {
+		return
+	}
+
+	apiKeyData, err := u.apiKeyUseCase.RotateAPIKey(c.Request.Context(), apiKeyId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, apiKeyData)
+}
+
+func (u *APIKeyController) RevokeAPIKey(c *gin.Context) {
+	apiKeyId, ok := uuidParam(c, "apiKeyId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionAPIKeyManage, "") {
+		return
+	}
+
+	apiKeyData, err := u.apiKeyUseCase.RevokeAPIKey(c.Request.Context(), apiKeyId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, apiKeyData)
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// withPrincipal stands in for the auth middleware, identifying every request
+// as principal; a nil principal leaves requests anonymous.
+func withPrincipal(principal *auth.Principal) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if principal != nil {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+		c.Next()
+	}
+}
+
+func newTestPolicy(t *testing.T) *auth.Policy {
+	t.Helper()
+
+	t.Setenv("AUTHORIZATION_POLICY_FILE", "")
+	policy, err := auth.NewPolicy()
+	require.NoError(t, err)
+	return policy
+}
+
+func serve(router *gin.Engine, method, target string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// fakeAuctionUseCase records the listings asked for and finds the auction it
+// holds. It embeds the use case interface, so calling anything else fails the
+// test.
+type fakeAuctionUseCase struct {
+	usecase.AuctionUseCase
+
+	listed  []usecase.AuctionListInputDTO
+	auction *usecase.AuctionOutputDTO
+}
+
+func (f *fakeAuctionUseCase) FindAuctionById(_ context.Context, id string) (*usecase.AuctionOutputDTO, error) {
+	if f.auction == nil || f.auction.Id != id {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", id))
+	}
+	return f.auction, nil
+}
+
+func (f *fakeAuctionUseCase) FindWinningBidByAuctionId(
+	ctx context.Context,
+	id string,
+) (*usecase.WinningInfoOutputDTO, error) {
+	auction, err := f.FindAuctionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &usecase.WinningInfoOutputDTO{Auction: *auction}, nil
+}
+
+func (f *fakeAuctionUseCase) FindAuctions(
+	_ context.Context,
+	input usecase.AuctionListInputDTO,
+) (*usecase.AuctionListOutputDTO, error) {
+	f.listed = append(f.listed, input)
+	return &usecase.AuctionListOutputDTO{Auctions: []usecase.AuctionOutputDTO{}}, nil
+}
+
+func requireStatus(t *testing.T, want int, rec *httptest.ResponseRecorder) {
+	t.Helper()
+	require.Equal(t, want, rec.Code, rec.Body.String())
+}
+
+var (
+	testSellerId = "8f0c3a52-6d1e-4b9a-9a53-2f7d1c4e8b10"
+	testOtherId  = "1b7e2f90-3c4d-4e5f-8a6b-7c8d9e0f1a2b"
+)
+
+func TestFindAuctionsStatusVisibility(t *testing.T) {
+	seller := &auth.Principal{UserId: testSellerId, Roles: []string{auth.RoleSeller}}
+	admin := &auth.Principal{UserId: testOtherId, Roles: []string{auth.RoleAdmin}}
+
+	tests := []struct {
+		name      string
+		query     string
+		principal *auth.Principal
+		want      int
+	}{
+		{name: "public listing", query: "", want: http.StatusOK},
+		{name: "completed auctions", query: "?status=1", want: http.StatusOK},
+		{name: "drafts anonymously", query: "?status=3", want: http.StatusForbidden},
+		{name: "pending anonymously", query: "?status=4", want: http.StatusForbidden},
+		{name: "rejected anonymously", query: "?status=5", want: http.StatusForbidden},
+		{name: "unknown status", query: "?status=9", want: http.StatusBadRequest},
+		{name: "drafts of everyone as seller", query: "?status=3", principal: seller, want: http.StatusForbidden},
+		{name: "drafts of another seller", query: "?status=3&seller_id=" + testOtherId, principal: seller,
+			want: http.StatusForbidden},
+		{name: "own drafts", query: "?status=3&seller_id=" + testSellerId, principal: seller, want: http.StatusOK},
+		{name: "drafts as admin", query: "?status=4", principal: admin, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionUseCase := &fakeAuctionUseCase{}
+			router := gin.New()
+			router.GET("/auction", withPrincipal(tt.principal),
+				NewAuctionController(auctionUseCase, newTestPolicy(t)).FindAuctions)
+
+			rec := serve(router, http.MethodGet, "/auction"+tt.query)
+
+			requireStatus(t, tt.want, rec)
+			if tt.want != http.StatusOK {
+				require.Empty(t, auctionUseCase.listed)
+			}
+		})
+	}
+}
+
+func TestFindAuctionsBySellerIdStatusVisibility(t *testing.T) {
+	seller := &auth.Principal{UserId: testSellerId, Roles: []string{auth.RoleSeller}}
+	admin := &auth.Principal{UserId: testOtherId, Roles: []string{auth.RoleAdmin}}
+
+	tests := []struct {
+		name      string
+		sellerId  string
+		query     string
+		principal *auth.Principal
+		want      int
+	}{
+		{name: "public listing", sellerId: testSellerId, want: http.StatusOK},
+		{name: "cancelled auctions", sellerId: testSellerId, query: "?status=2", want: http.StatusOK},
+		{name: "drafts anonymously", sellerId: testSellerId, query: "?status=3", want: http.StatusForbidden},
+		{name: "pending of another seller", sellerId: testOtherId, query: "?status=4", principal: seller,
+			want: http.StatusForbidden},
+		{name: "own pending", sellerId: testSellerId, query: "?status=4", principal: seller, want: http.StatusOK},
+		{name: "rejected as admin", sellerId: testSellerId, query: "?status=5", principal: admin, want: http.StatusOK},
+		{name: "unknown status", sellerId: testSellerId, query: "?status=0", want: http.StatusOK},
+		{name: "invalid seller", sellerId: "seller", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionUseCase := &fakeAuctionUseCase{}
+			router := gin.New()
+			router.GET("/user/:userId/auctions", withPrincipal(tt.principal),
+				NewAuctionController(auctionUseCase, newTestPolicy(t)).FindAuctionsBySellerId)
+
+			rec := serve(router, http.MethodGet, "/user/"+tt.sellerId+"/auctions"+tt.query)
+
+			requireStatus(t, tt.want, rec)
+			if tt.want != http.StatusOK {
+				require.Empty(t, auctionUseCase.listed)
+				return
+			}
+			require.Len(t, auctionUseCase.listed, 1)
+			require.Equal(t, tt.sellerId, auctionUseCase.listed[0].SellerId)
+		})
+	}
+}
+
+func TestFindAuctionsCondition(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "?condition=1", want: http.StatusOK},
+		{query: "?condition=2", want: http.StatusOK},
+		{query: "?condition=3", want: http.StatusOK},
+		{query: "?condition=0", want: http.StatusBadRequest},
+		{query: "?condition=4", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/auction", NewAuctionController(&fakeAuctionUseCase{}, newTestPolicy(t)).FindAuctions)
+
+			rec := serve(router, http.MethodGet, "/auction"+tt.query)
+
+			requireStatus(t, tt.want, rec)
+		})
+	}
+}
+
+func TestAuctionInputCondition(t *testing.T) {
+	tests := []struct {
+		condition usecase.ProductCondition
+		wantErr   bool
+	}{
+		{condition: 1},
+		{condition: 3},
+		{condition: 0, wantErr: true},
+		{condition: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(int(tt.condition)), func(t *testing.T) {
+			input := usecase.AuctionInputDTO{
+				ProductName: "Camera",
+				Category:    "cameras",
+				Description: "A camera in good shape",
+				Condition:   tt.condition,
+			}
+			update := usecase.AuctionUpdateInputDTO{Condition: &tt.condition, Version: 1}
+
+			createErr := binding.Validator.ValidateStruct(input)
+			updateErr := binding.Validator.ValidateStruct(update)
+
+			if tt.wantErr {
+				require.Error(t, createErr)
+				require.Error(t, updateErr)
+				return
+			}
+			require.NoError(t, createErr)
+			require.NoError(t, updateErr)
+		})
+	}
+}
+
+func TestFindAuctionByIdVisibility(t *testing.T) {
+	seller := &auth.Principal{UserId: testSellerId, Roles: []string{auth.RoleSeller}}
+	otherSeller := &auth.Principal{UserId: testOtherId, Roles: []string{auth.RoleSeller}}
+	admin := &auth.Principal{UserId: testOtherId, Roles: []string{auth.RoleAdmin}}
+	auctionId := "4c1f8e2a-9b3d-4e7f-a6c5-2d8b1e0f3a94"
+
+	tests := []struct {
+		name      string
+		status    entity.AuctionStatus
+		principal *auth.Principal
+		want      int
+	}{
+		{name: "active anonymously", status: entity.Active, want: http.StatusOK},
+		{name: "draft anonymously", status: entity.Draft, want: http.StatusNotFound},
+		{name: "pending of another seller", status: entity.PendingApproval, principal: otherSeller,
+			want: http.StatusNotFound},
+		{name: "own rejected", status: entity.Rejected, principal: seller, want: http.StatusOK},
+		{name: "draft as admin", status: entity.Draft, principal: admin, want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionUseCase := &fakeAuctionUseCase{auction: &usecase.AuctionOutputDTO{
+				Id:       auctionId,
+				SellerId: testSellerId,
+				Status:   usecase.AuctionStatus(tt.status),
+			}}
+			controller := NewAuctionController(auctionUseCase, newTestPolicy(t))
+			router := gin.New()
+			router.GET("/auction/:auctionId", withPrincipal(tt.principal), controller.FindAuctionById)
+			router.GET("/auction/winner/:auctionId", withPrincipal(tt.principal), controller.FindWinningBidByAuctionId)
+
+			requireStatus(t, tt.want, serve(router, http.MethodGet, "/auction/"+auctionId))
+			requireStatus(t, tt.want, serve(router, http.MethodGet, "/auction/winner/"+auctionId))
+		})
+	}
+}
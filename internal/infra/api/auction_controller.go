@@ -1,21 +1,23 @@
 package api
 
 import (
+	"fmt"
+	"fullcycle-auction_go/configuration/auth"
 	"fullcycle-auction_go/configuration/rest_err"
 	"fullcycle-auction_go/internal/usecase"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"net/http"
-	"strconv"
 )
 
 type AuctionController struct {
 	auctionUseCase usecase.AuctionUseCase
+	policy         *auth.Policy
 }
 
-func NewAuctionController(auctionUseCase usecase.AuctionUseCase) *AuctionController {
+func NewAuctionController(auctionUseCase usecase.AuctionUseCase, policy *auth.Policy) *AuctionController {
 	return &AuctionController{
 		auctionUseCase: auctionUseCase,
+		policy:         policy,
 	}
 }
 
@@ -28,8 +30,12 @@ func (u *AuctionController) CreateAuction(c *gin.Context) {
 		c.JSON(restErr.Code, restErr)
 		return
 	}
+	auctionInputDTO.SellerId, _ = auth.SubjectFromContext(c.Request.Context())
+	if !authorize(c, u.policy, auth.ActionAuctionCreate, auctionInputDTO.SellerId) {
+		return
+	}
 
-	err := u.auctionUseCase.CreateAuction(c.Request.Context(), auctionInputDTO)
+	auctionData, err := u.auctionUseCase.CreateAuction(c.Request.Context(), auctionInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 
@@ -37,50 +43,181 @@ func (u *AuctionController) CreateAuction(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusCreated)
+	c.Header("Location", "/auction/"+auctionData.Id)
+	c.JSON(http.StatusCreated, auctionData)
 }
 
 func (u *AuctionController) FindAuctionById(c *gin.Context) {
-	auctionId := c.Param("auctionId")
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	auctionData, err := findVisibleAuction(c.Request.Context(), u.policy, u.auctionUseCase, auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionController) FindAuctions(c *gin.Context) {
+	var auctionListInputDTO usecase.AuctionListInputDTO
 
-	if err := uuid.Validate(auctionId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
-			Field:   "auctionId",
-			Message: "Invalid UUID value",
-		})
+	if err := c.ShouldBindQuery(&auctionListInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+	auctionListInputDTO.Attributes = c.QueryMap("attr")
+	if !authorizeAuctionListing(c, u.policy, auctionListInputDTO) {
+		return
+	}
 
+	auctions, err := u.auctionUseCase.FindAuctions(c.Request.Context(), auctionListInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
-	auctionData, err := u.auctionUseCase.FindAuctionById(c.Request.Context(), auctionId)
+	c.JSON(http.StatusOK, auctions)
+}
+
+func (u *AuctionController) SearchAuctions(c *gin.Context) {
+	var auctionSearchInputDTO usecase.AuctionSearchInputDTO
+
+	if err := c.ShouldBindQuery(&auctionSearchInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	results, err := u.auctionUseCase.SearchAuctions(c.Request.Context(), auctionSearchInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
+	c.JSON(http.StatusOK, results)
+}
+
+func (u *AuctionController) FindWinningBidByAuctionId(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	auctionData, err := u.auctionUseCase.FindWinningBidByAuctionId(c.Request.Context(), auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+	if !auctionVisible(c.Request.Context(), u.policy, &auctionData.Auction) {
+		errRest := rest_err.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", auctionId))
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
 	c.JSON(http.StatusOK, auctionData)
 }
 
-func (u *AuctionController) FindAuctions(c *gin.Context) {
-	status := c.Query("status")
-	category := c.Query("category")
-	productName := c.Query("productName")
+func (u *AuctionController) CancelAuction(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var cancelInputDTO usecase.AuctionCancelInputDTO
+	if err := c.ShouldBindJSON(&cancelInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
 
-	statusNumber, errConv := strconv.Atoi(status)
-	if errConv != nil {
-		errRest := rest_err.NewBadRequestError("Error trying to validate auction status param")
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionAuctionCancel, auctionId) {
+		return
+	}
+
+	auctionData, err := u.auctionUseCase.CancelAuction(c.Request.Context(), auctionId, cancelInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
-	auctions, err := u.auctionUseCase.FindAuctions(
-		c.Request.Context(),
-		usecase.AuctionStatus(statusNumber),
-		category,
-		productName,
-	)
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionController) UpdateAuction(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var updateInputDTO usecase.AuctionUpdateInputDTO
+	if err := c.ShouldBindJSON(&updateInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionAuctionUpdate, auctionId) {
+		return
+	}
+
+	auctionData, err := u.auctionUseCase.UpdateAuction(c.Request.Context(), auctionId, updateInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionController) SubmitAuction(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionAuctionSubmit, auctionId) {
+		return
+	}
+
+	auctionData, err := u.auctionUseCase.SubmitAuction(c.Request.Context(), auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionController) FindPendingAuctions(c *gin.Context) {
+	var auctionListInputDTO usecase.AuctionListInputDTO
+	if err := c.ShouldBindQuery(&auctionListInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionAuctionModerate, "") {
+		return
+	}
+
+	auctions, err := u.auctionUseCase.FindPendingAuctions(c.Request.Context(), auctionListInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -90,20 +227,116 @@ func (u *AuctionController) FindAuctions(c *gin.Context) {
 	c.JSON(http.StatusOK, auctions)
 }
 
-func (u *AuctionController) FindWinningBidByAuctionId(c *gin.Context) {
-	auctionId := c.Param("auctionId")
+func (u *AuctionController) ApproveAuction(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
 
-	if err := uuid.Validate(auctionId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
-			Field:   "auctionId",
-			Message: "Invalid UUID value",
-		})
+	if !authorize(c, u.policy, auth.ActionAuctionModerate, "") {
+		return
+	}
 
+	auctionData, err := u.auctionUseCase.ApproveAuction(c.Request.Context(), auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
-	auctionData, err := u.auctionUseCase.FindWinningBidByAuctionId(c.Request.Context(), auctionId)
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionController) RejectAuction(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var rejectInputDTO usecase.AuctionRejectInputDTO
+	if err := c.ShouldBindJSON(&rejectInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionAuctionModerate, "") {
+		return
+	}
+
+	auctionData, err := u.auctionUseCase.RejectAuction(c.Request.Context(), auctionId, rejectInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+// FindAuctionsBySellerId pages through the auctions of a seller, with the
+// same filters, sort options and visibility as the auction listing.
+func (u *AuctionController) FindAuctionsBySellerId(c *gin.Context) {
+	sellerId, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var auctionListInputDTO usecase.AuctionListInputDTO
+	if err := c.ShouldBindQuery(&auctionListInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+	auctionListInputDTO.SellerId = sellerId
+	auctionListInputDTO.Attributes = c.QueryMap("attr")
+	if !authorizeAuctionListing(c, u.policy, auctionListInputDTO) {
+		return
+	}
+
+	auctions, err := u.auctionUseCase.FindAuctions(c.Request.Context(), auctionListInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctions)
+}
+
+func (u *AuctionController) CloseAuction(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionAuctionClose, "") {
+		return
+	}
+
+	auctionData, err := u.auctionUseCase.CloseAuction(c.Request.Context(), auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionController) ReopenAuction(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionAuctionReopen, "") {
+		return
+	}
+
+	auctionData, err := u.auctionUseCase.ReopenAuction(c.Request.Context(), auctionId)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
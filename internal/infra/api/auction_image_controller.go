@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// maxImageUploadBody bounds the whole multipart request; the use case applies
+// the stricter limit on the image itself.
+const maxImageUploadBody = 32 << 20
+
+type AuctionImageController struct {
+	auctionUseCase      usecase.AuctionUseCase
+	auctionImageUseCase usecase.AuctionImageUseCase
+	policy              *auth.Policy
+}
+
+func NewAuctionImageController(
+	auctionUseCase usecase.AuctionUseCase,
+	auctionImageUseCase usecase.AuctionImageUseCase,
+	policy *auth.Policy,
+) *AuctionImageController {
+	return &AuctionImageController{
+		auctionUseCase:      auctionUseCase,
+		auctionImageUseCase: auctionImageUseCase,
+		policy:              policy,
+	}
+}
+
+// UploadImage takes the image from the "image" field of a multipart form.
+func (u *AuctionImageController) UploadImage(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUploadBody)
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "image",
+			Message: "An image file of at most 32MB is required",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionAuctionUpdate, auctionId) {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		errRest := rest_err.NewBadRequestError("Error trying to read image file")
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+	defer file.Close()
+
+	imageData, err := u.auctionImageUseCase.UploadImage(c.Request.Context(), auctionId, file)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Header("Location", imageData.URL)
+	c.JSON(http.StatusCreated, imageData)
+}
+
+func (u *AuctionImageController) DeleteImage(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+	imageId, ok := uuidParam(c, "imageId")
+	if !ok {
+		return
+	}
+
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionAuctionUpdate, auctionId) {
+		return
+	}
+
+	if err := u.auctionImageUseCase.DeleteImage(c.Request.Context(), auctionId, imageId); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *AuctionImageController) ReorderImages(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var orderInputDTO usecase.AuctionImageOrderInputDTO
+	if err := c.ShouldBindJSON(&orderInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionAuctionUpdate, auctionId) {
+		return
+	}
+
+	auctionData, err := u.auctionImageUseCase.ReorderImages(c.Request.Context(), auctionId, orderInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func (u *AuctionImageController) SetPrimaryImage(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+	imageId, ok := uuidParam(c, "imageId")
+	if !ok {
+		return
+	}
+
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionAuctionUpdate, auctionId) {
+		return
+	}
+
+	auctionData, err := u.auctionImageUseCase.SetPrimaryImage(c.Request.Context(), auctionId, imageId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, auctionData)
+}
+
+func uuidParam(c *gin.Context, name string) (string, bool) {
+	value := c.Param(name)
+
+	if err := uuid.Validate(value); err != nil {
+		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   name,
+			Message: "Invalid UUID value",
+		})
+
+		c.JSON(errRest.Code, errRest)
+		return "", false
+	}
+
+	return value, true
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+const (
+	streamKeepAlive = 15 * time.Second
+
+	// streamRetryMillis tells browsers how long to wait before reconnecting.
+	streamRetryMillis = 3000
+)
+
+type AuctionStreamController struct {
+	auctionStreamUseCase usecase.AuctionStreamUseCase
+	auctionUseCase       usecase.AuctionUseCase
+	policy               *auth.Policy
+}
+
+func NewAuctionStreamController(
+	auctionStreamUseCase usecase.AuctionStreamUseCase,
+	auctionUseCase usecase.AuctionUseCase,
+	policy *auth.Policy,
+) *AuctionStreamController {
+	return &AuctionStreamController{
+		auctionStreamUseCase: auctionStreamUseCase,
+		auctionUseCase:       auctionUseCase,
+		policy:               policy,
+	}
+}
+
+// StreamEvents follows an auction as Server-Sent Events, replaying the events
+// missed since a Last-Event-ID header.
+func (u *AuctionStreamController) StreamEvents(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+	if !authorizeAuctionRead(c, u.policy, u.auctionUseCase, auctionId) {
+		return
+	}
+
+	subscription, err := u.auctionStreamUseCase.Subscribe(
+		c.Request.Context(), auctionId, c.GetHeader("Last-Event-ID"))
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetryMillis)
+	for _, event := range subscription.Replay {
+		if !writeStreamEvent(c, event) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return
+			}
+			if !writeStreamEvent(c, event) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+func writeStreamEvent(c *gin.Context, event usecase.AuctionStreamEventDTO) bool {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to encode %s stream event", event.Type), err)
+		return true
+	}
+
+	_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+	return err == nil
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAuctionRepository finds the auctions it holds. It embeds the repository
+// interface, so calling anything else fails the test.
+type fakeAuctionRepository struct {
+	repository.AuctionRepository
+
+	auctions map[string]*entity.Auction
+}
+
+func (r *fakeAuctionRepository) FindAuctionById(_ context.Context, id string) (*entity.Auction, error) {
+	auction, ok := r.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", id))
+	}
+	return auction, nil
+}
+
+func TestStreamEventsResume(t *testing.T) {
+	auction := &entity.Auction{
+		Id:           "4c1f8e2a-9b3d-4e7f-a6c5-2d8b1e0f3a94",
+		Status:       entity.Active,
+		CurrentPrice: 12,
+		Timestamp:    time.Now(),
+	}
+
+	tests := []struct {
+		name        string
+		lastEventId func(lastId uint64) string
+		wantEvents  []string
+	}{
+		{name: "live events only", lastEventId: func(uint64) string { return "" }},
+		{name: "resuming from a kept event", lastEventId: func(lastId uint64) string { return fmt.Sprint(lastId - 1) },
+			wantEvents: []string{usecase.StreamPriceChanged}},
+		{name: "resuming from a stale event", lastEventId: func(uint64) string { return "1" },
+			wantEvents: []string{usecase.StreamReset}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamUseCase := usecase.NewAuctionStreamUseCase(
+				&fakeAuctionRepository{auctions: map[string]*entity.Auction{auction.Id: auction}})
+			subscription, err := streamUseCase.Subscribe(context.Background(), auction.Id, "")
+			require.NoError(t, err)
+			defer subscription.Close()
+
+			event := entity.NewAuctionEvent(entity.EventBidAccepted, auction.Id)
+			event.Bid, event.Leading = &entity.Bid{AuctionId: auction.Id, Amount: 12}, true
+			streamUseCase.HandleAuctionEvent(context.Background(), event)
+			var lastId uint64
+			for len(subscription.Events) > 0 {
+				lastId = (<-subscription.Events).Id
+			}
+
+			router := gin.New()
+			auctionUseCase := &fakeAuctionUseCase{auction: &usecase.AuctionOutputDTO{
+				Id:     auction.Id,
+				Status: usecase.AuctionStatus(auction.Status),
+			}}
+			controller := NewAuctionStreamController(streamUseCase, auctionUseCase, newTestPolicy(t))
+			router.GET("/auction/:auctionId/events", controller.StreamEvents)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req := httptest.NewRequest(http.MethodGet, "/auction/"+auction.Id+"/events", nil).WithContext(ctx)
+			req.Header.Set("Last-Event-ID", tt.lastEventId(lastId))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			requireStatus(t, http.StatusOK, rec)
+			var events []string
+			for _, line := range strings.Split(rec.Body.String(), "\n") {
+				if eventType, ok := strings.CutPrefix(line, "event: "); ok {
+					events = append(events, eventType)
+				}
+			}
+			require.Equal(t, tt.wantEvents, events)
+		})
+	}
+}
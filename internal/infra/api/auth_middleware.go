@@ -0,0 +1,43 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	bearerPrefix = "Bearer "
+	apiKeyHeader = "X-API-Key"
+)
+
+// AuthMiddleware rejects requests without a valid bearer token or API key and
+// stores the principal it identifies in the request context.
+func AuthMiddleware(authUseCase usecase.AuthUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authUseCase.Authenticate(
+			c.Request.Context(), c.GetHeader(apiKeyHeader), c.GetHeader("Authorization"))
+		if err != nil {
+			errRest := rest_err.ConvertError(err)
+			c.AbortWithStatusJSON(errRest.Code, errRest)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware identifies the caller of a public route when
+// credentials are sent, rejecting invalid ones.
+func OptionalAuthMiddleware(authUseCase usecase.AuthUseCase) gin.HandlerFunc {
+	authenticate := AuthMiddleware(authUseCase)
+	return func(c *gin.Context) {
+		if c.GetHeader(apiKeyHeader) == "" && c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testJWTSecret = "test-secret"
+
+func newTestVerifier(t *testing.T) *auth.JWTVerifier {
+	t.Helper()
+
+	t.Setenv("JWT_HS256_SECRET", testJWTSecret)
+	verifier, err := auth.NewJWTVerifier()
+	require.NoError(t, err)
+	return verifier
+}
+
+func signTestToken(t *testing.T, subject string, expiresIn time.Duration, roles ...string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   subject,
+		"exp":   time.Now().Add(expiresIn).Unix(),
+		"roles": roles,
+	})
+	signed, err := token.SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return signed
+}
+
+func TestOptionalAuthMiddleware(t *testing.T) {
+	verifier := newTestVerifier(t)
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+		wantSubject   string
+	}{
+		{name: "anonymous", want: http.StatusOK},
+		{name: "valid token", authorization: "Bearer " + signTestToken(t, testSellerId, time.Hour),
+			want: http.StatusOK, wantSubject: testSellerId},
+		{name: "expired token", authorization: "Bearer " + signTestToken(t, testSellerId, -time.Hour),
+			want: http.StatusUnauthorized},
+		{name: "not a bearer token", authorization: "Basic dXNlcjpwYXNz", want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subject string
+			router := gin.New()
+			router.GET("/", OptionalAuthMiddleware(usecase.NewAuthUseCase(verifier, nil)), func(c *gin.Context) {
+				subject, _ = auth.SubjectFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			requireStatus(t, tt.want, rec)
+			require.Equal(t, tt.wantSubject, subject)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// authorize consults the policy for the caller and writes the 403 response
+// when the action is denied. ownerId is the user owning the target resource.
+func authorize(c *gin.Context, policy *auth.Policy, action, ownerId string) bool {
+	if err := policy.Authorize(c.Request.Context(), action, ownerId); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return false
+	}
+	return true
+}
+
+// authorizeOnAuction authorizes an action on an existing auction, which is
+// owned by its seller.
+func authorizeOnAuction(
+	c *gin.Context,
+	policy *auth.Policy,
+	auctionUseCase usecase.AuctionUseCase,
+	action, auctionId string,
+) bool {
+	auctionData, err := auctionUseCase.FindAuctionById(c.Request.Context(), auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return false
+	}
+
+	return authorize(c, policy, action, auctionData.SellerId)
+}
+
+// authorizeAuctionListing lets only admins and the seller filtering on
+// themselves list auctions that are not public.
+func authorizeAuctionListing(c *gin.Context, policy *auth.Policy, input usecase.AuctionListInputDTO) bool {
+	if input.Status.IsPublic() {
+		return true
+	}
+	return authorize(c, policy, auth.ActionAuctionReadDraft, input.SellerId)
+}
+
+// auctionVisible reports whether the caller may see the auction. Drafts and
+// auctions under moderation are only shown to their seller and to admins.
+func auctionVisible(ctx context.Context, policy *auth.Policy, auction *usecase.AuctionOutputDTO) bool {
+	return auction.Status.IsPublic() || policy.Authorize(ctx, auth.ActionAuctionReadDraft, auction.SellerId) == nil
+}
+
+// findVisibleAuction loads an auction the caller may see, reporting the
+// others as not found.
+func findVisibleAuction(
+	ctx context.Context,
+	policy *auth.Policy,
+	auctionUseCase usecase.AuctionUseCase,
+	auctionId string,
+) (*usecase.AuctionOutputDTO, error) {
+	auctionData, err := auctionUseCase.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+	if !auctionVisible(ctx, policy, auctionData) {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", auctionId))
+	}
+	return auctionData, nil
+}
+
+// authorizeAuctionRead writes the 404 response when the caller may not see
+// the auction.
+func authorizeAuctionRead(
+	c *gin.Context,
+	policy *auth.Policy,
+	auctionUseCase usecase.AuctionUseCase,
+	auctionId string,
+) bool {
+	if _, err := findVisibleAuction(c.Request.Context(), policy, auctionUseCase, auctionId); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return false
+	}
+	return true
+}
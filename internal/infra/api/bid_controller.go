@@ -1,20 +1,28 @@
 package api
 
 import (
+	"fullcycle-auction_go/configuration/auth"
 	"fullcycle-auction_go/configuration/rest_err"
 	"fullcycle-auction_go/internal/usecase"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"net/http"
 )
 
 type BidController struct {
-	bidUseCase usecase.BidUseCase
+	bidUseCase     usecase.BidUseCase
+	auctionUseCase usecase.AuctionUseCase
+	policy         *auth.Policy
 }
 
-func NewBidController(bidUseCase usecase.BidUseCase) *BidController {
+func NewBidController(
+	bidUseCase usecase.BidUseCase,
+	auctionUseCase usecase.AuctionUseCase,
+	policy *auth.Policy,
+) *BidController {
 	return &BidController{
-		bidUseCase: bidUseCase,
+		bidUseCase:     bidUseCase,
+		auctionUseCase: auctionUseCase,
+		policy:         policy,
 	}
 }
 
@@ -27,8 +35,12 @@ func (u *BidController) CreateBid(c *gin.Context) {
 		c.JSON(restErr.Code, restErr)
 		return
 	}
+	bidInputDTO.UserId, _ = auth.SubjectFromContext(c.Request.Context())
+	if !authorize(c, u.policy, auth.ActionBidCreate, bidInputDTO.UserId) {
+		return
+	}
 
-	err := u.bidUseCase.CreateBid(c.Request.Context(), bidInputDTO)
+	bidData, err := u.bidUseCase.CreateBid(c.Request.Context(), bidInputDTO)
 	if err != nil {
 		restErr := rest_err.ConvertError(err)
 
@@ -36,23 +48,29 @@ func (u *BidController) CreateBid(c *gin.Context) {
 		return
 	}
 
-	c.Status(http.StatusCreated)
+	// The bid is only queued here; it is written, or dropped when the auction
+	// has closed meanwhile, with the next batch.
+	c.JSON(http.StatusAccepted, bidData)
 }
 
 func (u *BidController) FindBidByAuctionId(c *gin.Context) {
-	auctionId := c.Param("auctionId")
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
 
-	if err := uuid.Validate(auctionId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
-			Field:   "auctionId",
-			Message: "Invalid UUID value",
-		})
+	var bidListInputDTO usecase.BidListInputDTO
+	if err := c.ShouldBindQuery(&bidListInputDTO); err != nil {
+		restErr := ValidateErr(err)
 
-		c.JSON(errRest.Code, errRest)
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+	if !authorizeAuctionRead(c, u.policy, u.auctionUseCase, auctionId) {
 		return
 	}
 
-	bidOutputList, err := u.bidUseCase.FindBidByAuctionId(c.Request.Context(), auctionId)
+	bidOutputList, err := u.bidUseCase.FindBidByAuctionId(c.Request.Context(), auctionId, bidListInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
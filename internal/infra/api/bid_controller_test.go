@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBidUseCase queues the bids it is given.
+type fakeBidUseCase struct {
+	usecase.BidUseCase
+
+	queued []usecase.BidInputDTO
+}
+
+func (f *fakeBidUseCase) CreateBid(_ context.Context, input usecase.BidInputDTO) (*usecase.BidOutputDTO, error) {
+	f.queued = append(f.queued, input)
+	return &usecase.BidOutputDTO{
+		Id:        testOtherId,
+		UserId:    input.UserId,
+		AuctionId: input.AuctionId,
+		Amount:    input.Amount,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+func TestBidControllerCreateBid(t *testing.T) {
+	bidder := &auth.Principal{UserId: testOtherId, Roles: []string{auth.RoleBidder}}
+	seller := &auth.Principal{UserId: testOtherId, Roles: []string{auth.RoleSeller}}
+	validBody := `{"auction_id": "` + testSellerId + `", "amount": 10}`
+
+	tests := []struct {
+		name      string
+		body      string
+		principal *auth.Principal
+		want      int
+	}{
+		{name: "queued", body: validBody, principal: bidder, want: http.StatusAccepted},
+		{name: "anonymous", body: validBody, want: http.StatusForbidden},
+		{name: "not a bidder", body: validBody, principal: seller, want: http.StatusForbidden},
+		{name: "malformed body", body: `{"amount": 10`, principal: bidder, want: http.StatusBadRequest},
+		{name: "invalid auction id", body: `{"auction_id": "42", "amount": 10}`, principal: bidder,
+			want: http.StatusBadRequest},
+		{name: "amount not positive", body: `{"auction_id": "` + testSellerId + `", "amount": 0}`, principal: bidder,
+			want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidUseCase := &fakeBidUseCase{}
+			router := gin.New()
+			router.POST("/bid", withPrincipal(tt.principal),
+				NewBidController(bidUseCase, &fakeAuctionUseCase{}, newTestPolicy(t)).CreateBid)
+
+			req := httptest.NewRequest(http.MethodPost, "/bid", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			requireStatus(t, tt.want, rec)
+			require.Empty(t, rec.Header().Get("Location"))
+			if tt.want != http.StatusAccepted {
+				require.Empty(t, bidUseCase.queued)
+				return
+			}
+			require.Len(t, bidUseCase.queued, 1)
+			require.Equal(t, testOtherId, bidUseCase.queued[0].UserId)
+		})
+	}
+}
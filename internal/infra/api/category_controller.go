@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type CategoryController struct {
+	categoryUseCase usecase.CategoryUseCase
+	policy          *auth.Policy
+}
+
+func NewCategoryController(categoryUseCase usecase.CategoryUseCase, policy *auth.Policy) *CategoryController {
+	return &CategoryController{
+		categoryUseCase: categoryUseCase,
+		policy:          policy,
+	}
+}
+
+func (u *CategoryController) CreateCategory(c *gin.Context) {
+	var categoryInputDTO usecase.CategoryInputDTO
+
+	if err := c.ShouldBindJSON(&categoryInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionCategoryManage, "") {
+		return
+	}
+
+	categoryData, err := u.categoryUseCase.CreateCategory(c.Request.Context(), categoryInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header("Location", "/category/"+categoryData.Id)
+	c.JSON(http.StatusCreated, categoryData)
+}
+
+func (u *CategoryController) FindCategories(c *gin.Context) {
+	categories, err := u.categoryUseCase.FindCategories(c.Request.Context())
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
+
+func (u *CategoryController) FindCategoryById(c *gin.Context) {
+	categoryId, ok := uuidParam(c, "categoryId")
+	if !ok {
+		return
+	}
+
+	categoryData, err := u.categoryUseCase.FindCategoryById(c.Request.Context(), categoryId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, categoryData)
+}
+
+func (u *CategoryController) UpdateCategory(c *gin.Context) {
+	categoryId, ok := uuidParam(c, "categoryId")
+	if !ok {
+		return
+	}
+
+	var categoryUpdateInputDTO usecase.CategoryUpdateInputDTO
+	if err := c.ShouldBindJSON(&categoryUpdateInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionCategoryManage, "") {
+		return
+	}
+
+	categoryData, err := u.categoryUseCase.UpdateCategory(c.Request.Context(), categoryId, categoryUpdateInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, categoryData)
+}
+
+func (u *CategoryController) DeleteCategory(c *gin.Context) {
+	categoryId, ok := uuidParam(c, "categoryId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionCategoryManage, "") {
+		return
+	}
+
+	if err := u.categoryUseCase.DeleteCategory(c.Request.Context(), categoryId); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
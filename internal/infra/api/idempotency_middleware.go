@@ -0,0 +1,100 @@
+package api
+
+import (
+	"bytes"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+const idempotencyKeyHeader = "Idempotency-Key"
+
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware replays the stored response to retries with the same
+// Idempotency-Key and body, releasing keys of requests that failed or panicked.
+func IdempotencyMiddleware(idempotencyUseCase usecase.IdempotencyUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			errRest := rest_err.NewBadRequestError("Error trying to read request body")
+			c.AbortWithStatusJSON(errRest.Code, errRest)
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		subject, _ := auth.SubjectFromContext(c.Request.Context())
+		scopedKey := subject + ":" + c.Request.Method + " " + c.FullPath() + ":" + key
+
+		replay, err := idempotencyUseCase.Begin(c.Request.Context(), scopedKey, c.Request.Method, c.Request.URL.Path, body)
+		if err != nil {
+			errRest := rest_err.ConvertError(err)
+			c.AbortWithStatusJSON(errRest.Code, errRest)
+			return
+		}
+		if replay != nil {
+			c.Header("Idempotent-Replayed", "true")
+			if len(replay.Body) == 0 {
+				c.AbortWithStatus(replay.StatusCode)
+				return
+			}
+			c.Data(replay.StatusCode, replay.ContentType, replay.Body)
+			c.Abort()
+			return
+		}
+
+		release := func() {
+			if err := idempotencyUseCase.Abort(c.Request.Context(), scopedKey); err != nil {
+				logger.Error("Error trying to release idempotency key", err)
+			}
+		}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				release()
+				panic(recovered)
+			}
+		}()
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			release()
+			return
+		}
+
+		response := usecase.IdempotentResponseDTO{
+			StatusCode:  writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if err := idempotencyUseCase.Complete(c.Request.Context(), scopedKey, response); err != nil {
+			logger.Error("Error trying to store idempotent response", err)
+			_ = idempotencyUseCase.Abort(c.Request.Context(), scopedKey)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeIdempotencyUseCase keeps the claimed keys in memory.
+type fakeIdempotencyUseCase struct {
+	claimed   map[string]bool
+	completed map[string]usecase.IdempotentResponseDTO
+	aborted   []string
+	mutex     sync.Mutex
+}
+
+func newFakeIdempotencyUseCase() *fakeIdempotencyUseCase {
+	return &fakeIdempotencyUseCase{
+		claimed:   make(map[string]bool),
+		completed: make(map[string]usecase.IdempotentResponseDTO),
+	}
+}
+
+func (f *fakeIdempotencyUseCase) Begin(
+	_ context.Context,
+	key, _, _ string,
+	_ []byte,
+) (*usecase.IdempotentResponseDTO, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if response, ok := f.completed[key]; ok {
+		return &response, nil
+	}
+	f.claimed[key] = true
+	return nil, nil
+}
+
+func (f *fakeIdempotencyUseCase) Complete(_ context.Context, key string, response usecase.IdempotentResponseDTO) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.completed[key] = response
+	return nil
+}
+
+func (f *fakeIdempotencyUseCase) Abort(_ context.Context, key string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.claimed, key)
+	f.aborted = append(f.aborted, key)
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       gin.HandlerFunc
+		want          int
+		wantCompleted bool
+		wantAborted   bool
+	}{
+		{
+			name:          "success is stored",
+			handler:       func(c *gin.Context) { c.JSON(http.StatusAccepted, gin.H{"ok": true}) },
+			want:          http.StatusAccepted,
+			wantCompleted: true,
+		},
+		{
+			name:          "client error is stored",
+			handler:       func(c *gin.Context) { c.JSON(http.StatusBadRequest, gin.H{"ok": false}) },
+			want:          http.StatusBadRequest,
+			wantCompleted: true,
+		},
+		{
+			name:        "server error releases the key",
+			handler:     func(c *gin.Context) { c.JSON(http.StatusInternalServerError, gin.H{"ok": false}) },
+			want:        http.StatusInternalServerError,
+			wantAborted: true,
+		},
+		{
+			name:        "panic releases the key",
+			handler:     func(c *gin.Context) { panic("handler failed") },
+			want:        http.StatusInternalServerError,
+			wantAborted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idempotencyUseCase := newFakeIdempotencyUseCase()
+			router := gin.New()
+			router.Use(gin.Recovery())
+			router.POST("/bid", IdempotencyMiddleware(idempotencyUseCase), tt.handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/bid", strings.NewReader(`{"amount": 10}`))
+			req.Header.Set(idempotencyKeyHeader, "key-1")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			requireStatus(t, tt.want, rec)
+			require.Len(t, idempotencyUseCase.completed, boolCount(tt.wantCompleted))
+			require.Len(t, idempotencyUseCase.aborted, boolCount(tt.wantAborted))
+			if tt.wantAborted {
+				require.Empty(t, idempotencyUseCase.claimed)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareReplaysResponse(t *testing.T) {
+	idempotencyUseCase := newFakeIdempotencyUseCase()
+	calls := 0
+	router := gin.New()
+	router.POST("/bid", IdempotencyMiddleware(idempotencyUseCase), func(c *gin.Context) {
+		calls++
+		c.JSON(http.StatusAccepted, gin.H{"call": calls})
+	})
+
+	var bodies []string
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/bid", strings.NewReader(`{"amount": 10}`))
+		req.Header.Set(idempotencyKeyHeader, "key-1")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		requireStatus(t, http.StatusAccepted, rec)
+		bodies = append(bodies, rec.Body.String())
+	}
+
+	require.Equal(t, 1, calls)
+	require.Equal(t, bodies[0], bodies[1])
+}
+
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,653 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	liveMaxMessageBytes   = 4 << 10
+	liveMaxSubscriptions  = 20
+	liveOutboundBuffer    = 256
+	liveWriteTimeout      = 10 * time.Second
+	livePongTimeout       = 60 * time.Second
+	livePingInterval      = 50 * time.Second
+	liveMessageRateLimit  = 10
+	liveMessageRateBurst  = 20
+	liveDefaultBidRate    = 2
+	liveDefaultBidBurst   = 5
+	liveAllowedOriginsEnv = "LIVE_ALLOWED_ORIGINS"
+)
+
+// Messages sent by clients.
+const (
+	liveAuth        = "auth"
+	liveSubscribe   = "subscribe"
+	liveUnsubscribe = "unsubscribe"
+	liveBid         = "bid"
+)
+
+// Messages sent to clients.
+const (
+	liveAuthenticated = "authenticated"
+	liveSubscribed    = "subscribed"
+	liveUnsubscribed  = "unsubscribed"
+	liveBidQueued     = "bid_queued"
+	liveBidRejected   = "bid_rejected"
+	liveEvent         = "event"
+	liveError         = "error"
+)
+
+type (
+	LiveBiddingController struct {
+		bidUseCase           usecase.BidUseCase
+		auctionUseCase       usecase.AuctionUseCase
+		auctionStreamUseCase usecase.AuctionStreamUseCase
+		authUseCase          usecase.AuthUseCase
+		userUseCase          usecase.UserUseCase
+		idempotencyUseCase   usecase.IdempotencyUseCase
+		policy               *auth.Policy
+		upgrader             websocket.Upgrader
+		bidRateLimit         float64
+		bidRateBurst         int
+	}
+
+	// liveInboundMessage is any message a client sends. RequestId is echoed
+	// in the reply, and a bid repeating it gets the earlier reply.
+	liveInboundMessage struct {
+		Type        string  `json:"type"`
+		RequestId   string  `json:"request_id"`
+		AuctionId   string  `json:"auction_id"`
+		Amount      float64 `json:"amount"`
+		LastEventId string  `json:"last_event_id"`
+		Token       string  `json:"token"`
+		APIKey      string  `json:"api_key"`
+	}
+
+	liveOutboundMessage struct {
+		Type      string                `json:"type"`
+		RequestId string                `json:"request_id,omitempty"`
+		AuctionId string                `json:"auction_id,omitempty"`
+		UserId    string                `json:"user_id,omitempty"`
+		Bid       *usecase.BidOutputDTO `json:"bid,omitempty"`
+		Event     *liveEventMessage     `json:"event,omitempty"`
+		Error     *rest_err.RestErr     `json:"error,omitempty"`
+	}
+
+	liveEventMessage struct {
+		Id   uint64      `json:"id"`
+		Type string      `json:"type"`
+		Data interface{} `json:"data"`
+	}
+
+	// liveConnection is the state of one client connection, owned by its
+	// reading goroutine. Everything sent to the client goes through outbound.
+	liveConnection struct {
+		controller    *LiveBiddingController
+		conn          *websocket.Conn
+		ctx           context.Context
+		cancel        context.CancelFunc
+		principal     *auth.Principal
+		apiKey        string
+		outbound      chan liveOutboundMessage
+		subscriptions map[string]*liveSubscription
+		mutex         *sync.Mutex
+		messageBucket *tokenBucket
+		bidBucket     *tokenBucket
+	}
+
+	liveSubscription struct {
+		auctionId   string
+		stream      *usecase.AuctionStreamSubscription
+		lastEventId uint64
+	}
+
+	// tokenBucket allows bursts of up to capacity messages, refilled at rate
+	// tokens per second.
+	tokenBucket struct {
+		tokens   float64
+		capacity float64
+		rate     float64
+		updated  time.Time
+	}
+)
+
+func NewLiveBiddingController(
+	bidUseCase usecase.BidUseCase,
+	auctionUseCase usecase.AuctionUseCase,
+	auctionStreamUseCase usecase.AuctionStreamUseCase,
+	authUseCase usecase.AuthUseCase,
+	userUseCase usecase.UserUseCase,
+	idempotencyUseCase usecase.IdempotencyUseCase,
+	policy *auth.Policy,
+) *LiveBiddingController {
+	return &LiveBiddingController{
+		bidUseCase:           bidUseCase,
+		auctionUseCase:       auctionUseCase,
+		auctionStreamUseCase: auctionStreamUseCase,
+		authUseCase:          authUseCase,
+		userUseCase:          userUseCase,
+		idempotencyUseCase:   idempotencyUseCase,
+		policy:               policy,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     newOriginChecker(os.Getenv(liveAllowedOriginsEnv)),
+		},
+		bidRateLimit: getLiveBidRateLimit(),
+		bidRateBurst: getLiveBidRateBurst(),
+	}
+}
+
+// Connect upgrades the request to a WebSocket on which the client follows
+// auctions and bids. Bidding needs the credentials of the handshake or of an
+// auth message, which are checked again before every bid.
+func (u *LiveBiddingController) Connect(c *gin.Context) {
+	var principal *auth.Principal
+	apiKey, authorization := c.GetHeader(apiKeyHeader), c.GetHeader("Authorization")
+	if apiKey != "" || authorization != "" {
+		var err error
+		principal, err = u.authUseCase.Authenticate(c.Request.Context(), apiKey, authorization)
+		if err != nil {
+			errRest := rest_err.ConvertError(err)
+			c.JSON(errRest.Code, errRest)
+			return
+		}
+	}
+
+	conn, err := u.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader already answered the handshake with the error.
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	connection := &liveConnection{
+		controller:    u,
+		conn:          conn,
+		ctx:           ctx,
+		cancel:        cancel,
+		outbound:      make(chan liveOutboundMessage, liveOutboundBuffer),
+		subscriptions: make(map[string]*liveSubscription),
+		mutex:         &sync.Mutex{},
+		messageBucket: newTokenBucket(liveMessageRateLimit, liveMessageRateBurst),
+		bidBucket:     newTokenBucket(u.bidRateLimit, u.bidRateBurst),
+		principal:     principal,
+		apiKey:        apiKey,
+	}
+
+	go connection.write()
+	connection.read()
+}
+
+func (lc *liveConnection) read() {
+	defer lc.close()
+
+	lc.conn.SetReadLimit(liveMaxMessageBytes)
+	_ = lc.conn.SetReadDeadline(time.Now().Add(livePongTimeout))
+	lc.conn.SetPongHandler(func(string) error {
+		return lc.conn.SetReadDeadline(time.Now().Add(livePongTimeout))
+	})
+
+	for {
+		_, data, err := lc.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var message liveInboundMessage
+		if err := json.Unmarshal(data, &message); err != nil {
+			lc.send(liveOutboundMessage{Type: liveError, Error: rest_err.NewBadRequestError("Message is not valid JSON")})
+			continue
+		}
+
+		if !lc.messageBucket.allow() {
+			lc.reject(message, rest_err.NewTooManyRequestsError("Too many messages, slow down"))
+			continue
+		}
+
+		switch message.Type {
+		case liveAuth:
+			lc.handleAuth(message)
+		case liveSubscribe:
+			lc.handleSubscribe(message)
+		case liveUnsubscribe:
+			lc.handleUnsubscribe(message)
+		case liveBid:
+			lc.handleBid(message)
+		default:
+			lc.reject(message, rest_err.NewBadRequestError(fmt.Sprintf("Unknown message type %q", message.Type)))
+		}
+	}
+}
+
+// write sends the outbound messages and keeps the connection alive with
+// pings. It closes the connection once the connection context ends.
+func (lc *liveConnection) write() {
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+	defer lc.conn.Close()
+
+	for {
+		select {
+		case <-lc.ctx.Done():
+			_ = lc.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(liveWriteTimeout))
+			return
+		case message := <-lc.outbound:
+			_ = lc.conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if err := lc.conn.WriteJSON(message); err != nil {
+				lc.cancel()
+				return
+			}
+		case <-ping.C:
+			_ = lc.conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+			if err := lc.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				lc.cancel()
+				return
+			}
+		}
+	}
+}
+
+// send queues a message for the client. A client too slow to take its
+// messages is disconnected rather than buffered without bound.
+func (lc *liveConnection) send(message liveOutboundMessage) bool {
+	select {
+	case <-lc.ctx.Done():
+		return false
+	default:
+	}
+
+	select {
+	case lc.outbound <- message:
+		return true
+	default:
+		logger.Error("Dropping live bidding connection",
+			fmt.Errorf("client of %s is not keeping up", lc.conn.RemoteAddr()))
+		lc.cancel()
+		return false
+	}
+}
+
+func (lc *liveConnection) reject(message liveInboundMessage, errRest *rest_err.RestErr) {
+	lc.send(rejection(message, errRest))
+}
+
+func rejection(message liveInboundMessage, errRest *rest_err.RestErr) liveOutboundMessage {
+	replyType := liveError
+	if message.Type == liveBid {
+		replyType = liveBidRejected
+	}
+
+	return liveOutboundMessage{
+		Type:      replyType,
+		RequestId: message.RequestId,
+		AuctionId: message.AuctionId,
+		Error:     errRest,
+	}
+}
+
+func (lc *liveConnection) close() {
+	lc.cancel()
+
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	for auctionId, subscription := range lc.subscriptions {
+		delete(lc.subscriptions, auctionId)
+		subscription.stream.Close()
+	}
+}
+
+func (lc *liveConnection) handleAuth(message liveInboundMessage) {
+	if lc.principal != nil {
+		lc.reject(message, rest_err.NewBadRequestError("Connection is already authenticated"))
+		return
+	}
+
+	authorization := ""
+	if message.Token != "" {
+		authorization = bearerPrefix + message.Token
+	}
+
+	principal, err := lc.controller.authUseCase.Authenticate(lc.ctx, message.APIKey, authorization)
+	if err != nil {
+		lc.reject(message, rest_err.ConvertError(err))
+		return
+	}
+
+	lc.principal, lc.apiKey = principal, message.APIKey
+	lc.send(liveOutboundMessage{Type: liveAuthenticated, RequestId: message.RequestId, UserId: principal.UserId})
+}
+
+func (lc *liveConnection) handleSubscribe(message liveInboundMessage) {
+	if err := uuid.Validate(message.AuctionId); err != nil {
+		lc.reject(message, rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "auction_id",
+			Message: "Invalid UUID value",
+		}))
+		return
+	}
+
+	lc.mutex.Lock()
+	_, subscribed := lc.subscriptions[message.AuctionId]
+	full := len(lc.subscriptions) >= liveMaxSubscriptions
+	lc.mutex.Unlock()
+
+	if subscribed {
+		lc.send(liveOutboundMessage{Type: liveSubscribed, RequestId: message.RequestId, AuctionId: message.AuctionId})
+		return
+	}
+	if full {
+		lc.reject(message, rest_err.NewBadRequestError(
+			fmt.Sprintf("A connection can follow at most %d auctions", liveMaxSubscriptions)))
+		return
+	}
+
+	ctx := auth.WithPrincipal(lc.ctx, lc.principal)
+	_, err := findVisibleAuction(ctx, lc.controller.policy, lc.controller.auctionUseCase, message.AuctionId)
+	if err != nil {
+		lc.reject(message, rest_err.ConvertError(err))
+		return
+	}
+
+	stream, err := lc.controller.auctionStreamUseCase.Subscribe(lc.ctx, message.AuctionId, message.LastEventId)
+	if err != nil {
+		lc.reject(message, rest_err.ConvertError(err))
+		return
+	}
+
+	subscription := &liveSubscription{auctionId: message.AuctionId, stream: stream}
+	lc.mutex.Lock()
+	lc.subscriptions[message.AuctionId] = subscription
+	lc.mutex.Unlock()
+
+	lc.send(liveOutboundMessage{Type: liveSubscribed, RequestId: message.RequestId, AuctionId: message.AuctionId})
+	for _, event := range stream.Replay {
+		lc.sendEvent(subscription, event)
+	}
+	go lc.forward(subscription)
+}
+
+func (lc *liveConnection) handleUnsubscribe(message liveInboundMessage) {
+	lc.mutex.Lock()
+	subscription, ok := lc.subscriptions[message.AuctionId]
+	if ok {
+		delete(lc.subscriptions, message.AuctionId)
+		subscription.stream.Close()
+	}
+	lc.mutex.Unlock()
+
+	lc.send(liveOutboundMessage{Type: liveUnsubscribed, RequestId: message.RequestId, AuctionId: message.AuctionId})
+}
+
+// forward relays the events of a followed auction. When the stream drops the
+// subscription for lagging behind, it resumes from the last event relayed.
+func (lc *liveConnection) forward(subscription *liveSubscription) {
+	for {
+		lc.mutex.Lock()
+		events := subscription.stream.Events
+		lc.mutex.Unlock()
+
+		for event := range events {
+			if !lc.sendEvent(subscription, event) {
+				return
+			}
+		}
+		if !lc.isCurrent(subscription) {
+			return
+		}
+
+		stream, err := lc.controller.auctionStreamUseCase.Subscribe(
+			lc.ctx, subscription.auctionId, strconv.FormatUint(subscription.lastEventId, 10))
+
+		lc.mutex.Lock()
+		current := lc.subscriptions[subscription.auctionId] == subscription
+		if current && err == nil {
+			subscription.stream = stream
+		}
+		lc.mutex.Unlock()
+
+		if err != nil {
+			if current {
+				lc.send(liveOutboundMessage{
+					Type:      liveError,
+					AuctionId: subscription.auctionId,
+					Error:     rest_err.ConvertError(err),
+				})
+			}
+			return
+		}
+		if !current {
+			stream.Close()
+			return
+		}
+
+		for _, event := range stream.Replay {
+			if !lc.sendEvent(subscription, event) {
+				return
+			}
+		}
+	}
+}
+
+// isCurrent reports whether the subscription is still wanted, i.e. neither
+// unsubscribed nor ended with the connection.
+func (lc *liveConnection) isCurrent(subscription *liveSubscription) bool {
+	lc.mutex.Lock()
+	defer lc.mutex.Unlock()
+
+	return lc.subscriptions[subscription.auctionId] == subscription
+}
+
+func (lc *liveConnection) sendEvent(subscription *liveSubscription, event usecase.AuctionStreamEventDTO) bool {
+	subscription.lastEventId = event.Id
+	return lc.send(liveOutboundMessage{
+		Type:      liveEvent,
+		AuctionId: subscription.auctionId,
+		Event:     &liveEventMessage{Id: event.Id, Type: event.Type, Data: event.Data},
+	})
+}
+
+func (lc *liveConnection) handleBid(message liveInboundMessage) {
+	if lc.principal == nil {
+		lc.reject(message, rest_err.NewUnauthorizedError("Authenticate the connection before bidding"))
+		return
+	}
+	if !lc.bidBucket.allow() {
+		lc.reject(message, rest_err.NewTooManyRequestsError("Too many bids, slow down"))
+		return
+	}
+	if err := uuid.Validate(message.AuctionId); err != nil {
+		lc.reject(message, rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
+			Field:   "auction_id",
+			Message: "Invalid UUID value",
+		}))
+		return
+	}
+	if err := lc.recheckPrincipal(); err != nil {
+		lc.reject(message, rest_err.ConvertError(err))
+		return
+	}
+
+	ctx := auth.WithPrincipal(lc.ctx, lc.principal)
+	if err := lc.controller.policy.Authorize(ctx, auth.ActionBidCreate, lc.principal.UserId); err != nil {
+		lc.reject(message, rest_err.ConvertError(err))
+		return
+	}
+
+	if message.RequestId == "" {
+		lc.send(lc.placeBid(ctx, message))
+		return
+	}
+	lc.placeBidOnce(ctx, message)
+}
+
+// recheckPrincipal makes the connection anonymous again once its credentials
+// expire or are revoked, or its user is suspended.
+func (lc *liveConnection) recheckPrincipal() error {
+	err := lc.checkPrincipal()
+	if err != nil {
+		lc.principal, lc.apiKey = nil, ""
+	}
+	return err
+}
+
+func (lc *liveConnection) checkPrincipal() error {
+	if lc.principal.Expired(time.Now()) {
+		return internal_error.NewUnauthorizedError("Token has expired, authenticate the connection again")
+	}
+
+	if lc.apiKey != "" {
+		if _, err := lc.controller.authUseCase.Authenticate(lc.ctx, lc.apiKey, ""); err != nil {
+			return err
+		}
+	}
+
+	user, err := lc.controller.userUseCase.FindUserById(lc.ctx, lc.principal.UserId)
+	if err != nil {
+		return err
+	}
+	if user.Status == usecase.UserStatus(entity.UserSuspended) {
+		return internal_error.NewUnauthorizedError("User is suspended")
+	}
+	return nil
+}
+
+// placeBidOnce places the bid unless the user already sent one with the same
+// request id, replaying that reply unless it was a server error.
+func (lc *liveConnection) placeBidOnce(ctx context.Context, message liveInboundMessage) {
+	idempotencyUseCase := lc.controller.idempotencyUseCase
+	key := lc.principal.UserId + ":" + liveBid + ":" + message.RequestId
+	request, _ := json.Marshal(usecase.BidInputDTO{AuctionId: message.AuctionId, Amount: message.Amount})
+
+	replay, err := idempotencyUseCase.Begin(ctx, key, liveBid, "", request)
+	if err != nil {
+		lc.reject(message, rest_err.ConvertError(err))
+		return
+	}
+	if replay != nil {
+		var reply liveOutboundMessage
+		if err := json.Unmarshal(replay.Body, &reply); err != nil {
+			logger.Error("Error trying to decode stored live bid reply", err)
+			lc.reject(message, rest_err.NewInternalServerError("Error trying to replay bid"))
+			return
+		}
+		lc.send(reply)
+		return
+	}
+
+	reply := lc.placeBid(ctx, message)
+	if reply.Error != nil && reply.Error.Code >= http.StatusInternalServerError {
+		if err := idempotencyUseCase.Abort(ctx, key); err != nil {
+			logger.Error("Error trying to release idempotency key", err)
+		}
+		lc.send(reply)
+		return
+	}
+
+	response := usecase.IdempotentResponseDTO{StatusCode: http.StatusAccepted, ContentType: "application/json"}
+	if reply.Error != nil {
+		response.StatusCode = reply.Error.Code
+	}
+	response.Body, _ = json.Marshal(reply)
+	if err := idempotencyUseCase.Complete(ctx, key, response); err != nil {
+		logger.Error("Error trying to store idempotent response", err)
+		_ = idempotencyUseCase.Abort(ctx, key)
+	}
+	lc.send(reply)
+}
+
+// placeBid queues the bid and returns the reply for the client.
+func (lc *liveConnection) placeBid(ctx context.Context, message liveInboundMessage) liveOutboundMessage {
+	bidData, err := lc.controller.bidUseCase.CreateBid(ctx, usecase.BidInputDTO{
+		UserId:    lc.principal.UserId,
+		AuctionId: message.AuctionId,
+		Amount:    message.Amount,
+	})
+	if err != nil {
+		return rejection(message, rest_err.ConvertError(err))
+	}
+
+	return liveOutboundMessage{
+		Type:      liveBidQueued,
+		RequestId: message.RequestId,
+		AuctionId: bidData.AuctionId,
+		Bid:       bidData,
+	}
+}
+
+func newTokenBucket(rate float64, capacity int) *tokenBucket {
+	return &tokenBucket{
+		tokens:   float64(capacity),
+		capacity: float64(capacity),
+		rate:     rate,
+		updated:  time.Now(),
+	}
+}
+
+// allow takes a token if one is left. Buckets belong to a single connection
+// and are only used by its reading goroutine, so they need no locking.
+func (tb *tokenBucket) allow() bool {
+	now := time.Now()
+	tb.tokens = min(tb.capacity, tb.tokens+now.Sub(tb.updated).Seconds()*tb.rate)
+	tb.updated = now
+
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// newOriginChecker accepts handshakes from the comma separated origins given,
+// any origin for "*", or only the same origin without a list.
+func newOriginChecker(allowedOrigins string) func(r *http.Request) bool {
+	if allowedOrigins == "" {
+		return nil
+	}
+
+	origins := strings.Split(allowedOrigins, ",")
+	for i := range origins {
+		origins[i] = strings.TrimSpace(origins[i])
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || slices.Contains(origins, "*") || slices.Contains(origins, origin)
+	}
+}
+
+func getLiveBidRateLimit() float64 {
+	value, err := strconv.ParseFloat(os.Getenv("LIVE_BID_RATE_LIMIT"), 64)
+	if err != nil || value <= 0 {
+		return liveDefaultBidRate
+	}
+	return value
+}
+
+func getLiveBidRateBurst() int {
+	value, err := strconv.Atoi(os.Getenv("LIVE_BID_RATE_BURST"))
+	if err != nil || value <= 0 {
+		return liveDefaultBidBurst
+	}
+	return value
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testBidderId   = "5d2a7c41-8e3f-4b6a-9c0d-1e2f3a4b5c6d"
+	testAPIKey     = "ak_live_test"
+	failingAmount  = 13
+	rejectedAmount = -1
+)
+
+// liveBidUseCase numbers the bids it queues. Bids of failingAmount fail with
+// a server error and bids of rejectedAmount are invalid.
+type liveBidUseCase struct {
+	usecase.BidUseCase
+
+	calls atomic.Int32
+}
+
+func (f *liveBidUseCase) CreateBid(_ context.Context, input usecase.BidInputDTO) (*usecase.BidOutputDTO, error) {
+	call := f.calls.Add(1)
+	switch input.Amount {
+	case failingAmount:
+		return nil, internal_error.NewInternalServerError("Error trying to queue bid")
+	case rejectedAmount:
+		return nil, internal_error.NewBadRequestError("Bid amount must be positive")
+	}
+
+	return &usecase.BidOutputDTO{
+		Id:        fmt.Sprintf("bid-%d", call),
+		UserId:    input.UserId,
+		AuctionId: input.AuctionId,
+		Amount:    input.Amount,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// fakeAPIKeyUseCase authenticates the keys it holds until they are revoked.
+type fakeAPIKeyUseCase struct {
+	usecase.APIKeyUseCase
+
+	keys  map[string]*usecase.APIKeyPrincipalDTO
+	mutex sync.Mutex
+}
+
+func (f *fakeAPIKeyUseCase) AuthenticateAPIKey(_ context.Context, plainKey string) (*usecase.APIKeyPrincipalDTO, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	principal, ok := f.keys[plainKey]
+	if !ok {
+		return nil, internal_error.NewUnauthorizedError("Invalid API key")
+	}
+	return principal, nil
+}
+
+func (f *fakeAPIKeyUseCase) revoke(plainKey string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.keys, plainKey)
+}
+
+type liveTestServer struct {
+	url           string
+	bidUseCase    *liveBidUseCase
+	apiKeyUseCase *fakeAPIKeyUseCase
+}
+
+func newLiveTestServer(t *testing.T, users ...usecase.UserOutputDTO) *liveTestServer {
+	t.Helper()
+
+	server := &liveTestServer{
+		bidUseCase: &liveBidUseCase{},
+		apiKeyUseCase: &fakeAPIKeyUseCase{keys: map[string]*usecase.APIKeyPrincipalDTO{
+			testAPIKey: {UserId: testBidderId, Scopes: []string{"bid"}},
+		}},
+	}
+	controller := NewLiveBiddingController(
+		server.bidUseCase,
+		&fakeAuctionUseCase{},
+		usecase.NewAuctionStreamUseCase(&fakeAuctionRepository{}),
+		usecase.NewAuthUseCase(newTestVerifier(t), server.apiKeyUseCase),
+		&fakeUserUseCase{users: users},
+		newFakeIdempotencyUseCase(),
+		newTestPolicy(t),
+	)
+
+	router := gin.New()
+	router.GET("/auction/live", controller.Connect)
+	httpServer := httptest.NewServer(router)
+	t.Cleanup(httpServer.Close)
+
+	server.url = "ws" + strings.TrimPrefix(httpServer.URL, "http") + "/auction/live"
+	return server
+}
+
+func (s *liveTestServer) dial(t *testing.T, header http.Header) *websocket.Conn {
+	t.Helper()
+
+	conn, _, err := websocket.DefaultDialer.Dial(s.url, header)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// exchange sends message and returns the reply to it.
+func exchange(t *testing.T, conn *websocket.Conn, message liveInboundMessage) liveOutboundMessage {
+	t.Helper()
+
+	require.NoError(t, conn.WriteJSON(message))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var reply liveOutboundMessage
+	require.NoError(t, conn.ReadJSON(&reply))
+	return reply
+}
+
+func bidMessage(requestId string, amount float64) liveInboundMessage {
+	return liveInboundMessage{Type: liveBid, RequestId: requestId, AuctionId: testSellerId, Amount: amount}
+}
+
+func bearer(t *testing.T, userId string, expiresIn time.Duration) http.Header {
+	return http.Header{"Authorization": {bearerPrefix + signTestToken(t, userId, expiresIn, auth.RoleBidder)}}
+}
+
+func TestLiveBiddingBid(t *testing.T) {
+	users := []usecase.UserOutputDTO{
+		{Id: testBidderId, Status: usecase.UserStatus(entity.UserActive)},
+		{Id: testOtherId, Status: usecase.UserStatus(entity.UserActive)},
+	}
+
+	type step struct {
+		userId    string
+		requestId string
+		amount    float64
+		wantType  string
+		wantBid   string
+		wantCode  int
+	}
+
+	tests := []struct {
+		name      string
+		steps     []step
+		wantCalls int32
+	}{
+		{name: "queued", wantCalls: 1, steps: []step{
+			{userId: testBidderId, requestId: "1", amount: 10, wantType: liveBidQueued, wantBid: "bid-1"},
+		}},
+		{name: "retried request", wantCalls: 1, steps: []step{
+			{userId: testBidderId, requestId: "1", amount: 10, wantType: liveBidQueued, wantBid: "bid-1"},
+			{userId: testBidderId, requestId: "1", amount: 10, wantType: liveBidQueued, wantBid: "bid-1"},
+		}},
+		{name: "without request id", wantCalls: 2, steps: []step{
+			{userId: testBidderId, amount: 10, wantType: liveBidQueued, wantBid: "bid-1"},
+			{userId: testBidderId, amount: 10, wantType: liveBidQueued, wantBid: "bid-2"},
+		}},
+		{name: "request id of another user", wantCalls: 2, steps: []step{
+			{userId: testBidderId, requestId: "1", amount: 10, wantType: liveBidQueued, wantBid: "bid-1"},
+			{userId: testOtherId, requestId: "1", amount: 10, wantType: liveBidQueued, wantBid: "bid-2"},
+		}},
+		{name: "rejected bid retried", wantCalls: 1, steps: []step{
+			{userId: testBidderId, requestId: "1", amount: rejectedAmount, wantType: liveBidRejected,
+				wantCode: http.StatusBadRequest},
+			{userId: testBidderId, requestId: "1", amount: rejectedAmount, wantType: liveBidRejected,
+				wantCode: http.StatusBadRequest},
+		}},
+		{name: "failed bid retried", wantCalls: 2, steps: []step{
+			{userId: testBidderId, requestId: "1", amount: failingAmount, wantType: liveBidRejected,
+				wantCode: http.StatusInternalServerError},
+			{userId: testBidderId, requestId: "1", amount: failingAmount, wantType: liveBidRejected,
+				wantCode: http.StatusInternalServerError},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newLiveTestServer(t, users...)
+			connections := make(map[string]*websocket.Conn)
+
+			for _, step := range tt.steps {
+				conn, ok := connections[step.userId]
+				if !ok {
+					conn = server.dial(t, bearer(t, step.userId, time.Hour))
+					connections[step.userId] = conn
+				}
+
+				reply := exchange(t, conn, bidMessage(step.requestId, step.amount))
+
+				require.Equal(t, step.wantType, reply.Type)
+				require.Equal(t, step.requestId, reply.RequestId)
+				if step.wantBid != "" {
+					require.Equal(t, step.wantBid, reply.Bid.Id)
+					require.Equal(t, step.userId, reply.Bid.UserId)
+				}
+				if step.wantCode != 0 {
+					require.Equal(t, step.wantCode, reply.Error.Code)
+				}
+			}
+			require.Equal(t, tt.wantCalls, server.bidUseCase.calls.Load())
+		})
+	}
+}
+
+func TestLiveBiddingRechecksPrincipal(t *testing.T) {
+	active := usecase.UserOutputDTO{Id: testBidderId, Status: usecase.UserStatus(entity.UserActive)}
+	suspended := usecase.UserOutputDTO{Id: testBidderId, Status: usecase.UserStatus(entity.UserSuspended)}
+
+	tests := []struct {
+		name      string
+		user      usecase.UserOutputDTO
+		header    func(t *testing.T) http.Header
+		change    func(server *liveTestServer)
+		wantCode  int
+		reconnect bool
+	}{
+		{name: "valid token", user: active,
+			header: func(t *testing.T) http.Header { return bearer(t, testBidderId, time.Hour) }},
+		{name: "valid API key", user: active,
+			header: func(*testing.T) http.Header { return http.Header{apiKeyHeader: {testAPIKey}} }},
+		{name: "token expired", user: active, wantCode: http.StatusUnauthorized, reconnect: true,
+			header: func(t *testing.T) http.Header { return bearer(t, testBidderId, 2*time.Second) },
+			change: func(*liveTestServer) { time.Sleep(2 * time.Second) }},
+		{name: "API key revoked", user: active, wantCode: http.StatusUnauthorized,
+			header: func(*testing.T) http.Header { return http.Header{apiKeyHeader: {testAPIKey}} },
+			change: func(server *liveTestServer) { server.apiKeyUseCase.revoke(testAPIKey) }},
+		{name: "user suspended", user: suspended, wantCode: http.StatusUnauthorized,
+			header: func(t *testing.T) http.Header { return bearer(t, testBidderId, time.Hour) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newLiveTestServer(t, tt.user)
+			conn := server.dial(t, tt.header(t))
+			if tt.change != nil {
+				tt.change(server)
+			}
+
+			reply := exchange(t, conn, bidMessage("1", 10))
+
+			if tt.wantCode == 0 {
+				require.Equal(t, liveBidQueued, reply.Type)
+				return
+			}
+			require.Equal(t, liveBidRejected, reply.Type)
+			require.Equal(t, tt.wantCode, reply.Error.Code)
+
+			reply = exchange(t, conn, bidMessage("2", 10))
+			require.Equal(t, liveBidRejected, reply.Type)
+			require.Equal(t, "Authenticate the connection before bidding", reply.Error.Message)
+
+			if tt.reconnect {
+				token := signTestToken(t, testBidderId, time.Hour, auth.RoleBidder)
+				reply = exchange(t, conn, liveInboundMessage{Type: liveAuth, RequestId: "3", Token: token})
+				require.Equal(t, liveAuthenticated, reply.Type)
+
+				reply = exchange(t, conn, bidMessage("4", 10))
+				require.Equal(t, liveBidQueued, reply.Type)
+			}
+		})
+	}
+}
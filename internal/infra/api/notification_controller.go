@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type NotificationController struct {
+	notificationUseCase usecase.NotificationUseCase
+	policy              *auth.Policy
+}
+
+func NewNotificationController(
+	notificationUseCase usecase.NotificationUseCase,
+	policy *auth.Policy,
+) *NotificationController {
+	return &NotificationController{
+		notificationUseCase: notificationUseCase,
+		policy:              policy,
+	}
+}
+
+func (u *NotificationController) FindPreferences(c *gin.Context) {
+	userId, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionUserUpdate, userId) {
+		return
+	}
+
+	preferences, err := u.notificationUseCase.FindPreferences(c.Request.Context(), userId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+func (u *NotificationController) UpdatePreferences(c *gin.Context) {
+	userId, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var preferencesDTO usecase.NotificationPreferencesDTO
+	if err := c.ShouldBindJSON(&preferencesDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionUserUpdate, userId) {
+		return
+	}
+
+	preferences, err := u.notificationUseCase.UpdatePreferences(c.Request.Context(), userId, preferencesDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type QuestionController struct {
+	questionUseCase usecase.QuestionUseCase
+	auctionUseCase  usecase.AuctionUseCase
+	policy          *auth.Policy
+}
+
+func NewQuestionController(
+	questionUseCase usecase.QuestionUseCase,
+	auctionUseCase usecase.AuctionUseCase,
+	policy *auth.Policy,
+) *QuestionController {
+	return &QuestionController{
+		questionUseCase: questionUseCase,
+		auctionUseCase:  auctionUseCase,
+		policy:          policy,
+	}
+}
+
+func (u *QuestionController) AskQuestion(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var questionInputDTO usecase.QuestionInputDTO
+	if err := c.ShouldBindJSON(&questionInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionQuestionAsk, "") {
+		return
+	}
+	askerId, _ := auth.SubjectFromContext(c.Request.Context())
+
+	questionData, err := u.questionUseCase.AskQuestion(c.Request.Context(), auctionId, askerId, questionInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Header("Location", "/auction/"+auctionId+"/questions/"+questionData.Id)
+	c.JSON(http.StatusCreated, questionData)
+}
+
+func (u *QuestionController) FindQuestions(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+
+	var questionListInputDTO usecase.QuestionListInputDTO
+	if err := c.ShouldBindQuery(&questionListInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+	if !authorizeAuctionRead(c, u.policy, u.auctionUseCase, auctionId) {
+		return
+	}
+
+	questions, err := u.questionUseCase.FindQuestions(c.Request.Context(), auctionId, questionListInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+func (u *QuestionController) AnswerQuestion(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+	questionId, ok := uuidParam(c, "questionId")
+	if !ok {
+		return
+	}
+
+	var answerInputDTO usecase.AnswerInputDTO
+	if err := c.ShouldBindJSON(&answerInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorizeOnAuction(c, u.policy, u.auctionUseCase, auth.ActionQuestionAnswer, auctionId) {
+		return
+	}
+
+	questionData, err := u.questionUseCase.AnswerQuestion(c.Request.Context(), auctionId, questionId, answerInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionData)
+}
+
+func (u *QuestionController) FlagQuestion(c *gin.Context) {
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return
+	}
+	questionId, ok := uuidParam(c, "questionId")
+	if !ok {
+		return
+	}
+
+	var flagInputDTO usecase.QuestionFlagInputDTO
+	if err := c.ShouldBindJSON(&flagInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionQuestionFlag, "") {
+		return
+	}
+	userId, _ := auth.SubjectFromContext(c.Request.Context())
+
+	questionData, err := u.questionUseCase.FlagQuestion(
+		c.Request.Context(), auctionId, questionId, userId, flagInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionData)
+}
+
+func (u *QuestionController) FindFlaggedQuestions(c *gin.Context) {
+	var questionListInputDTO usecase.QuestionListInputDTO
+	if err := c.ShouldBindQuery(&questionListInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionQuestionModerate, "") {
+		return
+	}
+
+	questions, err := u.questionUseCase.FindFlaggedQuestions(c.Request.Context(), questionListInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, questions)
+}
+
+func (u *QuestionController) HideQuestion(c *gin.Context) {
+	questionId, ok := uuidParam(c, "questionId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionQuestionModerate, "") {
+		return
+	}
+
+	questionData, err := u.questionUseCase.HideQuestion(c.Request.Context(), questionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionData)
+}
+
+func (u *QuestionController) RestoreQuestion(c *gin.Context) {
+	questionId, ok := uuidParam(c, "questionId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionQuestionModerate, "") {
+		return
+	}
+
+	questionData, err := u.questionUseCase.RestoreQuestion(c.Request.Context(), questionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, questionData)
+}
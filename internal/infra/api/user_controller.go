@@ -1,37 +1,89 @@
 package api
 
 import (
+	"fullcycle-auction_go/configuration/auth"
 	"fullcycle-auction_go/configuration/rest_err"
 	"fullcycle-auction_go/internal/usecase"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 	"net/http"
 )
 
 type UserController struct {
 	userUseCase usecase.UserUseCase
+	policy      *auth.Policy
 }
 
-func NewUserController(userUseCase usecase.UserUseCase) *UserController {
+func NewUserController(userUseCase usecase.UserUseCase, policy *auth.Policy) *UserController {
 	return &UserController{
 		userUseCase: userUseCase,
+		policy:      policy,
 	}
 }
 
 func (u *UserController) FindUserById(c *gin.Context) {
-	userId := c.Param("userId")
-
-	if err := uuid.Validate(userId); err != nil {
-		errRest := rest_err.NewBadRequestError("Invalid fields", rest_err.Causes{
-			Field:   "userId",
-			Message: "Invalid UUID value",
-		})
+	userId, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
 
+	userData, err := u.userUseCase.FindUserById(c.Request.Context(), userId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
 		return
 	}
 
-	userData, err := u.userUseCase.FindUserById(c.Request.Context(), userId)
+	if u.policy.Authorize(c.Request.Context(), auth.ActionUserReadPrivate, userId) != nil {
+		c.JSON(http.StatusOK, userData.Public())
+		return
+	}
+	c.JSON(http.StatusOK, userData)
+}
+
+func (u *UserController) CreateUser(c *gin.Context) {
+	var userInputDTO usecase.UserInputDTO
+
+	if err := c.ShouldBindJSON(&userInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	userData, err := u.userUseCase.CreateUser(c.Request.Context(), userInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.Header("Location", "/user/"+userData.Id)
+	c.JSON(http.StatusCreated, userData)
+}
+
+func (u *UserController) UpdateUser(c *gin.Context) {
+	userId, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var userUpdateInputDTO usecase.UserUpdateInputDTO
+	if err := c.ShouldBindJSON(&userUpdateInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionUserUpdate, userId) {
+		return
+	}
+	if userUpdateInputDTO.Status != nil && !authorize(c, u.policy, auth.ActionUserModerate, userId) {
+		return
+	}
+
+	userData, err := u.userUseCase.UpdateUser(c.Request.Context(), userId, userUpdateInputDTO)
 	if err != nil {
 		errRest := rest_err.ConvertError(err)
 		c.JSON(errRest.Code, errRest)
@@ -40,3 +92,30 @@ func (u *UserController) FindUserById(c *gin.Context) {
 
 	c.JSON(http.StatusOK, userData)
 }
+
+func (u *UserController) FindUsers(c *gin.Context) {
+	var userListInputDTO usecase.UserListInputDTO
+
+	if err := c.ShouldBindQuery(&userListInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	users, err := u.userUseCase.FindUsers(c.Request.Context(), userListInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	// Only admins see the emails of everyone listed.
+	if u.policy.Authorize(c.Request.Context(), auth.ActionUserReadPrivate, "") != nil {
+		for i := range users.Users {
+			users.Users[i] = users.Users[i].Public()
+		}
+	}
+
+	c.JSON(http.StatusOK, users)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+)
+
+// fakeUserUseCase serves a fixed set of users.
+type fakeUserUseCase struct {
+	usecase.UserUseCase
+
+	users []usecase.UserOutputDTO
+}
+
+func (f *fakeUserUseCase) FindUserById(_ context.Context, id string) (*usecase.UserOutputDTO, error) {
+	for _, user := range f.users {
+		if user.Id == id {
+			return &user, nil
+		}
+	}
+	return nil, internal_error.NewNotFoundError("User not found")
+}
+
+func (f *fakeUserUseCase) FindUsers(_ context.Context, _ usecase.UserListInputDTO) (*usecase.UserListOutputDTO, error) {
+	users := append([]usecase.UserOutputDTO(nil), f.users...)
+	return &usecase.UserListOutputDTO{Users: users, Page: 1, PageSize: 20, Total: int64(len(users))}, nil
+}
+
+func TestUserControllerHidesEmails(t *testing.T) {
+	users := []usecase.UserOutputDTO{
+		{Id: testSellerId, Name: "Seller", Email: "seller@example.com"},
+		{Id: testOtherId, Name: "Other", Email: "other@example.com"},
+	}
+	self := &auth.Principal{UserId: testSellerId, Roles: []string{auth.RoleBidder}}
+	admin := &auth.Principal{UserId: testOtherId, Roles: []string{auth.RoleAdmin}}
+
+	tests := []struct {
+		name       string
+		target     string
+		principal  *auth.Principal
+		wantEmails []string
+	}{
+		{name: "listing anonymously", target: "/user", wantEmails: []string{"", ""}},
+		{name: "listing as a user", target: "/user", principal: self, wantEmails: []string{"", ""}},
+		{name: "listing as admin", target: "/user", principal: admin,
+			wantEmails: []string{"seller@example.com", "other@example.com"}},
+		{name: "profile anonymously", target: "/user/" + testSellerId, wantEmails: []string{""}},
+		{name: "own profile", target: "/user/" + testSellerId, principal: self,
+			wantEmails: []string{"seller@example.com"}},
+		{name: "profile of another user", target: "/user/" + testOtherId, principal: self, wantEmails: []string{""}},
+		{name: "profile as admin", target: "/user/" + testSellerId, principal: admin,
+			wantEmails: []string{"seller@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := NewUserController(&fakeUserUseCase{users: users}, newTestPolicy(t))
+			router := gin.New()
+			router.Use(withPrincipal(tt.principal))
+			router.GET("/user", controller.FindUsers)
+			router.GET("/user/:userId", controller.FindUserById)
+
+			rec := serve(router, http.MethodGet, tt.target)
+			requireStatus(t, http.StatusOK, rec)
+
+			var emails []string
+			if tt.target == "/user" {
+				var list usecase.UserListOutputDTO
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+				for _, user := range list.Users {
+					emails = append(emails, user.Email)
+				}
+			} else {
+				var user usecase.UserOutputDTO
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+				emails = append(emails, user.Email)
+			}
+			require.Equal(t, tt.wantEmails, emails)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type WatchlistController struct {
+	watchlistUseCase usecase.WatchlistUseCase
+	policy           *auth.Policy
+}
+
+func NewWatchlistController(watchlistUseCase usecase.WatchlistUseCase, policy *auth.Policy) *WatchlistController {
+	return &WatchlistController{
+		watchlistUseCase: watchlistUseCase,
+		policy:           policy,
+	}
+}
+
+func (u *WatchlistController) WatchAuction(c *gin.Context) {
+	userId, auctionId, ok := u.watchlistParams(c)
+	if !ok {
+		return
+	}
+
+	watchedData, err := u.watchlistUseCase.WatchAuction(c.Request.Context(), userId, auctionId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchedData)
+}
+
+func (u *WatchlistController) UnwatchAuction(c *gin.Context) {
+	userId, auctionId, ok := u.watchlistParams(c)
+	if !ok {
+		return
+	}
+
+	if err := u.watchlistUseCase.UnwatchAuction(c.Request.Context(), userId, auctionId); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *WatchlistController) FindWatchlist(c *gin.Context) {
+	userId, ok := uuidParam(c, "userId")
+	if !ok {
+		return
+	}
+
+	var watchlistInputDTO usecase.WatchlistInputDTO
+	if err := c.ShouldBindQuery(&watchlistInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionWatchlistManage, userId) {
+		return
+	}
+
+	watchlist, err := u.watchlistUseCase.FindWatchlist(c.Request.Context(), userId, watchlistInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// watchlistParams reads the path of a watchlist entry and checks the caller
+// may manage that user's watchlist.
+func (u *WatchlistController) watchlistParams(c *gin.Context) (string, string, bool) {
+	userId, ok := uuidParam(c, "userId")
+	if !ok {
+		return "", "", false
+	}
+	auctionId, ok := uuidParam(c, "auctionId")
+	if !ok {
+		return "", "", false
+	}
+
+	if !authorize(c, u.policy, auth.ActionWatchlistManage, userId) {
+		return "", "", false
+	}
+	return userId, auctionId, true
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/rest_err"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type WebhookController struct {
+	webhookUseCase usecase.WebhookUseCase
+	policy         *auth.Policy
+}
+
+func NewWebhookController(webhookUseCase usecase.WebhookUseCase, policy *auth.Policy) *WebhookController {
+	return &WebhookController{
+		webhookUseCase: webhookUseCase,
+		policy:         policy,
+	}
+}
+
+func (u *WebhookController) CreateWebhook(c *gin.Context) {
+	var webhookInputDTO usecase.WebhookInputDTO
+
+	if err := c.ShouldBindJSON(&webhookInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionWebhookManage, "") {
+		return
+	}
+
+	ownerId, _ := auth.SubjectFromContext(c.Request.Context())
+	webhookData, err := u.webhookUseCase.CreateWebhook(c.Request.Context(), ownerId, webhookInputDTO)
+	if err != nil {
+		restErr := rest_err.ConvertError(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhookData)
+}
+
+func (u *WebhookController) FindWebhooks(c *gin.Context) {
+	if !authorize(c, u.policy, auth.ActionWebhookManage, "") {
+		return
+	}
+
+	webhooks, err := u.webhookUseCase.FindWebhooks(c.Request.Context())
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (u *WebhookController) FindWebhookById(c *gin.Context) {
+	webhookId, ok := uuidParam(c, "webhookId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionWebhookManage, "") {
+		return
+	}
+
+	webhookData, err := u.webhookUseCase.FindWebhookById(c.Request.Context(), webhookId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhookData)
+}
+
+func (u *WebhookController) DeleteWebhook(c *gin.Context) {
+	webhookId, ok := uuidParam(c, "webhookId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionWebhookManage, "") {
+		return
+	}
+
+	if err := u.webhookUseCase.DeleteWebhook(c.Request.Context(), webhookId); err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (u *WebhookController) FindDeliveries(c *gin.Context) {
+	webhookId, ok := uuidParam(c, "webhookId")
+	if !ok {
+		return
+	}
+
+	var deliveryListInputDTO usecase.DeliveryListInputDTO
+	if err := c.ShouldBindQuery(&deliveryListInputDTO); err != nil {
+		restErr := ValidateErr(err)
+
+		c.JSON(restErr.Code, restErr)
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionWebhookManage, "") {
+		return
+	}
+
+	deliveries, err := u.webhookUseCase.FindDeliveries(c.Request.Context(), webhookId, deliveryListInputDTO)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (u *WebhookController) Redeliver(c *gin.Context) {
+	webhookId, ok := uuidParam(c, "webhookId")
+	if !ok {
+		return
+	}
+	deliveryId, ok := uuidParam(c, "deliveryId")
+	if !ok {
+		return
+	}
+
+	if !authorize(c, u.policy, auth.ActionWebhookManage, "") {
+		return
+	}
+
+	delivery, err := u.webhookUseCase.Redeliver(c.Request.Context(), webhookId, deliveryId)
+	if err != nil {
+		errRest := rest_err.ConvertError(err)
+		c.JSON(errRest.Code, errRest)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
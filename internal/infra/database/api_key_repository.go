@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type (
+	APIKeyMongo struct {
+		Id            string               `bson:"_id"`
+		Name          string               `bson:"name"`
+		UserId        string               `bson:"user_id"`
+		Prefix        string               `bson:"prefix"`
+		Hash          string               `bson:"key_hash"`
+		Scopes        []entity.APIKeyScope `bson:"scopes"`
+		RotatedFromId string               `bson:"rotated_from_id,omitempty"`
+		CreatedAt     int64                `bson:"created_at"`
+		RevokedAt     int64                `bson:"revoked_at,omitempty"`
+	}
+
+	APIKeyRepository struct {
+		Collection *mongo.Collection
+	}
+)
+
+func NewAPIKeyRepository(database *mongo.Database) *APIKeyRepository {
+	repository := &APIKeyRepository{
+		Collection: database.Collection("api_keys"),
+	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+func (kr *APIKeyRepository) createIndexes(ctx context.Context) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "key_hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	if _, err := kr.Collection.Indexes().CreateOne(ctx, index); err != nil {
+		logger.Error("Error trying to create api keys hash index", err)
+	}
+}
+
+func (kr *APIKeyRepository) CreateAPIKey(ctx context.Context, apiKey *entity.APIKey) error {
+	if _, err := kr.Collection.InsertOne(ctx, newAPIKeyMongo(apiKey)); err != nil {
+		logger.Error("Error trying to insert api key", err)
+		return internal_error.NewInternalServerError("Error trying to insert api key")
+	}
+
+	return nil
+}
+
+func (kr *APIKeyRepository) FindAPIKeyById(ctx context.Context, id string) (*entity.APIKey, error) {
+	return kr.findAPIKey(ctx, bson.M{"_id": id})
+}
+
+func (kr *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*entity.APIKey, error) {
+	return kr.findAPIKey(ctx, bson.M{"key_hash": hash})
+}
+
+func (kr *APIKeyRepository) findAPIKey(ctx context.Context, filter bson.M) (*entity.APIKey, error) {
+	var apiKeyMongo APIKeyMongo
+	if err := kr.Collection.FindOne(ctx, filter).Decode(&apiKeyMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("API key not found")
+		}
+
+		logger.Error("Error trying to find api key", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find api key")
+	}
+
+	return apiKeyMongo.toEntity(), nil
+}
+
+func (kr *APIKeyRepository) FindAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := kr.Collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Error("Error finding api keys", err)
+		return nil, internal_error.NewInternalServerError("Error finding api keys")
+	}
+	defer cursor.Close(ctx)
+
+	var apiKeysMongo []APIKeyMongo
+	if err := cursor.All(ctx, &apiKeysMongo); err != nil {
+		logger.Error("Error decoding api keys", err)
+		return nil, internal_error.NewInternalServerError("Error decoding api keys")
+	}
+
+	var apiKeys []entity.APIKey
+	for _, apiKeyMongo := range apiKeysMongo {
+		apiKeys = append(apiKeys, *apiKeyMongo.toEntity())
+	}
+
+	return apiKeys, nil
+}
+
+func (kr *APIKeyRepository) RevokeAPIKey(ctx context.Context, apiKey *entity.APIKey) error {
+	filter := bson.M{"_id": apiKey.Id, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": apiKey.RevokedAt.Unix()}}
+
+	result, err := kr.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to revoke api key %s", apiKey.Id), err)
+		return internal_error.NewInternalServerError("Error trying to revoke api key")
+	}
+	if result.MatchedCount == 0 {
+		return internal_error.NewConflictError("API key was already revoked")
+	}
+
+	return nil
+}
+
+// RotateAPIKey revokes apiKey and stores its replacement, restoring apiKey when
+// the replacement cannot be stored.
+func (kr *APIKeyRepository) RotateAPIKey(ctx context.Context, apiKey, rotated *entity.APIKey) error {
+	if err := kr.RevokeAPIKey(ctx, apiKey); err != nil {
+		return err
+	}
+
+	if err := kr.CreateAPIKey(ctx, rotated); err != nil {
+		filter := bson.M{"_id": apiKey.Id, "revoked_at": apiKey.RevokedAt.Unix()}
+		update := bson.M{"$unset": bson.M{"revoked_at": ""}}
+		if _, restoreErr := kr.Collection.UpdateOne(ctx, filter, update); restoreErr != nil {
+			logger.Error(fmt.Sprintf("Error trying to restore api key %s", apiKey.Id), restoreErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func newAPIKeyMongo(apiKey *entity.APIKey) *APIKeyMongo {
+	apiKeyMongo := &APIKeyMongo{
+		Id:            apiKey.Id,
+		Name:          apiKey.Name,
+		UserId:        apiKey.UserId,
+		Prefix:        apiKey.Prefix,
+		Hash:          apiKey.Hash,
+		Scopes:        apiKey.Scopes,
+		RotatedFromId: apiKey.RotatedFromId,
+		CreatedAt:     apiKey.CreatedAt.Unix(),
+	}
+	if apiKey.IsRevoked() {
+		apiKeyMongo.RevokedAt = apiKey.RevokedAt.Unix()
+	}
+	return apiKeyMongo
+}
+
+func (km *APIKeyMongo) toEntity() *entity.APIKey {
+	apiKey := &entity.APIKey{
+		Id:            km.Id,
+		Name:          km.Name,
+		UserId:        km.UserId,
+		Prefix:        km.Prefix,
+		Hash:          km.Hash,
+		Scopes:        km.Scopes,
+		RotatedFromId: km.RotatedFromId,
+		CreatedAt:     time.Unix(km.CreatedAt, 0),
+	}
+	if km.RevokedAt != 0 {
+		apiKey.RevokedAt = time.Unix(km.RevokedAt, 0)
+	}
+	return apiKey
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAPIKeyRepositoryRotateAPIKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		storeFails  bool
+		wantErr     bool
+		wantRevoked bool
+	}{
+		{name: "rotated", wantRevoked: true},
+		{name: "replacement not stored", storeFails: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeyRepository := NewAPIKeyRepository(newTestDatabase(t))
+			ctx := context.Background()
+
+			apiKey, _, err := entity.CreateAPIKey("partner", uuid.NewString(), []entity.APIKeyScope{entity.APIKeyScopeRead})
+			require.NoError(t, err)
+			require.NoError(t, apiKeyRepository.CreateAPIKey(ctx, apiKey))
+			rotated, _, err := apiKey.Rotate()
+			require.NoError(t, err)
+			if tt.storeFails {
+				require.NoError(t, apiKeyRepository.CreateAPIKey(ctx, &entity.APIKey{Id: rotated.Id}))
+			}
+
+			err = apiKeyRepository.RotateAPIKey(ctx, apiKey, rotated)
+
+			stored, findErr := apiKeyRepository.FindAPIKeyById(ctx, apiKey.Id)
+			require.NoError(t, findErr)
+			require.Equal(t, tt.wantRevoked, stored.IsRevoked())
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			stored, err = apiKeyRepository.FindAPIKeyByHash(ctx, rotated.Hash)
+			require.NoError(t, err)
+			require.Equal(t, apiKey.Id, stored.RotatedFromId)
+		})
+	}
+}
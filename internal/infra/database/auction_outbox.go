@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"slices"
+	"time"
+)
+
+// OutboxEventMongo is an auction event waiting in the outbox of its auction,
+// written along with the change it records. auction.completed keeps a
+// snapshot of the auction, its winning bid and end time.
+type OutboxEventMongo struct {
+	Id          string                  `bson:"id"`
+	Type        entity.AuctionEventType `bson:"type"`
+	Auction     *AuctionMongo           `bson:"auction,omitempty"`
+	Bid         *BidMongo               `bson:"bid,omitempty"`
+	Leading     bool                    `bson:"leading,omitempty"`
+	PreviousBid *BidMongo               `bson:"previous_bid,omitempty"`
+	EndsAt      int64                   `bson:"ends_at,omitempty"`
+	OccurredAt  int64                   `bson:"occurred_at"`
+}
+
+func newOutboxEventMongo(event *entity.AuctionEvent) OutboxEventMongo {
+	outboxEvent := OutboxEventMongo{
+		Id:         event.Id,
+		Type:       event.Type,
+		Leading:    event.Leading,
+		OccurredAt: event.OccurredAt.Unix(),
+	}
+	if event.Type == entity.EventAuctionCompleted && event.Auction != nil {
+		outboxEvent.Auction = newAuctionMongo(event.Auction)
+		outboxEvent.EndsAt = event.EndsAt.Unix()
+	}
+	if event.Bid != nil {
+		outboxEvent.Bid = newBidMongo(event.Bid)
+	}
+	if event.PreviousBid != nil {
+		outboxEvent.PreviousBid = newBidMongo(event.PreviousBid)
+	}
+	return outboxEvent
+}
+
+// FindOutboxEvents reads the events back, oldest first, with the auction as
+// it is now unless the event holds a snapshot of it.
+func (ar *AuctionRepository) FindOutboxEvents(ctx context.Context, limit int) ([]entity.AuctionEvent, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "outbox.occurred_at", Value: 1}}).SetLimit(int64(limit))
+	cursor, err := ar.Collection.Find(ctx, bson.M{"outbox.id": bson.M{"$exists": true}}, opts)
+	if err != nil {
+		logger.Error("Error trying to find auction outbox events", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction outbox events")
+	}
+	defer cursor.Close(ctx)
+
+	var auctionsMongo []AuctionMongo
+	if err := cursor.All(ctx, &auctionsMongo); err != nil {
+		logger.Error("Error trying to decode auction outbox events", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find auction outbox events")
+	}
+
+	var events []entity.AuctionEvent
+	for i := range auctionsMongo {
+		auctionMongo := &auctionsMongo[i]
+		auction := auctionMongo.toEntity()
+
+		for _, outboxEvent := range auctionMongo.Outbox {
+			event := entity.AuctionEvent{
+				Id:          outboxEvent.Id,
+				Type:        outboxEvent.Type,
+				AuctionId:   auction.Id,
+				Auction:     auction,
+				Bid:         outboxEvent.Bid.toEntity(),
+				Leading:     outboxEvent.Leading,
+				PreviousBid: outboxEvent.PreviousBid.toEntity(),
+				OccurredAt:  time.Unix(outboxEvent.OccurredAt, 0),
+			}
+			switch {
+			case outboxEvent.Auction != nil:
+				event.Auction = outboxEvent.Auction.toEntity()
+				event.EndsAt = time.Unix(outboxEvent.EndsAt, 0)
+			case event.Type == entity.EventAuctionCompleted:
+				// Recorded before completions were snapshotted.
+				event.EndsAt = auction.EndsAt(ar.auctionDuration)
+				if event.Bid, err = ar.findHighestBid(ctx, auction.Id); err != nil {
+					logger.Error(fmt.Sprintf("Error trying to find highest bid of auction %s", auction.Id), err)
+					return nil, internal_error.NewInternalServerError("Error trying to find auction outbox events")
+				}
+			}
+			events = append(events, event)
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b entity.AuctionEvent) int {
+		return a.OccurredAt.Compare(b.OccurredAt)
+	})
+	return events, nil
+}
+
+// AcknowledgeOutboxEvents removes relayed events from the outbox of their
+// auctions, pulling entries by value as some compatible servers require.
+func (ar *AuctionRepository) AcknowledgeOutboxEvents(ctx context.Context, events []entity.AuctionEvent) error {
+	eventIds := make(map[string][]string)
+	for _, event := range events {
+		eventIds[event.AuctionId] = append(eventIds[event.AuctionId], event.Id)
+	}
+
+	for auctionId, ids := range eventIds {
+		if err := ar.acknowledgeOutboxEvents(ctx, auctionId, ids); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to acknowledge outbox events of auction %s", auctionId), err)
+			return internal_error.NewInternalServerError("Error trying to acknowledge auction outbox events")
+		}
+	}
+
+	return nil
+}
+
+func (ar *AuctionRepository) acknowledgeOutboxEvents(ctx context.Context, auctionId string, ids []string) error {
+	var stored struct {
+		Outbox []bson.Raw `bson:"outbox"`
+	}
+	opts := options.FindOne().SetProjection(bson.M{"outbox": 1})
+	err := ar.Collection.FindOne(ctx, bson.M{"_id": auctionId}, opts).Decode(&stored)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var relayed bson.A
+	for _, entry := range stored.Outbox {
+		if id, ok := entry.Lookup("id").StringValueOK(); ok && slices.Contains(ids, id) {
+			relayed = append(relayed, entry)
+		}
+	}
+	if len(relayed) == 0 {
+		return nil
+	}
+
+	_, err = ar.Collection.UpdateOne(ctx, bson.M{"_id": auctionId}, bson.M{"$pullAll": bson.M{"outbox": relayed}})
+	return err
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"testing"
+)
+
+func TestAuctionOutbox(t *testing.T) {
+	bidRepository, publisher := newTestBidRepository(t)
+	auctionRepository := bidRepository.AuctionRepository
+	ctx := context.Background()
+
+	auction, err := entity.CreateAuction(testBidderId("seller"), "Camera", "cameras", "A camera in good shape",
+		entity.Used, nil, 0, entity.RelistRule{})
+	require.NoError(t, err)
+	require.NoError(t, auction.Submit())
+	require.NoError(t, auctionRepository.CreateAuction(ctx, auction))
+
+	events, err := auctionRepository.FindOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, events, "auction.created waits for the approval")
+
+	require.NoError(t, auction.Approve())
+	require.NoError(t, auctionRepository.UpdateAuctionStatus(ctx, auction, entity.PendingApproval))
+
+	placeBid := func(bidder string, amount float64) *entity.Bid {
+		bid, err := entity.CreateBid(testBidderId(bidder), auction.Id, amount)
+		require.NoError(t, err)
+		require.NoError(t, bidRepository.CreateBid(ctx, []entity.Bid{*bid}))
+		return bid
+	}
+	first := placeBid("a", 10)
+	second := placeBid("b", 20)
+	overtaken := placeBid("c", 15)
+
+	auction.Status = entity.Completed
+	require.NoError(t, auctionRepository.UpdateAuctionStatus(ctx, auction, entity.Active))
+	require.NoError(t, auction.Reopen())
+	require.NoError(t, auctionRepository.UpdateAuctionStatus(ctx, auction, entity.Completed))
+
+	events, err = auctionRepository.FindOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+
+	tests := []struct {
+		eventType       entity.AuctionEventType
+		wantBid         string
+		wantLeading     bool
+		wantPreviousBid string
+	}{
+		{eventType: entity.EventAuctionCreated},
+		{eventType: entity.EventBidAccepted, wantBid: first.Id, wantLeading: true},
+		{eventType: entity.EventBidAccepted, wantBid: second.Id, wantLeading: true, wantPreviousBid: first.Id},
+		{eventType: entity.EventBidAccepted, wantBid: overtaken.Id},
+		{eventType: entity.EventAuctionCompleted, wantBid: second.Id},
+	}
+	require.Len(t, events, len(tests))
+
+	for i, tt := range tests {
+		t.Run(string(tt.eventType), func(t *testing.T) {
+			event := events[i]
+
+			require.Equal(t, tt.eventType, event.Type)
+			require.Equal(t, auction.Id, event.AuctionId)
+			require.Equal(t, auction.Id, event.Auction.Id)
+			require.Equal(t, tt.wantLeading, event.Leading)
+			if tt.wantBid == "" {
+				require.Nil(t, event.Bid)
+			} else {
+				require.Equal(t, tt.wantBid, event.Bid.Id)
+			}
+			if tt.wantPreviousBid == "" {
+				require.Nil(t, event.PreviousBid)
+			} else {
+				require.Equal(t, tt.wantPreviousBid, event.PreviousBid.Id)
+			}
+
+			published := slices.ContainsFunc(publisher.ofType(tt.eventType), func(published entity.AuctionEvent) bool {
+				return published.Id == event.Id
+			})
+			require.True(t, published, "the event published has the id of the one in the outbox")
+		})
+	}
+
+	completed := events[len(events)-1]
+	require.Equal(t, entity.Completed, completed.Auction.Status, "the snapshot outlives the reopening")
+	require.False(t, completed.EndsAt.IsZero())
+
+	require.NoError(t, auctionRepository.AcknowledgeOutboxEvents(ctx, events[:2]))
+	left, err := auctionRepository.FindOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Equal(t, eventIds(events[2:]), eventIds(left))
+
+	require.NoError(t, auctionRepository.AcknowledgeOutboxEvents(ctx, left))
+	left, err = auctionRepository.FindOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Empty(t, left)
+}
+
+func eventIds(events []entity.AuctionEvent) []string {
+	ids := make([]string, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.Id)
+	}
+	return ids
+}
@@ -2,13 +2,16 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity"
 	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"os"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"regexp"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,40 +19,196 @@ import (
 
 type (
 	AuctionMongo struct {
-		Id          string                  `bson:"_id"`
-		ProductName string                  `bson:"product_name"`
-		Category    string                  `bson:"category"`
-		Description string                  `bson:"description"`
-		Condition   entity.ProductCondition `bson:"condition"`
-		Status      entity.AuctionStatus    `bson:"status"`
-		Timestamp   int64                   `bson:"timestamp"`
+		Id                 string                  `bson:"_id"`
+		SellerId           string                  `bson:"seller_id"`
+		ProductName        string                  `bson:"product_name"`
+		Category           string                  `bson:"category"`
+		Description        string                  `bson:"description"`
+		Condition          entity.ProductCondition `bson:"condition"`
+		Attributes         bson.M                  `bson:"attributes,omitempty"`
+		Images             []AuctionImageMongo     `bson:"images,omitempty"`
+		Status             entity.AuctionStatus    `bson:"status"`
+		ReservePrice       float64                 `bson:"reserve_price"`
+		CancellationReason string                  `bson:"cancellation_reason,omitempty"`
+		CancelledAt        int64                   `bson:"cancelled_at,omitempty"`
+		RejectionReason    string                  `bson:"rejection_reason,omitempty"`
+		RelistRule         RelistRuleMongo         `bson:"relist_rule"`
+		RelistCount        int                     `bson:"relist_count"`
+		RelistedFromId     string                  `bson:"relisted_from_id,omitempty"`
+		RelistedAsId       string                  `bson:"relisted_as_id,omitempty"`
+		RelistPending      bool                    `bson:"relist_pending,omitempty"`
+		CurrentPrice       float64                 `bson:"current_price"`
+		LeadingBid         *BidMongo               `bson:"leading_bid,omitempty"`
+		Outbox             []OutboxEventMongo      `bson:"outbox,omitempty"`
+		BidCount           int64                   `bson:"bid_count"`
+		AnsweredQuestions  int64                   `bson:"answered_questions"`
+		WatcherCount       int64                   `bson:"watcher_count"`
+		Version            int64                   `bson:"version"`
+		CreatedAt          int64                   `bson:"created_at,omitempty"`
+		Timestamp          int64                   `bson:"timestamp"`
+		ExtendedUntil      int64                   `bson:"extended_until,omitempty"`
+		EndsAt             int64                   `bson:"ends_at"`
+	}
+
+	AuctionImageMongo struct {
+		Id           string `bson:"_id"`
+		Key          string `bson:"key"`
+		ThumbnailKey string `bson:"thumbnail_key"`
+		URL          string `bson:"url"`
+		ThumbnailURL string `bson:"thumbnail_url"`
+		ContentType  string `bson:"content_type"`
+		Size         int64  `bson:"size"`
+		Width        int    `bson:"width"`
+		Height       int    `bson:"height"`
+		Position     int    `bson:"position"`
+		Primary      bool   `bson:"primary"`
+		CreatedAt    int64  `bson:"created_at"`
+	}
+
+	RelistRuleMongo struct {
+		MaxRelists            int     `bson:"max_relists"`
+		PriceReductionPercent float64 `bson:"price_reduction_percent"`
 	}
 
 	AuctionRepository struct {
 		Collection      *mongo.Collection
+		bidCollection   *mongo.Collection
+		events          repository.AuctionEventPublisher
 		auctionDuration time.Duration
+		extensionWindow time.Duration
 	}
 )
 
-func NewAuctionRepository(database *mongo.Database) *AuctionRepository {
+// maxFacetValues caps the values listed per attribute facet, most common first.
+const maxFacetValues = 50
+
+var auctionSorts = map[string]sortSpec{
+	entity.AuctionSortEndingSoonest: {field: "ends_at"},
+	entity.AuctionSortNewest:        {field: "timestamp", descending: true},
+	entity.AuctionSortPriceAsc:      {field: "current_price"},
+	entity.AuctionSortPriceDesc:     {field: "current_price", descending: true},
+	entity.AuctionSortBidCount:      {field: "bid_count", descending: true},
+}
+
+// NewAuctionRepository publishes the auction events to events. The closer is
+// started separately with StartAuctionCloser.
+func NewAuctionRepository(database *mongo.Database, events repository.AuctionEventPublisher) *AuctionRepository {
 	repository := &AuctionRepository{
 		Collection:      database.Collection("auctions"),
-		auctionDuration: getAuctionDuration(),
+		bidCollection:   database.Collection("bids"),
+		events:          events,
+		auctionDuration: entity.AuctionDuration(),
+		extensionWindow: entity.AuctionExtensionWindow(),
 	}
-	go repository.StartAuctionCloser(context.Background())
+	repository.createIndexes(context.Background())
+	repository.backfillFields(context.Background())
 	return repository
 }
 
-func (ar *AuctionRepository) CreateAuction(ctx context.Context, auction *entity.Auction) error {
-	auctionMongo := &AuctionMongo{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   auction.Condition,
-		Status:      auction.Status,
-		Timestamp:   auction.Timestamp.Unix(),
+// createIndexes covers the listing filters combined with each sort option, so
+// paginated searches do not fall back to collection scans.
+func (ar *AuctionRepository) createIndexes(ctx context.Context) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "ends_at", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "current_price", Value: 1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "bid_count", Value: -1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "seller_id", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "condition", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}},
+		{Keys: bson.D{{Key: "outbox.id", Value: 1}}},
+	}
+	if _, err := ar.Collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Error("Error trying to create auctions search indexes", err)
+	}
+}
+
+// backfillFields sets the fields added since the first auctions were stored on
+// the auctions still missing them.
+func (ar *AuctionRepository) backfillFields(ctx context.Context) {
+	defaults := bson.M{
+		"version": int64(1),
+	}
+	for field, value := range defaults {
+		filter := bson.M{field: bson.M{"$exists": false}}
+		if _, err := ar.Collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{field: value}}); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to backfill auction field %s", field), err)
+		}
+	}
+
+	ar.backfillBidTotals(ctx)
+	ar.backfillEndTimes(ctx)
+}
+
+// backfillBidTotals sets the current price and bid count of auctions stored
+// before they were kept up to date on every bid.
+func (ar *AuctionRepository) backfillBidTotals(ctx context.Context) {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"current_price": bson.M{"$exists": false}},
+		bson.M{"bid_count": bson.M{"$exists": false}},
+	}}
+	ids, err := ar.Collection.Distinct(ctx, "_id", filter)
+	if err != nil {
+		logger.Error("Error trying to find auctions without bid totals", err)
+		return
+	}
+
+	for _, id := range ids {
+		auctionId, _ := id.(string)
+		if err := ar.backfillAuctionBidTotals(ctx, auctionId); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to backfill bid totals of auction %s", auctionId), err)
+		}
+	}
+}
+
+func (ar *AuctionRepository) backfillAuctionBidTotals(ctx context.Context, auctionId string) error {
+	bidCount, err := ar.bidCollection.CountDocuments(ctx, bson.M{"auction_id": auctionId})
+	if err != nil {
+		return err
+	}
+
+	var highestBid BidMongo
+	if bidCount > 0 {
+		opts := options.FindOne().SetSort(bson.D{{Key: "amount", Value: -1}})
+		if err := ar.bidCollection.FindOne(ctx, bson.M{"auction_id": auctionId}, opts).Decode(&highestBid); err != nil {
+			return err
+		}
+	}
+
+	update := bson.M{"$set": bson.M{"current_price": highestBid.Amount, "bid_count": bidCount}}
+	_, err = ar.Collection.UpdateOne(ctx, bson.M{"_id": auctionId}, update)
+	return err
+}
+
+// backfillEndTimes stores the end time of auctions stored before it was kept
+// with them.
+func (ar *AuctionRepository) backfillEndTimes(ctx context.Context) {
+	auctions, err := ar.findAuctions(ctx, bson.M{"ends_at": bson.M{"$exists": false}})
+	if err != nil {
+		return
 	}
+
+	for i := range auctions {
+		auction := &auctions[i]
+		update := bson.M{"$set": bson.M{"ends_at": ar.endsAt(auction)}}
+		if _, err := ar.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, update); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to backfill end time of auction %s", auction.Id), err)
+		}
+	}
+}
+
+// endsAt is the end time stored with an auction, so listings can filter and
+// sort on it.
+func (ar *AuctionRepository) endsAt(auction *entity.Auction) int64 {
+	return auction.EndsAt(ar.auctionDuration).Unix()
+}
+
+// CreateAuction stores a new draft. auction.created is only recorded once the
+// auction is approved and goes live.
+func (ar *AuctionRepository) CreateAuction(ctx context.Context, auction *entity.Auction) error {
+	auctionMongo := newAuctionMongo(auction)
+	auctionMongo.EndsAt = ar.endsAt(auction)
 	_, err := ar.Collection.InsertOne(ctx, auctionMongo)
 	if err != nil {
 		logger.Error("Error trying to insert auction", err)
@@ -59,46 +218,346 @@ func (ar *AuctionRepository) CreateAuction(ctx context.Context, auction *entity.
 	return nil
 }
 
+func newCreatedEvent(auction *entity.Auction) entity.AuctionEvent {
+	event := entity.NewAuctionEvent(entity.EventAuctionCreated, auction.Id)
+	event.Auction = auction
+	return event
+}
+
 func (ar *AuctionRepository) FindAuctionById(
 	ctx context.Context, id string) (*entity.Auction, error) {
 	filter := bson.M{"_id": id}
 
 	var auctionMongo AuctionMongo
 	if err := ar.Collection.FindOne(ctx, filter).Decode(&auctionMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", id))
+		}
+
 		logger.Error(fmt.Sprintf("Error trying to find auction by id = %s", id), err)
 		return nil, internal_error.NewInternalServerError("Error trying to find auction by id")
 	}
 
-	return &entity.Auction{
-		Id:          auctionMongo.Id,
-		ProductName: auctionMongo.ProductName,
-		Category:    auctionMongo.Category,
-		Description: auctionMongo.Description,
-		Condition:   auctionMongo.Condition,
-		Status:      auctionMongo.Status,
-		Timestamp:   time.Unix(auctionMongo.Timestamp, 0),
-	}, nil
+	return auctionMongo.toEntity(), nil
 }
 
 func (ar *AuctionRepository) FindAuctions(
 	ctx context.Context,
-	status entity.AuctionStatus,
-	category string,
-	productName string) ([]entity.Auction, error) {
+	query entity.AuctionQuery,
+	page entity.PageRequest) (*entity.Page[entity.Auction], error) {
+	filter := ar.auctionQueryFilter(query)
+
+	auctionsPage, err := findPage(ctx, ar.Collection, filter, page, auctionSorts,
+		func(auction *AuctionMongo) (float64, string) {
+			return auctionSortValue(auction, page.Sort), auction.Id
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	auctions := make([]entity.Auction, 0, len(auctionsPage.Items))
+	for _, auction := range auctionsPage.Items {
+		auctions = append(auctions, *auction.toEntity())
+	}
+
+	return &entity.Page[entity.Auction]{
+		Items:      auctions,
+		NextCursor: auctionsPage.NextCursor,
+		HasMore:    auctionsPage.HasMore,
+		Total:      auctionsPage.Total,
+	}, nil
+}
+
+func (ar *AuctionRepository) auctionQueryFilter(query entity.AuctionQuery) bson.M {
+	filter := bson.M{}
+
+	if len(query.Statuses) > 0 {
+		filter["status"] = bson.M{"$in": query.Statuses}
+	} else {
+		filter["status"] = bson.M{"$in": bson.A{entity.Active, entity.Completed, entity.Cancelled}}
+	}
+
+	if len(query.Categories) > 0 {
+		filter["category"] = bson.M{"$in": query.Categories}
+	}
+
+	if query.SellerId != "" {
+		filter["seller_id"] = query.SellerId
+	}
+
+	if query.Condition != nil {
+		filter["condition"] = *query.Condition
+	}
+
+	if query.ProductName != "" {
+		filter["product_name"] = containsPattern(query.ProductName)
+	}
+
+	if query.Text != "" {
+		filter["$or"] = bson.A{
+			bson.M{"product_name": containsPattern(query.Text)},
+			bson.M{"description": containsPattern(query.Text)},
+		}
+	}
+
+	price := bson.M{}
+	if query.MinPrice != nil {
+		price["$gte"] = *query.MinPrice
+	}
+	if query.MaxPrice != nil {
+		price["$lte"] = *query.MaxPrice
+	}
+	if len(price) > 0 {
+		filter["current_price"] = price
+	}
+
+	if created := unixRange(query.CreatedFrom, query.CreatedTo); len(created) > 0 {
+		filter["created_at"] = created
+	}
+
+	if ending := unixRange(query.EndingFrom, query.EndingTo); len(ending) > 0 {
+		filter["ends_at"] = ending
+	}
+
+	for field, condition := range attributeFilter(query.Attributes, "") {
+		filter[field] = condition
+	}
+
+	if query.HasBids != nil {
+		if *query.HasBids {
+			filter["bid_count"] = bson.M{"$gt": 0}
+		} else {
+			filter["bid_count"] = bson.M{"$not": bson.M{"$gt": 0}}
+		}
+	}
+
+	return filter
+}
+
+// attributeFilter matches the attribute filters of a listing, leaving out the
+// one on the except key.
+func attributeFilter(attributes []entity.AttributeFilter, except string) bson.M {
 	filter := bson.M{}
+	for _, attribute := range attributes {
+		if attribute.Key == except {
+			continue
+		}
+
+		field := "attributes." + attribute.Key
+		if len(attribute.Values) > 0 {
+			filter[field] = bson.M{"$in": attribute.Values}
+			continue
+		}
+
+		bounds := bson.M{}
+		if attribute.Min != nil {
+			bounds["$gte"] = *attribute.Min
+		}
+		if attribute.Max != nil {
+			bounds["$lte"] = *attribute.Max
+		}
+		if len(bounds) > 0 {
+			filter[field] = bounds
+		}
+	}
+	return filter
+}
+
+// containsPattern matches value anywhere in a field, case-insensitively and
+// with any regex metacharacters in the user input taken literally.
+func containsPattern(value string) primitive.Regex {
+	return primitive.Regex{Pattern: regexp.QuoteMeta(value), Options: "i"}
+}
+
+func unixRange(from, to time.Time) bson.M {
+	bounds := bson.M{}
+	if !from.IsZero() {
+		bounds["$gte"] = from.Unix()
+	}
+	if !to.IsZero() {
+		bounds["$lte"] = to.Unix()
+	}
+	return bounds
+}
+
+func auctionSortValue(auction *AuctionMongo, sort string) float64 {
+	switch sort {
+	case entity.AuctionSortPriceAsc, entity.AuctionSortPriceDesc:
+		return auction.CurrentPrice
+	case entity.AuctionSortBidCount:
+		return float64(auction.BidCount)
+	case entity.AuctionSortEndingSoonest:
+		return float64(auction.EndsAt)
+	default:
+		return float64(auction.Timestamp)
+	}
+}
+
+// FindAuctionFacets counts the values, or the range of numeric ones, of the
+// given attributes over the matching auctions. Each facet leaves out the
+// filter on its own attribute.
+func (ar *AuctionRepository) FindAuctionFacets(
+	ctx context.Context,
+	query entity.AuctionQuery,
+	definitions []entity.AttributeDefinition) ([]entity.AttributeFacet, error) {
+	if len(definitions) == 0 {
+		return nil, nil
+	}
+
+	facets := bson.M{}
+	for _, definition := range definitions {
+		field := "$attributes." + definition.Key
+		match := attributeFilter(query.Attributes, definition.Key)
+		match["attributes."+definition.Key] = bson.M{"$exists": true}
+		present := bson.M{"$match": match}
+
+		if definition.IsNumeric() {
+			facets[definition.Key] = bson.A{
+				present,
+				bson.M{"$group": bson.M{"_id": nil, "min": bson.M{"$min": field}, "max": bson.M{"$max": field}}},
+			}
+			continue
+		}
+
+		facets[definition.Key] = bson.A{
+			present,
+			bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": maxFacetValues},
+		}
+	}
+
+	unfiltered := query
+	unfiltered.Attributes = nil
+	pipeline := bson.A{
+		bson.M{"$match": ar.auctionQueryFilter(unfiltered)},
+		bson.M{"$facet": facets},
+	}
+	cursor, err := ar.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		logger.Error("Error aggregating auction facets", err)
+		return nil, internal_error.NewInternalServerError("Error finding auction facets")
+	}
+	defer cursor.Close(ctx)
 
-	if status != 0 {
-		filter["status"] = status
+	var results []map[string][]bson.M
+	if err := cursor.All(ctx, &results); err != nil || len(results) != 1 {
+		logger.Error("Error decoding auction facets", err)
+		return nil, internal_error.NewInternalServerError("Error decoding auction facets")
 	}
 
-	if category != "" {
-		filter["category"] = category
+	attributeFacets := make([]entity.AttributeFacet, 0, len(definitions))
+	for _, definition := range definitions {
+		facet := entity.AttributeFacet{Definition: definition}
+		for _, bucket := range results[0][definition.Key] {
+			if definition.IsNumeric() {
+				if minimum, ok := entity.NumberValue(bucket["min"]); ok {
+					facet.Min = &minimum
+				}
+				if maximum, ok := entity.NumberValue(bucket["max"]); ok {
+					facet.Max = &maximum
+				}
+				continue
+			}
+
+			count, _ := entity.NumberValue(bucket["count"])
+			facet.Values = append(facet.Values, entity.FacetValue{Value: bucket["_id"], Count: int64(count)})
+		}
+		attributeFacets = append(attributeFacets, facet)
+	}
+
+	return attributeFacets, nil
+}
+
+// UpdateAuctionImages saves the image gallery only if it still is the one the
+// change was made on, so concurrent uploads do not overwrite each other.
+func (ar *AuctionRepository) UpdateAuctionImages(
+	ctx context.Context,
+	auction *entity.Auction,
+	previous []entity.AuctionImage) error {
+	filter := bson.M{"_id": auction.Id}
+	if len(previous) == 0 {
+		filter["$or"] = bson.A{
+			bson.M{"images": bson.M{"$exists": false}},
+			bson.M{"images": bson.M{"$size": 0}},
+		}
+	} else {
+		filter["images"] = newAuctionImagesMongo(previous)
 	}
 
-	if productName != "" {
-		filter["productName"] = primitive.Regex{Pattern: productName, Options: "i"}
+	update := bson.M{"$set": bson.M{"images": newAuctionImagesMongo(auction.Images)}}
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update images of auction %s", auction.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update auction images")
+	}
+	if result.MatchedCount == 0 {
+		return internal_error.NewVersionConflictError("Auction images were changed by another request, reload them and try again")
 	}
 
+	return nil
+}
+
+// IsImageInUse tells whether any auction still shows the image file, which
+// relisted auctions share with the auction they were relisted from.
+func (ar *AuctionRepository) IsImageInUse(ctx context.Context, key string) (bool, error) {
+	opts := options.Count().SetLimit(1)
+	count, err := ar.Collection.CountDocuments(ctx, bson.M{"images.key": key}, opts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error counting auctions using image %s", key), err)
+		return false, internal_error.NewInternalServerError("Error checking auction image usage")
+	}
+
+	return count > 0, nil
+}
+
+// HasAuctionsInCategory tells whether any auction, whatever its status, is
+// listed under the category slug.
+func (ar *AuctionRepository) HasAuctionsInCategory(ctx context.Context, category string) (bool, error) {
+	opts := options.Count().SetLimit(1)
+	count, err := ar.Collection.CountDocuments(ctx, bson.M{"category": category}, opts)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error counting auctions in category %s", category), err)
+		return false, internal_error.NewInternalServerError("Error counting auctions in category")
+	}
+
+	return count > 0, nil
+}
+
+// FindAuctionCategories lists the distinct categories auctions are listed
+// under, whatever their status.
+func (ar *AuctionRepository) FindAuctionCategories(ctx context.Context) ([]string, error) {
+	values, err := ar.Collection.Distinct(ctx, "category", bson.M{})
+	if err != nil {
+		logger.Error("Error finding auction categories", err)
+		return nil, internal_error.NewInternalServerError("Error finding auction categories")
+	}
+
+	categories := make([]string, 0, len(values))
+	for _, value := range values {
+		if category, ok := value.(string); ok {
+			categories = append(categories, category)
+		}
+	}
+	return categories, nil
+}
+
+// RenameAuctionCategory moves every auction listed under one category to
+// another.
+func (ar *AuctionRepository) RenameAuctionCategory(ctx context.Context, from, to string) error {
+	update := bson.M{"$set": bson.M{"category": to}}
+	if _, err := ar.Collection.UpdateMany(ctx, bson.M{"category": from}, update); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to move auctions from category %s to %s", from, to), err)
+		return internal_error.NewInternalServerError("Error trying to move auctions to another category")
+	}
+	return nil
+}
+
+func (ar *AuctionRepository) FindAuctionsByIds(ctx context.Context, ids []string) ([]entity.Auction, error) {
+	return ar.findAuctions(ctx, bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (ar *AuctionRepository) findAuctions(ctx context.Context, filter bson.M) ([]entity.Auction, error) {
 	cursor, err := ar.Collection.Find(ctx, filter)
 	if err != nil {
 		logger.Error("Error finding auctions", err)
@@ -114,67 +573,500 @@ func (ar *AuctionRepository) FindAuctions(
 
 	var auctions []entity.Auction
 	for _, auction := range auctionsMongo {
-		auctions = append(auctions, entity.Auction{
-			Id:          auction.Id,
-			ProductName: auction.ProductName,
-			Category:    auction.Category,
-			Status:      auction.Status,
-			Description: auction.Description,
-			Condition:   auction.Condition,
-			Timestamp:   time.Unix(auction.Timestamp, 0),
-		})
+		auctions = append(auctions, *auction.toEntity())
 	}
 
 	return auctions, nil
 }
 
-func (ar *AuctionRepository) StartAuctionCloser(ctx context.Context) {
+// UpdateAuctionStatus moves an auction out of previousStatus unless another
+// transition did first, publishing the events of approvals, closes and reopens.
+func (ar *AuctionRepository) UpdateAuctionStatus(
+	ctx context.Context,
+	auction *entity.Auction,
+	previousStatus entity.AuctionStatus) error {
+	filter := bson.M{"_id": auction.Id, "status": previousStatus}
+
+	auctionMongo := newAuctionMongo(auction)
+	update := bson.M{"$set": bson.M{
+		"status":              auctionMongo.Status,
+		"cancellation_reason": auctionMongo.CancellationReason,
+		"cancelled_at":        auctionMongo.CancelledAt,
+		"rejection_reason":    auctionMongo.RejectionReason,
+		"timestamp":           auctionMongo.Timestamp,
+		"extended_until":      auctionMongo.ExtendedUntil,
+		"ends_at":             ar.endsAt(auction),
+	}}
+	completed := previousStatus == entity.Active && auction.Status == entity.Completed
+	var completedEvent entity.AuctionEvent
+	if completed {
+		var err error
+		if completedEvent, err = ar.newCompletedEvent(ctx, auction, filter); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to find highest bid of auction %s", auction.Id), err)
+			return internal_error.NewInternalServerError("Error trying to update auction status")
+		}
+		update["$push"] = bson.M{"outbox": newOutboxEventMongo(&completedEvent)}
+	}
+	approved := previousStatus == entity.PendingApproval && auction.Status == entity.Active
+	createdEvent := newCreatedEvent(auction)
+	if approved {
+		update["$push"] = bson.M{"outbox": newOutboxEventMongo(&createdEvent)}
+	}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update status of auction %s", auction.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update auction status")
+	}
+	if result.MatchedCount == 0 {
+		return internal_error.NewVersionConflictError("Auction status was changed by another request, reload it and try again")
+	}
+
+	switch {
+	case completed:
+		ar.events.Publish(ctx, completedEvent)
+	case approved:
+		ar.events.Publish(ctx, createdEvent)
+	case (previousStatus == entity.Completed || previousStatus == entity.Cancelled) && auction.Status == entity.Active:
+		event := entity.NewAuctionEvent(entity.EventAuctionExtended, auction.Id)
+		event.Auction = auction
+		event.EndsAt = auction.EndsAt(ar.auctionDuration)
+		ar.events.Publish(ctx, event)
+	}
+
+	return nil
+}
+
+// recordBid counts a stored bid on its auction, records bid.accepted in the
+// outbox and extends the auction in one write, which only matches while the
+// auction is open at the bid time and still has the price the bid was compared
+// with. It returns false when the auction no longer takes the bid.
+func (ar *AuctionRepository) recordBid(ctx context.Context, event *entity.AuctionEvent) (bool, *entity.Auction, error) {
+	bid := newBidMongo(event.Bid)
+	open := bson.M{"_id": bid.AuctionId, "status": entity.Active, "ends_at": bson.M{"$gt": bid.Timestamp}}
+
+	for {
+		var auctionMongo AuctionMongo
+		err := ar.Collection.FindOne(ctx, open).Decode(&auctionMongo)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil, nil
+		}
+		if err != nil {
+			return false, nil, err
+		}
+
+		filter := bson.M{}
+		for key, value := range open {
+			filter[key] = value
+		}
+		set := bson.M{}
+		update := bson.M{"$inc": bson.M{"bid_count": 1}}
+
+		event.Leading = bid.Amount > auctionMongo.CurrentPrice
+		event.PreviousBid = nil
+		if event.Leading {
+			if event.PreviousBid, err = ar.previousLeadingBid(ctx, &auctionMongo, bid.Id); err != nil {
+				return false, nil, err
+			}
+			filter["current_price"] = unchanged(auctionMongo.CurrentPrice)
+			set["current_price"] = bid.Amount
+			set["leading_bid"] = bid
+		} else {
+			filter["current_price"] = bson.M{"$gte": bid.Amount}
+		}
+
+		var extended *entity.Auction
+		auction := auctionMongo.toEntity()
+		if extendedUntil, ok := auction.Extend(event.Bid.Timestamp, ar.auctionDuration, ar.extensionWindow); ok {
+			filter["extended_until"] = unchanged(auctionMongo.ExtendedUntil)
+			auction.ExtendedUntil = extendedUntil
+			set["extended_until"] = extendedUntil.Unix()
+			set["ends_at"] = ar.endsAt(auction)
+			extended = auction
+		}
+
+		if len(set) > 0 {
+			update["$set"] = set
+		}
+		update["$push"] = bson.M{"outbox": newOutboxEventMongo(event)}
+
+		result, err := ar.Collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return false, nil, err
+		}
+		if result.MatchedCount == 1 {
+			return true, extended, nil
+		}
+	}
+}
+
+// unchanged matches a field still holding the value read, where a zero value
+// may also be stored as a missing field.
+func unchanged[T float64 | int64](value T) interface{} {
+	if value == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return value
+}
+
+// previousLeadingBid is the bid an auction is led by, before it is outbid.
+func (ar *AuctionRepository) previousLeadingBid(
+	ctx context.Context,
+	auctionMongo *AuctionMongo,
+	bidId string,
+) (*entity.Bid, error) {
+	if auctionMongo.LeadingBid != nil {
+		return auctionMongo.LeadingBid.toEntity(), nil
+	}
+	if auctionMongo.BidCount == 0 {
+		return nil, nil
+	}
+	return ar.findHighestStoredBid(ctx, auctionMongo.Id, bidId)
+}
+
+// countAnsweredQuestion keeps the denormalized answered question count shown
+// with the auction in step with the questions collection.
+func (ar *AuctionRepository) countAnsweredQuestion(ctx context.Context, auctionId string, delta int) error {
+	return ar.incrementCounter(ctx, auctionId, "answered_questions", delta)
+}
+
+// incrementCounter moves a counter of the auction in place, never below zero,
+// so concurrent changes all add up.
+func (ar *AuctionRepository) incrementCounter(ctx context.Context, auctionId, field string, delta int) error {
+	filter := bson.M{"_id": auctionId}
+	if delta < 0 {
+		filter[field] = bson.M{"$gte": -delta}
+	}
+
+	_, err := ar.Collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{field: delta}})
+	return err
+}
+
+// addWatcher increments the watcher count of the auction and returns it.
+func (ar *AuctionRepository) addWatcher(ctx context.Context, auctionId string) (int64, error) {
+	update := bson.M{"$inc": bson.M{"watcher_count": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var counted struct {
+		WatcherCount int64 `bson:"watcher_count"`
+	}
+	err := ar.Collection.FindOneAndUpdate(ctx, bson.M{"_id": auctionId}, update, opts).Decode(&counted)
+	return counted.WatcherCount, err
+}
+
+// StartAuctionCloser completes the auctions past their end time every minute,
+// after writing queued bids, and relists the unsold ones.
+func (ar *AuctionRepository) StartAuctionCloser(ctx context.Context, bidQueue repository.BidQueue) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			ar.closeExpiredAuctions(ctx)
+			ar.closeExpiredAuctions(ctx, bidQueue)
+			ar.relistPendingAuctions(ctx)
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func (ar *AuctionRepository) closeExpiredAuctions(ctx context.Context) {
-	filter := bson.M{"status": entity.Active}
-	cursor, err := ar.Collection.Find(ctx, filter)
-	if err != nil {
-		logger.Error("Error finding open auctions", err)
+func (ar *AuctionRepository) closeExpiredAuctions(ctx context.Context, bidQueue repository.BidQueue) {
+	filter := bson.M{"status": entity.Active, "ends_at": bson.M{"$lt": time.Now().Unix()}}
+	expired, err := ar.findAuctions(ctx, filter)
+	if err != nil || len(expired) == 0 {
 		return
 	}
-	defer cursor.Close(ctx)
 
-	for cursor.Next(ctx) {
-		var auction AuctionMongo
-		if err = cursor.Decode(&auction); err != nil {
-			logger.Error("Error decoding auction", err)
+	if err := bidQueue.FlushBids(ctx); err != nil {
+		logger.Error("Error writing queued bids before closing auctions", err)
+		return
+	}
+
+	for i := range expired {
+		auction := &expired[i]
+
+		// Whether an unsold auction is relisted is settled by the relist pass,
+		// which picks up where it left off if the process stops midway.
+		set := bson.M{"status": entity.Completed}
+		if auction.RelistCount < auction.RelistRule.MaxRelists {
+			set["relist_pending"] = true
+		}
+		auction.Status = entity.Completed
+		filter := bson.M{"_id": auction.Id, "status": entity.Active}
+		event, err := ar.newCompletedEvent(ctx, auction, filter)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error trying to find highest bid of auction %s", auction.Id), err)
+			continue
+		}
+		update := bson.M{"$set": set, "$push": bson.M{"outbox": newOutboxEventMongo(&event)}}
+		result, err := ar.Collection.UpdateOne(ctx, filter, update)
+		if err != nil {
+			logger.Error("Error updating auction status to completed", err)
 			continue
 		}
+		if result.ModifiedCount == 1 {
+			ar.events.Publish(ctx, event)
+		}
+	}
+}
+
+// newCompletedEvent snapshots the outcome of an auction being completed, and
+// narrows filter to the leading bid it was taken with.
+func (ar *AuctionRepository) newCompletedEvent(
+	ctx context.Context,
+	auction *entity.Auction,
+	filter bson.M,
+) (entity.AuctionEvent, error) {
+	event := entity.NewAuctionEvent(entity.EventAuctionCompleted, auction.Id)
+	event.Auction = auction
+	event.EndsAt = auction.EndsAt(ar.auctionDuration)
+	if event.OccurredAt.Before(event.EndsAt) {
+		event.EndsAt = event.OccurredAt
+	}
+
+	var auctionMongo AuctionMongo
+	err := ar.Collection.FindOne(ctx, bson.M{"_id": auction.Id}).Decode(&auctionMongo)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return event, err
+	}
+	if auctionMongo.LeadingBid != nil {
+		event.Bid = auctionMongo.LeadingBid.toEntity()
+		filter["leading_bid._id"] = event.Bid.Id
+		return event, nil
+	}
 
-		timeStamp := time.Unix(auction.Timestamp, 0)
+	filter["leading_bid"] = bson.M{"$exists": false}
+	event.Bid, err = ar.findHighestStoredBid(ctx, auction.Id)
+	return event, err
+}
+
+// relistPendingAuctions puts unsold completed auctions back up. Relist ids are
+// derived from the original, so an interrupted run can safely be repeated.
+func (ar *AuctionRepository) relistPendingAuctions(ctx context.Context) {
+	pending, err := ar.findAuctions(ctx, bson.M{"relist_pending": true})
+	if err != nil {
+		return
+	}
+
+	for i := range pending {
+		auction := &pending[i]
+		highestBid, err := ar.findHighestBid(ctx, auction.Id)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Error trying to find highest bid of auction %s", auction.Id), err)
+			continue
+		}
 
-		if time.Now().After(timeStamp.Add(ar.auctionDuration)) {
-			auction.Status = entity.Completed
-			update := bson.M{"$set": bson.M{"status": entity.Completed}}
-			if _, err := ar.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, update); err != nil {
-				logger.Error("Error updating auction status to completed", err)
+		update := bson.M{"$unset": bson.M{"relist_pending": ""}}
+		if auction.ShouldRelist(highestBid) {
+			relisted := auction.Relist()
+			event := newCreatedEvent(relisted)
+			relistedMongo := newAuctionMongo(relisted, event)
+			relistedMongo.EndsAt = ar.endsAt(relisted)
+			_, err := ar.Collection.InsertOne(ctx, relistedMongo)
+			if err != nil && !mongo.IsDuplicateKeyError(err) {
+				logger.Error(fmt.Sprintf("Error trying to relist auction %s", auction.Id), err)
+				continue
+			}
+			if err == nil {
+				ar.events.Publish(ctx, event)
 			}
+			update["$set"] = bson.M{"relisted_as_id": relisted.Id}
+		}
+
+		if _, err := ar.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, update); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to link auction %s to its relist", auction.Id), err)
 		}
 	}
 }
 
-func getAuctionDuration() time.Duration {
-	auctionDuration := os.Getenv("AUCTION_DURATION")
-	duration, err := time.ParseDuration(auctionDuration)
+// findHighestBid returns the leading bid stored with the auction, or nil when it has no bids.
+func (ar *AuctionRepository) findHighestBid(ctx context.Context, auctionId string) (*entity.Bid, error) {
+	var auctionMongo AuctionMongo
+	err := ar.Collection.FindOne(ctx, bson.M{"_id": auctionId}).Decode(&auctionMongo)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if auctionMongo.LeadingBid != nil {
+		return auctionMongo.LeadingBid.toEntity(), nil
+	}
+
+	return ar.findHighestStoredBid(ctx, auctionId)
+}
+
+// findHighestStoredBid picks the earliest of the highest stored bids, for
+// auctions bid on before the leading bid was stored with them.
+func (ar *AuctionRepository) findHighestStoredBid(
+	ctx context.Context,
+	auctionId string,
+	excludedBidIds ...string,
+) (*entity.Bid, error) {
+	filter := bson.M{"auction_id": auctionId}
+	if len(excludedBidIds) > 0 {
+		filter["_id"] = bson.M{"$nin": excludedBidIds}
+	}
+
+	var bidMongo BidMongo
+	opts := options.FindOne().SetSort(bson.D{
+		{Key: "amount", Value: -1}, {Key: "timestamp", Value: 1}, {Key: "_id", Value: 1},
+	})
+	err := ar.bidCollection.FindOne(ctx, filter, opts).Decode(&bidMongo)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return bidMongo.toEntity(), nil
+}
+
+// UpdateAuction persists an edited auction only if the stored version is one
+// behind, and with requireNoBids only while the auction has no bids.
+func (ar *AuctionRepository) UpdateAuction(ctx context.Context, auction *entity.Auction, requireNoBids bool) error {
+	filter := bson.M{"_id": auction.Id, "status": auction.Status, "version": auction.Version - 1}
+	if requireNoBids {
+		filter["bid_count"] = bson.M{"$not": bson.M{"$gt": 0}}
+	}
+	update := bson.M{"$set": bson.M{
+		"product_name":  auction.ProductName,
+		"category":      auction.Category,
+		"description":   auction.Description,
+		"condition":     auction.Condition,
+		"attributes":    auction.Attributes,
+		"reserve_price": auction.ReservePrice,
+		"version":       auction.Version,
+	}}
+
+	result, err := ar.Collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return time.Second * 30
+		logger.Error(fmt.Sprintf("Error trying to update auction %s", auction.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update auction")
+	}
+	if result.MatchedCount == 0 {
+		return internal_error.NewVersionConflictError("Auction was modified by another request, reload it and try again")
+	}
+
+	return nil
+}
+
+// newAuctionMongo converts an auction to store, with the given events waiting
+// in its outbox.
+func newAuctionMongo(auction *entity.Auction, outbox ...entity.AuctionEvent) *AuctionMongo {
+	auctionMongo := &AuctionMongo{
+		Id:                 auction.Id,
+		SellerId:           auction.SellerId,
+		ProductName:        auction.ProductName,
+		Category:           auction.Category,
+		Description:        auction.Description,
+		Condition:          auction.Condition,
+		Attributes:         auction.Attributes,
+		Images:             newAuctionImagesMongo(auction.Images),
+		Status:             auction.Status,
+		ReservePrice:       auction.ReservePrice,
+		CancellationReason: auction.CancellationReason,
+		RejectionReason:    auction.RejectionReason,
+		RelistRule: RelistRuleMongo{
+			MaxRelists:            auction.RelistRule.MaxRelists,
+			PriceReductionPercent: auction.RelistRule.PriceReductionPercent,
+		},
+		RelistCount:       auction.RelistCount,
+		RelistedFromId:    auction.RelistedFromId,
+		RelistedAsId:      auction.RelistedAsId,
+		CurrentPrice:      auction.CurrentPrice,
+		BidCount:          auction.BidCount,
+		AnsweredQuestions: auction.AnsweredQuestions,
+		WatcherCount:      auction.WatcherCount,
+		Version:           auction.Version,
+		Timestamp:         auction.Timestamp.Unix(),
+	}
+	if !auction.ExtendedUntil.IsZero() {
+		auctionMongo.ExtendedUntil = auction.ExtendedUntil.Unix()
+	}
+	if !auction.CancelledAt.IsZero() {
+		auctionMongo.CancelledAt = auction.CancelledAt.Unix()
+	}
+	if !auction.CreatedAt.IsZero() {
+		auctionMongo.CreatedAt = auction.CreatedAt.Unix()
+	}
+	for i := range outbox {
+		auctionMongo.Outbox = append(auctionMongo.Outbox, newOutboxEventMongo(&outbox[i]))
+	}
+	return auctionMongo
+}
+
+func (am *AuctionMongo) toEntity() *entity.Auction {
+	auction := &entity.Auction{
+		Id:                 am.Id,
+		SellerId:           am.SellerId,
+		ProductName:        am.ProductName,
+		Category:           am.Category,
+		Description:        am.Description,
+		Condition:          am.Condition,
+		Attributes:         am.Attributes,
+		Status:             am.Status,
+		ReservePrice:       am.ReservePrice,
+		CancellationReason: am.CancellationReason,
+		RejectionReason:    am.RejectionReason,
+		RelistRule: entity.RelistRule{
+			MaxRelists:            am.RelistRule.MaxRelists,
+			PriceReductionPercent: am.RelistRule.PriceReductionPercent,
+		},
+		RelistCount:       am.RelistCount,
+		RelistedFromId:    am.RelistedFromId,
+		RelistedAsId:      am.RelistedAsId,
+		CurrentPrice:      am.CurrentPrice,
+		BidCount:          am.BidCount,
+		AnsweredQuestions: am.AnsweredQuestions,
+		WatcherCount:      am.WatcherCount,
+		Version:           am.Version,
+		Timestamp:         time.Unix(am.Timestamp, 0),
+	}
+	if am.ExtendedUntil != 0 {
+		auction.ExtendedUntil = time.Unix(am.ExtendedUntil, 0)
+	}
+	if am.CancelledAt != 0 {
+		auction.CancelledAt = time.Unix(am.CancelledAt, 0)
+	}
+	if am.CreatedAt != 0 {
+		auction.CreatedAt = time.Unix(am.CreatedAt, 0)
+	}
+	for _, image := range am.Images {
+		auction.Images = append(auction.Images, entity.AuctionImage{
+			Id:           image.Id,
+			Key:          image.Key,
+			ThumbnailKey: image.ThumbnailKey,
+			URL:          image.URL,
+			ThumbnailURL: image.ThumbnailURL,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Width:        image.Width,
+			Height:       image.Height,
+			Position:     image.Position,
+			Primary:      image.Primary,
+			CreatedAt:    time.Unix(image.CreatedAt, 0),
+		})
+	}
+	return auction
+}
+
+func newAuctionImagesMongo(images []entity.AuctionImage) []AuctionImageMongo {
+	imagesMongo := make([]AuctionImageMongo, 0, len(images))
+	for _, image := range images {
+		imagesMongo = append(imagesMongo, AuctionImageMongo{
+			Id:           image.Id,
+			Key:          image.Key,
+			ThumbnailKey: image.ThumbnailKey,
+			URL:          image.URL,
+			ThumbnailURL: image.ThumbnailURL,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Width:        image.Width,
+			Height:       image.Height,
+			Position:     image.Position,
+			Primary:      image.Primary,
+			CreatedAt:    image.CreatedAt.Unix(),
+		})
 	}
-	return duration
+	return imagesMongo
 }
@@ -0,0 +1,435 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+	"time"
+)
+
+func newTestAuctionRepository(t *testing.T) (*AuctionRepository, *recordingPublisher) {
+	t.Helper()
+
+	database := newTestDatabase(t)
+	publisher := &recordingPublisher{}
+	return &AuctionRepository{
+		Collection:      database.Collection("auctions"),
+		bidCollection:   database.Collection("bids"),
+		events:          publisher,
+		auctionDuration: time.Minute,
+	}, publisher
+}
+
+func TestAuctionRepositoryUpdateAuction(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		legacy        bool
+		bidCount      int64
+		requireNoBids bool
+		wantErr       bool
+	}{
+		{name: "current version"},
+		{name: "auction stored before versioning", legacy: true},
+		{name: "bids allowed", bidCount: 2},
+		{name: "no bids required and none placed", requireNoBids: true},
+		{name: "no bids required but one placed", bidCount: 1, requireNoBids: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionRepository, _ := newTestAuctionRepository(t)
+			auction := newTestAuction(t, auctionRepository, entity.Active)
+
+			update := bson.M{"$set": bson.M{"bid_count": tt.bidCount}}
+			if tt.legacy {
+				update["$unset"] = bson.M{"version": ""}
+			}
+			_, err := auctionRepository.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, update)
+			require.NoError(t, err)
+			auctionRepository.backfillFields(ctx)
+
+			stored, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+			require.NoError(t, err)
+			require.EqualValues(t, 1, stored.Version)
+
+			stored.ProductName = "Vintage camera"
+			stored.Version++
+			err = auctionRepository.UpdateAuction(ctx, stored, tt.requireNoBids)
+
+			updated, findErr := auctionRepository.FindAuctionById(ctx, auction.Id)
+			require.NoError(t, findErr)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, "Camera", updated.ProductName)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, "Vintage camera", updated.ProductName)
+			require.EqualValues(t, 2, updated.Version)
+		})
+	}
+}
+
+func TestAuctionRepositoryCloserRelistsUnsoldAuctions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		maxRelists   int
+		queuedAmount float64
+		wantRelist   bool
+	}{
+		{name: "unsold", maxRelists: 1, wantRelist: true},
+		{name: "queued bid below reserve", maxRelists: 1, queuedAmount: 50, wantRelist: true},
+		{name: "queued bid reaching reserve", maxRelists: 1, queuedAmount: 100},
+		{name: "no relist rule"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionRepository, publisher := newTestAuctionRepository(t)
+			bidRepository := &BidRepository{
+				Collection:        auctionRepository.bidCollection,
+				AuctionRepository: auctionRepository,
+			}
+
+			auction := newTestAuction(t, auctionRepository, entity.Active)
+			_, err := auctionRepository.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, bson.M{"$set": bson.M{
+				"reserve_price":           100,
+				"relist_rule.max_relists": tt.maxRelists,
+			}})
+			require.NoError(t, err)
+			restartTestAuction(t, auctionRepository, auction.Id, time.Now().Add(-30*time.Minute), time.Time{})
+
+			queue := &queuedBids{bidRepository: bidRepository}
+			if tt.queuedAmount > 0 {
+				bid, err := entity.CreateBid(testOtherUserId, auction.Id, tt.queuedAmount)
+				require.NoError(t, err)
+				bid.Timestamp = time.Now().Add(-29*time.Minute - 30*time.Second)
+				queue.bids = append(queue.bids, *bid)
+			}
+
+			auctionRepository.closeExpiredAuctions(ctx, queue)
+			auctionRepository.relistPendingAuctions(ctx)
+			auctionRepository.relistPendingAuctions(ctx)
+
+			completed, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+			require.NoError(t, err)
+			require.Equal(t, entity.Completed, completed.Status)
+			require.Len(t, publisher.ofType(entity.EventAuctionCompleted), 1)
+
+			relists, err := auctionRepository.Collection.CountDocuments(ctx, bson.M{"relisted_from_id": auction.Id})
+			require.NoError(t, err)
+			if tt.wantRelist {
+				require.EqualValues(t, 1, relists)
+				require.NotEmpty(t, completed.RelistedAsId)
+				require.Len(t, publisher.ofType(entity.EventAuctionCreated), 1)
+			} else {
+				require.Zero(t, relists)
+				require.Empty(t, completed.RelistedAsId)
+			}
+		})
+	}
+}
+
+func TestAuctionRepositoryRelistResumesAfterInterruption(t *testing.T) {
+	ctx := context.Background()
+	auctionRepository, _ := newTestAuctionRepository(t)
+
+	auction := newTestAuction(t, auctionRepository, entity.Completed)
+	_, err := auctionRepository.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, bson.M{"$set": bson.M{
+		"relist_rule.max_relists": 1,
+		"relist_pending":          true,
+	}})
+	require.NoError(t, err)
+
+	// The relist was inserted but the process stopped before linking it.
+	auction.Status = entity.Completed
+	auction.RelistRule.MaxRelists = 1
+	_, err = auctionRepository.Collection.InsertOne(ctx, newAuctionMongo(auction.Relist()))
+	require.NoError(t, err)
+
+	auctionRepository.relistPendingAuctions(ctx)
+
+	relists, err := auctionRepository.Collection.CountDocuments(ctx, bson.M{"relisted_from_id": auction.Id})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, relists)
+
+	linked, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+	require.NoError(t, err)
+	require.Equal(t, auction.RelistedAsId, linked.RelistedAsId)
+
+	pending, err := auctionRepository.Collection.CountDocuments(ctx, bson.M{"relist_pending": true})
+	require.NoError(t, err)
+	require.Zero(t, pending)
+}
+
+func TestAuctionRepositoryBackfillsBidTotals(t *testing.T) {
+	ctx := context.Background()
+	auctionRepository, _ := newTestAuctionRepository(t)
+
+	tests := []struct {
+		name      string
+		bids      []float64
+		wantPrice float64
+		wantCount int64
+	}{
+		{name: "with bids", bids: []float64{10, 30, 20}, wantPrice: 30, wantCount: 3},
+		{name: "without bids"},
+	}
+
+	auctionIds := make([]string, len(tests))
+	for i, tt := range tests {
+		auction := newTestAuction(t, auctionRepository, entity.Completed)
+		auctionIds[i] = auction.Id
+		unset := bson.M{"$unset": bson.M{"current_price": "", "bid_count": ""}}
+		_, err := auctionRepository.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, unset)
+		require.NoError(t, err)
+
+		for _, amount := range tt.bids {
+			_, err := auctionRepository.bidCollection.InsertOne(ctx, BidMongo{
+				Id: uuid.NewString(), UserId: testOtherUserId, AuctionId: auction.Id, Amount: amount,
+			})
+			require.NoError(t, err)
+		}
+	}
+
+	auctionRepository.backfillFields(ctx)
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored bson.M
+			err := auctionRepository.Collection.FindOne(ctx, bson.M{"_id": auctionIds[i]}).Decode(&stored)
+			require.NoError(t, err)
+			require.EqualValues(t, tt.wantPrice, stored["current_price"])
+			require.EqualValues(t, tt.wantCount, stored["bid_count"])
+		})
+	}
+}
+
+func TestAuctionRepositoryFindAuctionsPages(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		sort string
+	}{
+		{name: "ending soonest", sort: entity.AuctionSortEndingSoonest},
+		{name: "newest", sort: entity.AuctionSortNewest},
+		{name: "price ascending", sort: entity.AuctionSortPriceAsc},
+		{name: "price descending", sort: entity.AuctionSortPriceDesc},
+		{name: "bid count", sort: entity.AuctionSortBidCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionRepository, _ := newTestAuctionRepository(t)
+			want := make(map[string]bool)
+			for i := 0; i < 5; i++ {
+				auction := newTestAuction(t, auctionRepository, entity.Active)
+				want[auction.Id] = true
+				// Equal sort values are paged by id.
+				totals := bson.M{"$set": bson.M{"current_price": float64(i % 2), "bid_count": int64(i % 2)}}
+				_, err := auctionRepository.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, totals)
+				require.NoError(t, err)
+			}
+
+			seen := make(map[string]bool)
+			page := entity.PageRequest{Limit: 2, Sort: tt.sort}
+			for {
+				auctions, err := auctionRepository.FindAuctions(ctx, entity.AuctionQuery{}, page)
+				require.NoError(t, err)
+				require.EqualValues(t, len(want), auctions.Total)
+				for _, auction := range auctions.Items {
+					require.False(t, seen[auction.Id], "auction %s listed twice", auction.Id)
+					seen[auction.Id] = true
+				}
+				if !auctions.HasMore {
+					break
+				}
+				page.Cursor = auctions.NextCursor
+			}
+			require.Equal(t, want, seen)
+		})
+	}
+}
+
+func TestAuctionRepositoryFindAuctionsByEndTime(t *testing.T) {
+	ctx := context.Background()
+	auctionRepository, _ := newTestAuctionRepository(t)
+	now := time.Now()
+
+	endingFirst := newTestAuction(t, auctionRepository, entity.Active)
+	restartTestAuction(t, auctionRepository, endingFirst.Id, now.Add(-50*time.Second), time.Time{})
+	extended := newTestAuction(t, auctionRepository, entity.Active)
+	restartTestAuction(t, auctionRepository, extended.Id, now.Add(-55*time.Second), now.Add(30*time.Second))
+	endingLast := newTestAuction(t, auctionRepository, entity.Active)
+
+	tests := []struct {
+		name  string
+		query entity.AuctionQuery
+		want  []string
+	}{
+		{name: "all", want: []string{endingFirst.Id, extended.Id, endingLast.Id}},
+		{name: "ending in range", query: entity.AuctionQuery{
+			EndingFrom: now.Add(20 * time.Second), EndingTo: now.Add(40 * time.Second),
+		}, want: []string{extended.Id}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := entity.PageRequest{Limit: 10, Sort: entity.AuctionSortEndingSoonest}
+			auctions, err := auctionRepository.FindAuctions(ctx, tt.query, page)
+			require.NoError(t, err)
+
+			var got []string
+			for _, auction := range auctions.Items {
+				got = append(got, auction.Id)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuctionRepositoryFindAuctionsStatuses(t *testing.T) {
+	ctx := context.Background()
+	auctionRepository, _ := newTestAuctionRepository(t)
+	byStatus := make(map[entity.AuctionStatus]string)
+	for _, status := range []entity.AuctionStatus{
+		entity.Active, entity.Completed, entity.Cancelled, entity.Draft, entity.PendingApproval, entity.Rejected,
+	} {
+		byStatus[status] = newTestAuction(t, auctionRepository, status).Id
+	}
+
+	tests := []struct {
+		name     string
+		statuses []entity.AuctionStatus
+		want     []entity.AuctionStatus
+	}{
+		{name: "public by default", want: []entity.AuctionStatus{entity.Active, entity.Completed, entity.Cancelled}},
+		{name: "active only", statuses: []entity.AuctionStatus{entity.Active}, want: []entity.AuctionStatus{entity.Active}},
+		{name: "drafts", statuses: []entity.AuctionStatus{entity.Draft}, want: []entity.AuctionStatus{entity.Draft}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := entity.PageRequest{Limit: 10, Sort: entity.AuctionSortNewest}
+			auctions, err := auctionRepository.FindAuctions(ctx, entity.AuctionQuery{Statuses: tt.statuses}, page)
+			require.NoError(t, err)
+
+			var want, got []string
+			for _, status := range tt.want {
+				want = append(want, byStatus[status])
+			}
+			for _, auction := range auctions.Items {
+				got = append(got, auction.Id)
+			}
+			require.ElementsMatch(t, want, got)
+		})
+	}
+}
+
+func TestAuctionRepositoryFindAuctionFacets(t *testing.T) {
+	ctx := context.Background()
+	auctionRepository, _ := newTestAuctionRepository(t)
+	skipWithoutFacets(t, auctionRepository.Collection.Database())
+
+	cars := []map[string]interface{}{
+		{"fuel": "petrol", "year": int64(1960)},
+		{"fuel": "petrol", "year": int64(1975)},
+		{"fuel": "diesel", "year": int64(1980)},
+		{"fuel": "electric", "year": int64(2020)},
+	}
+	for _, attributes := range cars {
+		auction := newTestAuction(t, auctionRepository, entity.Active)
+		update := bson.M{"$set": bson.M{"attributes": attributes}}
+		_, err := auctionRepository.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id}, update)
+		require.NoError(t, err)
+	}
+
+	definitions := []entity.AttributeDefinition{
+		{Key: "fuel", Type: entity.AttributeEnum, Options: []string{"petrol", "diesel", "electric"}},
+		{Key: "year", Type: entity.AttributeInteger},
+	}
+	before1990 := float64(1990)
+
+	tests := []struct {
+		name       string
+		filters    []entity.AttributeFilter
+		wantFuels  map[interface{}]int64
+		wantYearTo float64
+	}{
+		{
+			name:       "no filter",
+			wantFuels:  map[interface{}]int64{"petrol": 2, "diesel": 1, "electric": 1},
+			wantYearTo: 2020,
+		},
+		{
+			name:       "fuel filter keeps the other fuels",
+			filters:    []entity.AttributeFilter{{Key: "fuel", Values: []interface{}{"petrol"}}},
+			wantFuels:  map[interface{}]int64{"petrol": 2, "diesel": 1, "electric": 1},
+			wantYearTo: 1975,
+		},
+		{
+			name:       "year filter narrows the fuels",
+			filters:    []entity.AttributeFilter{{Key: "year", Max: &before1990}},
+			wantFuels:  map[interface{}]int64{"petrol": 2, "diesel": 1},
+			wantYearTo: 2020,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			facets, err := auctionRepository.FindAuctionFacets(ctx, entity.AuctionQuery{Attributes: tt.filters}, definitions)
+			require.NoError(t, err)
+			require.Len(t, facets, 2)
+
+			fuels := make(map[interface{}]int64)
+			for _, value := range facets[0].Values {
+				fuels[value.Value] = value.Count
+			}
+			require.Equal(t, tt.wantFuels, fuels)
+			require.NotNil(t, facets[1].Max)
+			require.Equal(t, tt.wantYearTo, *facets[1].Max)
+		})
+	}
+}
+
+func TestAuctionRepositoryCloserWaitsForExtensions(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		extendedUntil time.Duration
+		wantStatus    entity.AuctionStatus
+	}{
+		{name: "not extended", wantStatus: entity.Completed},
+		{name: "extension over", extendedUntil: -time.Second, wantStatus: entity.Completed},
+		{name: "extension running", extendedUntil: time.Minute, wantStatus: entity.Active},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidRepository, _ := newTestBidRepository(t)
+			auctionRepository := bidRepository.AuctionRepository
+
+			auction := newTestAuction(t, auctionRepository, entity.Active)
+			var extendedUntil time.Time
+			if tt.extendedUntil != 0 {
+				extendedUntil = time.Now().Add(tt.extendedUntil)
+			}
+			restartTestAuction(t, auctionRepository, auction.Id, time.Now().Add(-time.Hour), extendedUntil)
+
+			auctionRepository.closeExpiredAuctions(ctx, &queuedBids{bidRepository: bidRepository})
+
+			stored, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, stored.Status)
+		})
+	}
+}
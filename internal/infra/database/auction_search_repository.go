@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	AuctionSearchMongo struct {
+		AuctionMongo `bson:",inline"`
+		Score        float64 `bson:"score"`
+	}
+
+	AuctionSearchRepository struct {
+		Collection *mongo.Collection
+	}
+)
+
+func NewAuctionSearchRepository(database *mongo.Database) *AuctionSearchRepository {
+	repository := &AuctionSearchRepository{
+		Collection: database.Collection("auctions"),
+	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+// createIndexes builds the collection's text index. Matches in the product
+// name weigh most, then the category, then the description.
+func (sr *AuctionSearchRepository) createIndexes(ctx context.Context) {
+	index := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "product_name", Value: "text"},
+			{Key: "category", Value: "text"},
+			{Key: "description", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("auctions_text").
+			SetWeights(bson.D{
+				{Key: "product_name", Value: 10},
+				{Key: "category", Value: 5},
+				{Key: "description", Value: 1},
+			}),
+	}
+	if _, err := sr.Collection.Indexes().CreateOne(ctx, index); err != nil {
+		logger.Error("Error trying to create auctions text index", err)
+	}
+}
+
+func (sr *AuctionSearchRepository) SearchAuctions(
+	ctx context.Context,
+	text string,
+	limit int) ([]entity.AuctionSearchHit, error) {
+	filter := bson.M{
+		"$text":  bson.M{"$search": text},
+		"status": bson.M{"$nin": bson.A{entity.Draft, entity.PendingApproval, entity.Rejected}},
+	}
+	score := bson.M{"$meta": "textScore"}
+
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := sr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error searching auctions", err)
+		return nil, internal_error.NewInternalServerError("Error searching auctions")
+	}
+	defer cursor.Close(ctx)
+
+	var results []AuctionSearchMongo
+	if err := cursor.All(ctx, &results); err != nil {
+		logger.Error("Error decoding auction search results", err)
+		return nil, internal_error.NewInternalServerError("Error decoding auction search results")
+	}
+
+	hits := make([]entity.AuctionSearchHit, 0, len(results))
+	for _, result := range results {
+		hits = append(hits, entity.AuctionSearchHit{
+			Auction: *result.AuctionMongo.toEntity(),
+			Score:   result.Score,
+		})
+	}
+
+	return hits, nil
+}
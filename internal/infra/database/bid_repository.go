@@ -7,8 +7,6 @@ import (
 	"fullcycle-auction_go/internal/entity"
 	"fullcycle-auction_go/internal/internal_error"
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
-	"os"
 	"sync"
 	"time"
 
@@ -25,28 +23,36 @@ type (
 	}
 
 	BidRepository struct {
-		Collection            *mongo.Collection
-		AuctionRepository     *AuctionRepository
-		auctionInterval       time.Duration
-		auctionStatusMap      map[string]entity.AuctionStatus
-		auctionEndTimeMap     map[string]time.Time
-		auctionStatusMapMutex *sync.Mutex
-		auctionEndTimeMutex   *sync.Mutex
+		Collection        *mongo.Collection
+		AuctionRepository *AuctionRepository
 	}
 )
 
+var bidSorts = map[string]sortSpec{
+	entity.BidSortNewest: {field: "timestamp", descending: true},
+	entity.BidSortAmount: {field: "amount", descending: true},
+}
+
 func NewBidRepository(database *mongo.Database, auctionRepository *AuctionRepository) *BidRepository {
-	return &BidRepository{
-		auctionInterval:       getAuctionInterval(),
-		auctionStatusMap:      make(map[string]entity.AuctionStatus),
-		auctionEndTimeMap:     make(map[string]time.Time),
-		auctionStatusMapMutex: &sync.Mutex{},
-		auctionEndTimeMutex:   &sync.Mutex{},
-		Collection:            database.Collection("bids"),
-		AuctionRepository:     auctionRepository,
+	repository := &BidRepository{
+		Collection:        database.Collection("bids"),
+		AuctionRepository: auctionRepository,
+	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+func (bd *BidRepository) createIndexes(ctx context.Context) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "auction_id", Value: 1}, {Key: "timestamp", Value: -1}, {Key: "_id", Value: 1}}},
+		{Keys: bson.D{{Key: "auction_id", Value: 1}, {Key: "amount", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	if _, err := bd.Collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Error("Error trying to create bids indexes", err)
 	}
 }
 
+// CreateBid stores the bids of a batch, dropping those the auction no longer takes.
 func (bd *BidRepository) CreateBid(ctx context.Context, bidEntities []entity.Bid) error {
 	var wg sync.WaitGroup
 	for _, bid := range bidEntities {
@@ -54,119 +60,124 @@ func (bd *BidRepository) CreateBid(ctx context.Context, bidEntities []entity.Bid
 		go func(bidValue entity.Bid) {
 			defer wg.Done()
 
-			bd.auctionStatusMapMutex.Lock()
-			auctionStatus, okStatus := bd.auctionStatusMap[bidValue.AuctionId]
-			bd.auctionStatusMapMutex.Unlock()
-
-			bd.auctionEndTimeMutex.Lock()
-			auctionEndTime, okEndTime := bd.auctionEndTimeMap[bidValue.AuctionId]
-			bd.auctionEndTimeMutex.Unlock()
-
-			bidMongo := &BidMongo{
-				Id:        bidValue.Id,
-				UserId:    bidValue.UserId,
-				AuctionId: bidValue.AuctionId,
-				Amount:    bidValue.Amount,
-				Timestamp: bidValue.Timestamp.Unix(),
-			}
-
-			if okEndTime && okStatus {
-				now := time.Now()
-				if auctionStatus == entity.Completed || now.After(auctionEndTime) {
-					return
-				}
+			bd.insertBid(ctx, newBidMongo(&bidValue))
+		}(bid)
+	}
+	wg.Wait()
+	return nil
+}
 
-				if _, err := bd.Collection.InsertOne(ctx, bidMongo); err != nil {
-					logger.Error("Error trying to insert bid", err)
-					return
-				}
+// insertBid stores a bid before counting it on the auction, deleting it again when not counted.
+func (bd *BidRepository) insertBid(ctx context.Context, bidMongo *BidMongo) {
+	if _, err := bd.Collection.InsertOne(ctx, bidMongo); err != nil {
+		logger.Error("Error trying to insert bid", err)
+		return
+	}
 
-				return
-			}
+	event := entity.NewAuctionEvent(entity.EventBidAccepted, bidMongo.AuctionId)
+	event.Bid = bidMongo.toEntity()
 
-			auction, err := bd.AuctionRepository.FindAuctionById(ctx, bidValue.AuctionId)
-			if err != nil {
-				logger.Error("Error trying to find auction by id", err)
-				return
-			}
-			if auction.Status == entity.Completed {
-				return
-			}
+	recorded, extended, err := bd.AuctionRepository.recordBid(ctx, &event)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to record bid on auction %s", bidMongo.AuctionId), err)
+		bd.deleteBid(ctx, bidMongo.Id)
+		return
+	}
+	if !recorded {
+		logger.Info(fmt.Sprintf("Dropping bid %s, auction %s no longer takes bids", bidMongo.Id, bidMongo.AuctionId))
+		bd.deleteBid(ctx, bidMongo.Id)
+		return
+	}
 
-			bd.auctionStatusMapMutex.Lock()
-			bd.auctionStatusMap[bidValue.AuctionId] = auction.Status
-			bd.auctionStatusMapMutex.Unlock()
+	bd.AuctionRepository.events.Publish(ctx, event)
 
-			bd.auctionEndTimeMutex.Lock()
-			bd.auctionEndTimeMap[bidValue.AuctionId] = auction.Timestamp.Add(bd.auctionInterval)
-			bd.auctionEndTimeMutex.Unlock()
+	if extended != nil {
+		extendedEvent := entity.NewAuctionEvent(entity.EventAuctionExtended, extended.Id)
+		extendedEvent.Auction = extended
+		extendedEvent.EndsAt = extended.EndsAt(bd.AuctionRepository.auctionDuration)
+		bd.AuctionRepository.events.Publish(ctx, extendedEvent)
+	}
+}
 
-			if _, err := bd.Collection.InsertOne(ctx, bidMongo); err != nil {
-				logger.Error("Error trying to insert bid", err)
-				return
-			}
-		}(bid)
+func (bd *BidRepository) deleteBid(ctx context.Context, bidId string) {
+	if _, err := bd.Collection.DeleteOne(ctx, bson.M{"_id": bidId}); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to delete unrecorded bid %s", bidId), err)
 	}
-	wg.Wait()
-	return nil
 }
 
 func (bd *BidRepository) FindBidByAuctionId(
-	ctx context.Context, auctionId string) ([]entity.Bid, error) {
-	filter := bson.M{"auctionId": auctionId}
+	ctx context.Context,
+	auctionId string,
+	page entity.PageRequest) (*entity.Page[entity.Bid], error) {
+	filter := bson.M{"auction_id": auctionId}
 
-	cursor, err := bd.Collection.Find(ctx, filter)
+	bidsPage, err := findPage(ctx, bd.Collection, filter, page, bidSorts,
+		func(bid *BidMongo) (float64, string) {
+			if page.Sort == entity.BidSortAmount {
+				return bid.Amount, bid.Id
+			}
+			return float64(bid.Timestamp), bid.Id
+		})
 	if err != nil {
-		logger.Error(fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
-		return nil, internal_error.NewInternalServerError(fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
-	}
-
-	var bids []BidMongo
-	if err := cursor.All(ctx, &bids); err != nil {
-		logger.Error(fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId), err)
-		return nil, internal_error.NewInternalServerError(fmt.Sprintf("Error trying to find bids by auctionId %s", auctionId))
+		return nil, err
 	}
 
-	var bidEntities []entity.Bid
-	for _, bidMongo := range bids {
-		bidEntities = append(bidEntities, entity.Bid{
-			Id:        bidMongo.Id,
-			UserId:    bidMongo.UserId,
-			AuctionId: bidMongo.AuctionId,
-			Amount:    bidMongo.Amount,
-			Timestamp: time.Unix(bidMongo.Timestamp, 0),
-		})
+	bidEntities := make([]entity.Bid, 0, len(bidsPage.Items))
+	for _, bidMongo := range bidsPage.Items {
+		bidEntities = append(bidEntities, *bidMongo.toEntity())
 	}
 
-	return bidEntities, nil
+	return &entity.Page[entity.Bid]{
+		Items:      bidEntities,
+		NextCursor: bidsPage.NextCursor,
+		HasMore:    bidsPage.HasMore,
+		Total:      bidsPage.Total,
+	}, nil
 }
 
 func (bd *BidRepository) FindWinningBidByAuctionId(
 	ctx context.Context, auctionId string) (*entity.Bid, error) {
-	filter := bson.M{"auction_id": auctionId}
+	auction, err := bd.AuctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+	if auction.Status == entity.Cancelled {
+		return nil, internal_error.NewNotFoundError("Cancelled auctions have no winner")
+	}
 
-	var bidMongo BidMongo
-	opts := options.FindOne().SetSort(bson.D{{"amount", -1}})
-	if err := bd.Collection.FindOne(ctx, filter, opts).Decode(&bidMongo); err != nil {
+	winningBid, err := bd.AuctionRepository.findHighestBid(ctx, auctionId)
+	if err != nil {
 		logger.Error("Error trying to find the auction winner", err)
 		return nil, internal_error.NewInternalServerError("Error trying to find the auction winner")
 	}
+	if winningBid == nil {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
 
-	return &entity.Bid{
-		Id:        bidMongo.Id,
-		UserId:    bidMongo.UserId,
-		AuctionId: bidMongo.AuctionId,
-		Amount:    bidMongo.Amount,
-		Timestamp: time.Unix(bidMongo.Timestamp, 0),
-	}, nil
+	return winningBid, nil
 }
 
-func getAuctionInterval() time.Duration {
-	auctionInterval := os.Getenv("AUCTION_INTERVAL")
-	duration, err := time.ParseDuration(auctionInterval)
-	if err != nil {
-		return time.Minute * 5
+func newBidMongo(bid *entity.Bid) *BidMongo {
+	return &BidMongo{
+		Id:        bid.Id,
+		UserId:    bid.UserId,
+		AuctionId: bid.AuctionId,
+		Amount:    bid.Amount,
+		Timestamp: bid.Timestamp.Unix(),
 	}
+}
 
-	return duration
+// toEntity converts a stored bid, which may be missing (nil) where it is
+// optional.
+func (bm *BidMongo) toEntity() *entity.Bid {
+	if bm == nil {
+		return nil
+	}
+	return &entity.Bid{
+		Id:        bm.Id,
+		UserId:    bm.UserId,
+		AuctionId: bm.AuctionId,
+		Amount:    bm.Amount,
+		Timestamp: time.Unix(bm.Timestamp, 0),
+	}
 }
@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+	"time"
+)
+
+func TestBidRepositoryCreateBidChecksAuctionStatus(t *testing.T) {
+	database := newTestDatabase(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		status   entity.AuctionStatus
+		startAt  time.Duration
+		recorded bool
+	}{
+		{name: "active auction", status: entity.Active, recorded: true},
+		{name: "cancelled auction", status: entity.Cancelled},
+		{name: "completed auction", status: entity.Completed},
+		{name: "active auction past its end", status: entity.Active, startAt: -time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			auctionRepository := &AuctionRepository{
+				Collection:      database.Collection("auctions"),
+				bidCollection:   database.Collection("bids"),
+				events:          publisher,
+				auctionDuration: time.Minute,
+			}
+			bidRepository := &BidRepository{
+				Collection:        database.Collection("bids"),
+				AuctionRepository: auctionRepository,
+			}
+
+			auction := newTestAuction(t, auctionRepository, tt.status)
+			restartTestAuction(t, auctionRepository, auction.Id, time.Now().Add(tt.startAt), time.Time{})
+
+			bid, err := entity.CreateBid(uuid.New().String(), auction.Id, 10)
+			require.NoError(t, err)
+			require.NoError(t, bidRepository.CreateBid(ctx, []entity.Bid{*bid}))
+
+			stored, err := bidRepository.Collection.CountDocuments(ctx, bson.M{"auction_id": auction.Id})
+			require.NoError(t, err)
+			updated, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+			require.NoError(t, err)
+
+			if tt.recorded {
+				require.EqualValues(t, 1, stored)
+				require.EqualValues(t, 1, updated.BidCount)
+				require.Len(t, publisher.ofType(entity.EventBidAccepted), 1)
+			} else {
+				require.Zero(t, stored)
+				require.Zero(t, updated.BidCount)
+				require.Empty(t, publisher.ofType(entity.EventBidAccepted))
+			}
+		})
+	}
+}
+
+func TestBidRepositoryCreateBidNotStored(t *testing.T) {
+	bidRepository, publisher := newTestBidRepository(t)
+	ctx := context.Background()
+	auction := newTestAuction(t, bidRepository.AuctionRepository, entity.Active)
+
+	bid, err := entity.CreateBid(testBidderId("a"), auction.Id, 10)
+	require.NoError(t, err)
+	_, err = bidRepository.Collection.InsertOne(ctx, newBidMongo(bid))
+	require.NoError(t, err)
+
+	require.NoError(t, bidRepository.CreateBid(ctx, []entity.Bid{*bid}))
+
+	updated, err := bidRepository.AuctionRepository.FindAuctionById(ctx, auction.Id)
+	require.NoError(t, err)
+	require.Zero(t, updated.BidCount)
+	require.Zero(t, updated.CurrentPrice)
+	require.Empty(t, publisher.ofType(entity.EventBidAccepted))
+}
+
+func newTestBidRepository(t *testing.T) (*BidRepository, *recordingPublisher) {
+	t.Helper()
+	database := newTestDatabase(t)
+
+	publisher := &recordingPublisher{}
+	auctionRepository := &AuctionRepository{
+		Collection:      database.Collection("auctions"),
+		bidCollection:   database.Collection("bids"),
+		events:          publisher,
+		auctionDuration: time.Minute,
+	}
+	return &BidRepository{
+		Collection:        database.Collection("bids"),
+		AuctionRepository: auctionRepository,
+	}, publisher
+}
+
+func TestBidRepositoryCreateBidLeading(t *testing.T) {
+	type bid struct {
+		userId      string
+		amount      float64
+		wantLeading bool
+		wantOutbid  string
+	}
+
+	tests := []struct {
+		name       string
+		bids       []bid
+		wantWinner string
+	}{
+		{name: "first bid leads", wantWinner: "a", bids: []bid{
+			{userId: "a", amount: 10, wantLeading: true},
+		}},
+		{name: "higher bid outbids", wantWinner: "b", bids: []bid{
+			{userId: "a", amount: 10, wantLeading: true},
+			{userId: "b", amount: 12, wantLeading: true, wantOutbid: "a"},
+		}},
+		{name: "lower bid does not lead", wantWinner: "a", bids: []bid{
+			{userId: "a", amount: 10, wantLeading: true},
+			{userId: "b", amount: 8},
+		}},
+		{name: "equal bid does not lead", wantWinner: "a", bids: []bid{
+			{userId: "a", amount: 10, wantLeading: true},
+			{userId: "b", amount: 10},
+		}},
+		{name: "leader raising their own bid", wantWinner: "a", bids: []bid{
+			{userId: "a", amount: 10, wantLeading: true},
+			{userId: "a", amount: 15, wantLeading: true, wantOutbid: "a"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidRepository, publisher := newTestBidRepository(t)
+			ctx := context.Background()
+			auction := newTestAuction(t, bidRepository.AuctionRepository, entity.Active)
+
+			for i, step := range tt.bids {
+				created, err := entity.CreateBid(testBidderId(step.userId), auction.Id, step.amount)
+				require.NoError(t, err)
+				require.NoError(t, bidRepository.CreateBid(ctx, []entity.Bid{*created}))
+
+				events := publisher.ofType(entity.EventBidAccepted)
+				require.Len(t, events, i+1)
+				event := events[i]
+				require.Equal(t, step.wantLeading, event.Leading, "bid %d", i)
+				if step.wantOutbid == "" {
+					require.Nil(t, event.PreviousBid, "bid %d", i)
+				} else {
+					require.Equal(t, testBidderId(step.wantOutbid), event.PreviousBid.UserId, "bid %d", i)
+				}
+			}
+
+			winner, err := bidRepository.FindWinningBidByAuctionId(ctx, auction.Id)
+			require.NoError(t, err)
+			require.Equal(t, testBidderId(tt.wantWinner), winner.UserId)
+		})
+	}
+}
+
+// testBidderId gives the bidders of a test short names.
+func testBidderId(name string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String()
+}
+
+// TestBidRepositoryCreateBidConcurrentLeaders writes a batch of equal bids at
+// once: only one may be told it leads, and it must be the winner.
+func TestBidRepositoryCreateBidConcurrentLeaders(t *testing.T) {
+	bidRepository, publisher := newTestBidRepository(t)
+	skipWithoutAtomicUpdates(t, bidRepository.Collection.Database())
+	ctx := context.Background()
+	auction := newTestAuction(t, bidRepository.AuctionRepository, entity.Active)
+
+	var bids []entity.Bid
+	for i := 0; i < 20; i++ {
+		created, err := entity.CreateBid(uuid.New().String(), auction.Id, 10)
+		require.NoError(t, err)
+		bids = append(bids, *created)
+	}
+	require.NoError(t, bidRepository.CreateBid(ctx, bids))
+
+	var leaders []entity.AuctionEvent
+	for _, event := range publisher.ofType(entity.EventBidAccepted) {
+		if event.Leading {
+			leaders = append(leaders, event)
+		}
+	}
+	require.Len(t, leaders, 1)
+
+	winner, err := bidRepository.FindWinningBidByAuctionId(ctx, auction.Id)
+	require.NoError(t, err)
+	require.Equal(t, leaders[0].Bid.Id, winner.Id)
+}
+
+// TestBidRepositoryCreateBidWithoutStoredLeader covers auctions bid on before
+// the leading bid was stored with them.
+func TestBidRepositoryCreateBidWithoutStoredLeader(t *testing.T) {
+	bidRepository, publisher := newTestBidRepository(t)
+	ctx := context.Background()
+	auction := newTestAuction(t, bidRepository.AuctionRepository, entity.Active)
+
+	earlier, err := entity.CreateBid(testBidderId("a"), auction.Id, 10)
+	require.NoError(t, err)
+	_, err = bidRepository.Collection.InsertOne(ctx, &BidMongo{
+		Id: earlier.Id, UserId: earlier.UserId, AuctionId: auction.Id,
+		Amount: earlier.Amount, Timestamp: earlier.Timestamp.Unix(),
+	})
+	require.NoError(t, err)
+	_, err = bidRepository.AuctionRepository.Collection.UpdateOne(ctx, bson.M{"_id": auction.Id},
+		bson.M{"$set": bson.M{"current_price": 10, "bid_count": 1}})
+	require.NoError(t, err)
+
+	winner, err := bidRepository.FindWinningBidByAuctionId(ctx, auction.Id)
+	require.NoError(t, err)
+	require.Equal(t, earlier.Id, winner.Id)
+
+	later, err := entity.CreateBid(testBidderId("b"), auction.Id, 11)
+	require.NoError(t, err)
+	require.NoError(t, bidRepository.CreateBid(ctx, []entity.Bid{*later}))
+
+	events := publisher.ofType(entity.EventBidAccepted)
+	require.Len(t, events, 1)
+	require.True(t, events[0].Leading)
+	require.Equal(t, earlier.Id, events[0].PreviousBid.Id)
+}
+
+func TestBidRepositoryCreateBidExtendsAuction(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		window          time.Duration
+		startedAgo      time.Duration
+		extendedFor     time.Duration
+		wantRecorded    bool
+		wantExtendedFor time.Duration
+	}{
+		{name: "early bid", window: 10 * time.Second, startedAgo: 10 * time.Second, wantRecorded: true},
+		{name: "bid inside the window", window: 10 * time.Second, startedAgo: 55 * time.Second,
+			wantRecorded: true, wantExtendedFor: 10 * time.Second},
+		{name: "bid inside the window of an extension", window: 10 * time.Second, startedAgo: 2 * time.Minute,
+			extendedFor: 5 * time.Second, wantRecorded: true, wantExtendedFor: 10 * time.Second},
+		{name: "extension disabled", startedAgo: 55 * time.Second, wantRecorded: true},
+		{name: "bid after the end", window: 10 * time.Second, startedAgo: 2 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidRepository, publisher := newTestBidRepository(t)
+			auctionRepository := bidRepository.AuctionRepository
+			auctionRepository.extensionWindow = tt.window
+
+			auction := newTestAuction(t, auctionRepository, entity.Active)
+			var extendedUntil time.Time
+			if tt.extendedFor > 0 {
+				extendedUntil = time.Now().Add(tt.extendedFor)
+			}
+			restartTestAuction(t, auctionRepository, auction.Id, time.Now().Add(-tt.startedAgo), extendedUntil)
+
+			bid, err := entity.CreateBid(testBidderId("a"), auction.Id, 10)
+			require.NoError(t, err)
+			require.NoError(t, bidRepository.CreateBid(ctx, []entity.Bid{*bid}))
+
+			stored, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+			require.NoError(t, err)
+			extended := publisher.ofType(entity.EventAuctionExtended)
+
+			if !tt.wantRecorded {
+				require.Zero(t, stored.BidCount)
+				require.Empty(t, extended)
+				return
+			}
+			require.EqualValues(t, 1, stored.BidCount)
+			if tt.wantExtendedFor == 0 {
+				require.Empty(t, extended)
+				return
+			}
+
+			wantUntil := bid.Timestamp.Add(tt.wantExtendedFor).Unix()
+			require.Equal(t, wantUntil, stored.ExtendedUntil.Unix())
+			require.Len(t, extended, 1)
+			require.Equal(t, wantUntil, extended[0].EndsAt.Unix())
+			require.Equal(t, auction.Id, extended[0].Auction.Id)
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"slices"
+	"time"
+)
+
+type (
+	CategoryMongo struct {
+		Id         string                     `bson:"_id"`
+		Name       string                     `bson:"name"`
+		Slug       string                     `bson:"slug"`
+		ParentId   string                     `bson:"parent_id,omitempty"`
+		Ancestors  []string                   `bson:"ancestors"`
+		Attributes []AttributeDefinitionMongo `bson:"attributes"`
+		CreatedAt  int64                      `bson:"created_at"`
+	}
+
+	AttributeDefinitionMongo struct {
+		Key      string               `bson:"key"`
+		Label    string               `bson:"label,omitempty"`
+		Type     entity.AttributeType `bson:"type"`
+		Required bool                 `bson:"required"`
+		Options  []string             `bson:"options,omitempty"`
+	}
+
+	CategoryRepository struct {
+		Collection *mongo.Collection
+	}
+)
+
+func NewCategoryRepository(database *mongo.Database) *CategoryRepository {
+	repository := &CategoryRepository{
+		Collection: database.Collection("categories"),
+	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+func (cr *CategoryRepository) createIndexes(ctx context.Context) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "ancestors", Value: 1}}},
+	}
+	if _, err := cr.Collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Error("Error trying to create categories indexes", err)
+	}
+}
+
+func (cr *CategoryRepository) CreateCategory(ctx context.Context, category *entity.Category) error {
+	if _, err := cr.Collection.InsertOne(ctx, newCategoryMongo(category)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewConflictError(fmt.Sprintf("Category slug %s is already taken", category.Slug))
+		}
+
+		logger.Error("Error trying to insert category", err)
+		return internal_error.NewInternalServerError("Error trying to insert category")
+	}
+
+	return nil
+}
+
+// UpdateCategory saves the category and rewrites the ancestors of its subtree
+// in a single update, so a move cannot be left half done.
+func (cr *CategoryRepository) UpdateCategory(ctx context.Context, category *entity.Category) error {
+	categoryMongo := newCategoryMongo(category)
+	isCategory := bson.M{"$eq": bson.A{"$_id", category.Id}}
+
+	var parentId interface{} = "$$REMOVE"
+	if categoryMongo.ParentId != "" {
+		parentId = bson.M{"$literal": categoryMongo.ParentId}
+	}
+
+	// Descendants keep the part of their ancestors below the category and get
+	// the new path of the category in front of it.
+	path := append(slices.Clone(categoryMongo.Ancestors), category.Id)
+	belowCategory := bson.M{"$slice": bson.A{
+		"$ancestors",
+		bson.M{"$add": bson.A{bson.M{"$indexOfArray": bson.A{"$ancestors", category.Id}}, 1}},
+		bson.M{"$max": bson.A{bson.M{"$size": "$ancestors"}, 1}},
+	}}
+
+	update := bson.A{bson.M{"$set": bson.M{
+		"name":       bson.M{"$cond": bson.A{isCategory, bson.M{"$literal": categoryMongo.Name}, "$name"}},
+		"parent_id":  bson.M{"$cond": bson.A{isCategory, parentId, "$parent_id"}},
+		"attributes": bson.M{"$cond": bson.A{isCategory, bson.M{"$literal": categoryMongo.Attributes}, "$attributes"}},
+		"ancestors": bson.M{"$cond": bson.A{
+			isCategory,
+			bson.M{"$literal": categoryMongo.Ancestors},
+			bson.M{"$concatArrays": bson.A{bson.M{"$literal": path}, belowCategory}},
+		}},
+	}}}
+
+	filter := bson.M{"$or": bson.A{bson.M{"_id": category.Id}, bson.M{"ancestors": category.Id}}}
+	result, err := cr.Collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update category %s", category.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update category")
+	}
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("Category not found with this id = %s", category.Id))
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) DeleteCategory(ctx context.Context, categoryId string) error {
+	result, err := cr.Collection.DeleteOne(ctx, bson.M{"_id": categoryId})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to delete category %s", categoryId), err)
+		return internal_error.NewInternalServerError("Error trying to delete category")
+	}
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("Category not found with this id = %s", categoryId))
+	}
+
+	return nil
+}
+
+func (cr *CategoryRepository) FindCategoryById(ctx context.Context, categoryId string) (*entity.Category, error) {
+	return cr.findCategory(ctx, bson.M{"_id": categoryId},
+		fmt.Sprintf("Category not found with this id = %s", categoryId))
+}
+
+func (cr *CategoryRepository) FindCategoryBySlug(ctx context.Context, slug string) (*entity.Category, error) {
+	return cr.findCategory(ctx, bson.M{"slug": slug},
+		fmt.Sprintf("Category not found with this slug = %s", slug))
+}
+
+func (cr *CategoryRepository) findCategory(
+	ctx context.Context, filter bson.M, notFoundMessage string) (*entity.Category, error) {
+	var categoryMongo CategoryMongo
+	if err := cr.Collection.FindOne(ctx, filter).Decode(&categoryMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(notFoundMessage)
+		}
+
+		logger.Error("Error trying to find category", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find category")
+	}
+
+	return categoryMongo.toEntity(), nil
+}
+
+func (cr *CategoryRepository) FindCategories(ctx context.Context) ([]entity.Category, error) {
+	return cr.findCategories(ctx, bson.M{})
+}
+
+func (cr *CategoryRepository) FindDescendants(ctx context.Context, categoryId string) ([]entity.Category, error) {
+	return cr.findCategories(ctx, bson.M{"ancestors": categoryId})
+}
+
+func (cr *CategoryRepository) findCategories(ctx context.Context, filter bson.M) ([]entity.Category, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := cr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error finding categories", err)
+		return nil, internal_error.NewInternalServerError("Error finding categories")
+	}
+	defer cursor.Close(ctx)
+
+	var categoriesMongo []CategoryMongo
+	if err := cursor.All(ctx, &categoriesMongo); err != nil {
+		logger.Error("Error decoding categories", err)
+		return nil, internal_error.NewInternalServerError("Error decoding categories")
+	}
+
+	categories := make([]entity.Category, 0, len(categoriesMongo))
+	for _, categoryMongo := range categoriesMongo {
+		categories = append(categories, *categoryMongo.toEntity())
+	}
+
+	return categories, nil
+}
+
+func newCategoryMongo(category *entity.Category) *CategoryMongo {
+	ancestors := category.Ancestors
+	if ancestors == nil {
+		ancestors = []string{}
+	}
+
+	attributes := make([]AttributeDefinitionMongo, 0, len(category.Attributes))
+	for _, attribute := range category.Attributes {
+		attributes = append(attributes, AttributeDefinitionMongo{
+			Key:      attribute.Key,
+			Label:    attribute.Label,
+			Type:     attribute.Type,
+			Required: attribute.Required,
+			Options:  attribute.Options,
+		})
+	}
+
+	return &CategoryMongo{
+		Id:         category.Id,
+		Name:       category.Name,
+		Slug:       category.Slug,
+		ParentId:   category.ParentId,
+		Ancestors:  ancestors,
+		Attributes: attributes,
+		CreatedAt:  category.CreatedAt.Unix(),
+	}
+}
+
+func (cm *CategoryMongo) toEntity() *entity.Category {
+	category := &entity.Category{
+		Id:        cm.Id,
+		Name:      cm.Name,
+		Slug:      cm.Slug,
+		ParentId:  cm.ParentId,
+		Ancestors: cm.Ancestors,
+		CreatedAt: time.Unix(cm.CreatedAt, 0),
+	}
+	for _, attribute := range cm.Attributes {
+		category.Attributes = append(category.Attributes, entity.AttributeDefinition{
+			Key:      attribute.Key,
+			Label:    attribute.Label,
+			Type:     attribute.Type,
+			Required: attribute.Required,
+			Options:  attribute.Options,
+		})
+	}
+	return category
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCategoryRepositoryUpdateCategory(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// moveTo names the new parent of cameras, empty for the root.
+		moveTo string
+	}{
+		{name: "moved below another category", moveTo: "toys"},
+		{name: "moved to the root"},
+		{name: "renamed in place", moveTo: "electronics"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			database := newTestDatabase(t)
+			skipWithoutUpdatePipelines(t, database)
+			categoryRepository := NewCategoryRepository(database)
+
+			categories := make(map[string]*entity.Category)
+			create := func(name string, parent *entity.Category) *entity.Category {
+				category, err := entity.CreateCategory(name, "", parent, nil)
+				require.NoError(t, err)
+				require.NoError(t, categoryRepository.CreateCategory(ctx, category))
+				categories[category.Slug] = category
+				return category
+			}
+			electronics := create("Electronics", nil)
+			cameras := create("Cameras", electronics)
+			lenses := create("Lenses", cameras)
+			create("Prime", lenses)
+			create("Toys", nil)
+
+			var parent *entity.Category
+			if tt.moveTo != "" {
+				parent = categories[tt.moveTo]
+			}
+			require.NoError(t, cameras.MoveTo(parent))
+			require.NoError(t, cameras.Rename("Photo cameras"))
+
+			require.NoError(t, categoryRepository.UpdateCategory(ctx, cameras))
+
+			stored, err := categoryRepository.FindCategoryById(ctx, cameras.Id)
+			require.NoError(t, err)
+			require.Equal(t, "Photo cameras", stored.Name)
+			require.Equal(t, cameras.ParentId, stored.ParentId)
+			require.Equal(t, cameras.Ancestors, nilIfEmpty(stored.Ancestors))
+
+			path := append(cameras.Ancestors, cameras.Id)
+			for slug, wantAncestors := range map[string][]string{
+				"lenses": path,
+				"prime":  append(path, lenses.Id),
+			} {
+				descendant, err := categoryRepository.FindCategoryBySlug(ctx, slug)
+				require.NoError(t, err)
+				require.Equal(t, wantAncestors, descendant.Ancestors, slug)
+				require.Equal(t, categories[slug].Name, descendant.Name)
+			}
+
+			toys, err := categoryRepository.FindCategoryBySlug(ctx, "toys")
+			require.NoError(t, err)
+			require.Empty(t, toys.Ancestors)
+		})
+	}
+}
+
+func TestCategoryRepositoryUpdateCategoryNotFound(t *testing.T) {
+	database := newTestDatabase(t)
+	skipWithoutUpdatePipelines(t, database)
+	categoryRepository := NewCategoryRepository(database)
+	category, err := entity.CreateCategory("Cameras", "", nil, nil)
+	require.NoError(t, err)
+
+	err = categoryRepository.UpdateCategory(context.Background(), category)
+
+	require.Error(t, err)
+}
+
+func nilIfEmpty(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testMongoURL is shared by the tests of the package: MONGODB_TEST_URL when
+// set, otherwise a container started for the first test needing it.
+var (
+	testMongoURL     string
+	testMongoErr     error
+	testMongoStarted sync.Once
+)
+
+// newTestDatabase hands out an empty database dropped when the test ends.
+// Tests are skipped when no MongoDB is reachable.
+func newTestDatabase(t *testing.T) *mongo.Database {
+	t.Helper()
+	ctx := context.Background()
+
+	testMongoStarted.Do(func() {
+		if testMongoURL = os.Getenv("MONGODB_TEST_URL"); testMongoURL != "" {
+			return
+		}
+
+		testMongoURL, testMongoErr = startMongoContainer(ctx)
+	})
+	if testMongoErr != nil {
+		t.Skipf("MongoDB is not available: %s", testMongoErr)
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(testMongoURL))
+	require.NoError(t, err)
+	if err := client.Ping(ctx, nil); err != nil {
+		t.Skipf("MongoDB is not available: %s", err)
+	}
+
+	database := client.Database("test_" + uuid.New().String()[:8])
+	t.Cleanup(func() {
+		_ = database.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+	return database
+}
+
+// startMongoContainer runs MongoDB in Docker. testcontainers panics when it
+// finds no Docker host, which is reported as an error instead.
+func startMongoContainer(ctx context.Context) (url string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	container, err := mongodb.Run(ctx, "mongo:6")
+	if err != nil {
+		return "", err
+	}
+	return container.ConnectionString(ctx)
+}
+
+// recordingPublisher keeps the published events for the test to inspect.
+type recordingPublisher struct {
+	events []entity.AuctionEvent
+	mutex  sync.Mutex
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, event entity.AuctionEvent) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *recordingPublisher) ofType(eventType entity.AuctionEventType) []entity.AuctionEvent {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var events []entity.AuctionEvent
+	for _, event := range p.events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+const testOtherUserId = "1b7e2f90-3c4d-4e5f-8a6b-7c8d9e0f1a2b"
+
+// newTestAuction stores an auction in the given status, started now.
+func newTestAuction(t *testing.T, auctionRepository *AuctionRepository, status entity.AuctionStatus) *entity.Auction {
+	t.Helper()
+
+	auction, err := entity.CreateAuction(uuid.New().String(), "Camera", "cameras", "A camera in good shape",
+		entity.Used, nil, 0, entity.RelistRule{})
+	require.NoError(t, err)
+	auction.Status = status
+	require.NoError(t, auctionRepository.CreateAuction(context.Background(), auction))
+	return auction
+}
+
+// restartTestAuction moves the start and extension of a stored auction, and
+// its end time with them.
+func restartTestAuction(
+	t *testing.T,
+	auctionRepository *AuctionRepository,
+	auctionId string,
+	start, extendedUntil time.Time,
+) {
+	t.Helper()
+
+	auction := &entity.Auction{Timestamp: start, ExtendedUntil: extendedUntil}
+	set := bson.M{"timestamp": start.Unix(), "ends_at": auctionRepository.endsAt(auction)}
+	if !extendedUntil.IsZero() {
+		set["extended_until"] = extendedUntil.Unix()
+	}
+	_, err := auctionRepository.Collection.UpdateOne(context.Background(), bson.M{"_id": auctionId}, bson.M{"$set": set})
+	require.NoError(t, err)
+}
+
+// queuedBids stands in for the bid batch, storing its bids when flushed.
+type queuedBids struct {
+	bidRepository *BidRepository
+	bids          []entity.Bid
+}
+
+func (q *queuedBids) FlushBids(ctx context.Context) error {
+	err := q.bidRepository.CreateBid(ctx, q.bids)
+	q.bids = nil
+	return err
+}
+
+// requireErrorKind checks err is an internal error of the given kind.
+func requireErrorKind(t *testing.T, err error, kind string) {
+	t.Helper()
+
+	var internalError *internal_error.InternalError
+	require.ErrorAs(t, err, &internalError)
+	require.Equal(t, kind, internalError.Err)
+}
+
+// skipWithoutUpdatePipelines skips tests of updates made of aggregation
+// pipelines, which MongoDB has since 4.2 but some compatible servers lack.
+func skipWithoutUpdatePipelines(t *testing.T, database *mongo.Database) {
+	t.Helper()
+
+	skipUnlessSupported(t, database, "Update pipelines", func(ctx context.Context, probes *mongo.Collection) error {
+		_, err := probes.UpdateOne(ctx, bson.M{}, bson.A{bson.M{"$set": bson.M{"probe": true}}})
+		return err
+	})
+}
+
+// skipWithoutFacets skips tests of aggregations using $facet.
+func skipWithoutFacets(t *testing.T, database *mongo.Database) {
+	t.Helper()
+
+	skipUnlessSupported(t, database, "Facets", func(ctx context.Context, probes *mongo.Collection) error {
+		_, err := probes.Aggregate(ctx, bson.A{bson.M{"$facet": bson.M{"probe": bson.A{bson.M{"$count": "probes"}}}}})
+		return err
+	})
+}
+
+// skipWithoutAtomicUpdates skips racing tests on servers that lose concurrent updates.
+func skipWithoutAtomicUpdates(t *testing.T, database *mongo.Database) {
+	t.Helper()
+
+	skipUnlessSupported(t, database, "Atomic updates", func(ctx context.Context, probes *mongo.Collection) error {
+		const rounds, increments = 3, 100
+		filter := bson.M{"_id": "atomic"}
+		for round := 0; round < rounds; round++ {
+			reset := bson.M{"$set": bson.M{"count": 0}}
+			if _, err := probes.UpdateOne(ctx, filter, reset, options.Update().SetUpsert(true)); err != nil {
+				return err
+			}
+
+			var wg sync.WaitGroup
+			for i := 0; i < increments; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, _ = probes.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"count": 1}})
+				}()
+			}
+			wg.Wait()
+
+			var probe struct {
+				Count int `bson:"count"`
+			}
+			if err := probes.FindOne(ctx, filter).Decode(&probe); err != nil {
+				return err
+			}
+			if probe.Count != increments {
+				return fmt.Errorf("%d concurrent increments added up to %d", increments, probe.Count)
+			}
+		}
+		return nil
+	})
+}
+
+func skipUnlessSupported(
+	t *testing.T,
+	database *mongo.Database,
+	feature string,
+	probe func(ctx context.Context, probes *mongo.Collection) error,
+) {
+	t.Helper()
+
+	if err := probe(context.Background(), database.Collection("probes")); err != nil {
+		t.Skipf("%s are not supported: %s", feature, err)
+	}
+}
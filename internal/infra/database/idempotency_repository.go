@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type (
+	// IdempotencyMongo keeps ExpiresAt as a BSON date so the TTL index can
+	// drop expired records.
+	IdempotencyMongo struct {
+		Key         string    `bson:"_id"`
+		RequestHash string    `bson:"request_hash"`
+		Completed   bool      `bson:"completed"`
+		StatusCode  int       `bson:"status_code,omitempty"`
+		ContentType string    `bson:"content_type,omitempty"`
+		Body        []byte    `bson:"body,omitempty"`
+		CreatedAt   int64     `bson:"created_at"`
+		ExpiresAt   time.Time `bson:"expires_at"`
+	}
+
+	IdempotencyRepository struct {
+		Collection *mongo.Collection
+	}
+)
+
+func NewIdempotencyRepository(database *mongo.Database) *IdempotencyRepository {
+	repository := &IdempotencyRepository{
+		Collection: database.Collection("idempotency_keys"),
+	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+func (ir *IdempotencyRepository) createIndexes(ctx context.Context) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := ir.Collection.Indexes().CreateOne(ctx, index); err != nil {
+		logger.Error("Error trying to create idempotency keys ttl index", err)
+	}
+}
+
+func (ir *IdempotencyRepository) CreateRecord(ctx context.Context, record *entity.IdempotencyRecord) error {
+	recordMongo := &IdempotencyMongo{
+		Key:         record.Key,
+		RequestHash: record.RequestHash,
+		CreatedAt:   record.CreatedAt.Unix(),
+		ExpiresAt:   record.ExpiresAt,
+	}
+	if _, err := ir.Collection.InsertOne(ctx, recordMongo); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewConflictError("Idempotency-Key was already used")
+		}
+
+		logger.Error("Error trying to insert idempotency key", err)
+		return internal_error.NewInternalServerError("Error trying to insert idempotency key")
+	}
+
+	return nil
+}
+
+// FindRecord ignores records past their window that the TTL monitor, which
+// only runs every minute, has not removed yet.
+func (ir *IdempotencyRepository) FindRecord(ctx context.Context, key string) (*entity.IdempotencyRecord, error) {
+	filter := bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now()}}
+
+	var recordMongo IdempotencyMongo
+	if err := ir.Collection.FindOne(ctx, filter).Decode(&recordMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError("Idempotency-Key not found")
+		}
+
+		logger.Error("Error trying to find idempotency key", err)
+		return nil, internal_error.NewInternalServerError("Error trying to find idempotency key")
+	}
+
+	return &entity.IdempotencyRecord{
+		Key:         recordMongo.Key,
+		RequestHash: recordMongo.RequestHash,
+		Completed:   recordMongo.Completed,
+		StatusCode:  recordMongo.StatusCode,
+		ContentType: recordMongo.ContentType,
+		Body:        recordMongo.Body,
+		CreatedAt:   time.Unix(recordMongo.CreatedAt, 0),
+		ExpiresAt:   recordMongo.ExpiresAt,
+	}, nil
+}
+
+func (ir *IdempotencyRepository) CompleteRecord(ctx context.Context, record *entity.IdempotencyRecord) error {
+	update := bson.M{"$set": bson.M{
+		"completed":    true,
+		"status_code":  record.StatusCode,
+		"content_type": record.ContentType,
+		"body":         record.Body,
+	}}
+	if _, err := ir.Collection.UpdateOne(ctx, bson.M{"_id": record.Key}, update); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to complete idempotency key %s", record.Key), err)
+		return internal_error.NewInternalServerError("Error trying to complete idempotency key")
+	}
+
+	return nil
+}
+
+func (ir *IdempotencyRepository) DeleteRecord(ctx context.Context, key string) error {
+	if _, err := ir.Collection.DeleteOne(ctx, bson.M{"_id": key}); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to delete idempotency key %s", key), err)
+		return internal_error.NewInternalServerError("Error trying to delete idempotency key")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	NotificationPreferencesMongo struct {
+		UserId   string   `bson:"_id"`
+		Disabled []string `bson:"disabled"`
+	}
+
+	NotificationPreferencesRepository struct {
+		Collection *mongo.Collection
+	}
+)
+
+func NewNotificationPreferencesRepository(database *mongo.Database) *NotificationPreferencesRepository {
+	return &NotificationPreferencesRepository{
+		Collection: database.Collection("notification_preferences"),
+	}
+}
+
+func (nr *NotificationPreferencesRepository) FindNotificationPreferences(
+	ctx context.Context,
+	userId string) (*entity.NotificationPreferences, error) {
+	preferences := &entity.NotificationPreferences{UserId: userId}
+
+	var preferencesMongo NotificationPreferencesMongo
+	err := nr.Collection.FindOne(ctx, bson.M{"_id": userId}).Decode(&preferencesMongo)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return preferences, nil
+	}
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find notification preferences of user %s", userId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find notification preferences")
+	}
+
+	for _, notificationType := range preferencesMongo.Disabled {
+		preferences.Set(entity.NotificationType(notificationType), false)
+	}
+	return preferences, nil
+}
+
+func (nr *NotificationPreferencesRepository) SaveNotificationPreferences(
+	ctx context.Context,
+	preferences *entity.NotificationPreferences) error {
+	preferencesMongo := NotificationPreferencesMongo{UserId: preferences.UserId, Disabled: []string{}}
+	for _, notificationType := range entity.NotificationTypes {
+		if !preferences.IsEnabled(notificationType) {
+			preferencesMongo.Disabled = append(preferencesMongo.Disabled, string(notificationType))
+		}
+	}
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := nr.Collection.ReplaceOne(ctx, bson.M{"_id": preferences.UserId}, preferencesMongo, opts); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to save notification preferences of user %s", preferences.UserId), err)
+		return internal_error.NewInternalServerError("Error trying to save notification preferences")
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// sortSpec orders a listing by a numeric field, ties broken by _id so that
+	// every document has a stable position to resume from.
+	sortSpec struct {
+		field      string
+		descending bool
+	}
+
+	// pageCursor is the position of the last document of a page, serialized
+	// into the opaque continuation token handed to clients.
+	pageCursor struct {
+		Sort  string  `json:"s"`
+		Value float64 `json:"v"`
+		Id    string  `json:"id"`
+	}
+)
+
+func (pc pageCursor) encode() string {
+	data, _ := json.Marshal(pc)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageCursor(token, sort string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, internal_error.NewBadRequestError("Invalid page cursor")
+	}
+
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" {
+		return nil, internal_error.NewBadRequestError("Invalid page cursor")
+	}
+	if cursor.Sort != sort {
+		return nil, internal_error.NewBadRequestError("Page cursor was issued for a different sort")
+	}
+
+	return &cursor, nil
+}
+
+// after matches the documents that come after the cursor in this sort order.
+func (ss sortSpec) after(cursor *pageCursor) bson.M {
+	operator := "$gt"
+	if ss.descending {
+		operator = "$lt"
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{ss.field: bson.M{operator: cursor.Value}},
+		bson.M{ss.field: cursor.Value, "_id": bson.M{"$gt": cursor.Id}},
+	}}
+}
+
+func (ss sortSpec) order() bson.D {
+	direction := 1
+	if ss.descending {
+		direction = -1
+	}
+
+	return bson.D{{Key: ss.field, Value: direction}, {Key: "_id", Value: 1}}
+}
+
+// findPage reads one page of documents matching filter using keyset
+// pagination; positionOf gives the sort value and id for the next cursor.
+func findPage[T any](
+	ctx context.Context,
+	collection *mongo.Collection,
+	filter bson.M,
+	page entity.PageRequest,
+	sorts map[string]sortSpec,
+	positionOf func(document *T) (float64, string)) (*entity.Page[T], error) {
+	spec, ok := sorts[page.Sort]
+	if !ok {
+		return nil, internal_error.NewBadRequestError("Invalid sort option " + page.Sort)
+	}
+	if page.Limit <= 0 {
+		return nil, internal_error.NewBadRequestError("Page size must be positive")
+	}
+
+	pageFilter := filter
+	if page.Cursor != "" {
+		cursor, err := decodePageCursor(page.Cursor, page.Sort)
+		if err != nil {
+			return nil, err
+		}
+		pageFilter = bson.M{"$and": bson.A{filter, spec.after(cursor)}}
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		logger.Error("Error counting documents of "+collection.Name(), err)
+		return nil, internal_error.NewInternalServerError("Error counting " + collection.Name())
+	}
+
+	opts := options.Find().SetSort(spec.order()).SetLimit(int64(page.Limit) + 1)
+	cursor, err := collection.Find(ctx, pageFilter, opts)
+	if err != nil {
+		logger.Error("Error finding documents of "+collection.Name(), err)
+		return nil, internal_error.NewInternalServerError("Error finding " + collection.Name())
+	}
+	defer cursor.Close(ctx)
+
+	documents := make([]T, 0, page.Limit+1)
+	for cursor.Next(ctx) {
+		var document T
+		if err := cursor.Decode(&document); err != nil {
+			logger.Error("Error decoding document of "+collection.Name(), err)
+			return nil, internal_error.NewInternalServerError("Error decoding " + collection.Name())
+		}
+		documents = append(documents, document)
+	}
+	if err := cursor.Err(); err != nil {
+		logger.Error("Error iterating documents of "+collection.Name(), err)
+		return nil, internal_error.NewInternalServerError("Error finding " + collection.Name())
+	}
+
+	result := &entity.Page[T]{Items: documents, Total: total}
+	if len(documents) > page.Limit {
+		result.Items = documents[:page.Limit]
+		result.HasMore = true
+
+		value, id := positionOf(&result.Items[page.Limit-1])
+		result.NextCursor = pageCursor{Sort: page.Sort, Value: value, Id: id}.encode()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,245 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type (
+	QuestionMongo struct {
+		Id            string                `bson:"_id"`
+		AuctionId     string                `bson:"auction_id"`
+		AskerId       string                `bson:"asker_id"`
+		Text          string                `bson:"text"`
+		Answer        *AnswerMongo          `bson:"answer,omitempty"`
+		Status        entity.QuestionStatus `bson:"status"`
+		Flags         []QuestionFlagMongo   `bson:"flags"`
+		FlagCount     int                   `bson:"flag_count"`
+		Reviewed      bool                  `bson:"reviewed"`
+		ReviewedFlags int                   `bson:"reviewed_flags"`
+		Version       int64                 `bson:"version"`
+		CreatedAt     int64                 `bson:"created_at"`
+	}
+
+	AnswerMongo struct {
+		Text       string `bson:"text"`
+		AnsweredAt int64  `bson:"answered_at"`
+	}
+
+	QuestionFlagMongo struct {
+		UserId    string            `bson:"user_id"`
+		Reason    entity.FlagReason `bson:"reason"`
+		CreatedAt int64             `bson:"created_at"`
+	}
+
+	QuestionRepository struct {
+		Collection        *mongo.Collection
+		AuctionRepository *AuctionRepository
+	}
+)
+
+var questionSorts = map[string]sortSpec{
+	entity.QuestionSortNewest: {field: "created_at", descending: true},
+}
+
+func NewQuestionRepository(database *mongo.Database, auctionRepository *AuctionRepository) *QuestionRepository {
+	repository := &QuestionRepository{
+		Collection:        database.Collection("questions"),
+		AuctionRepository: auctionRepository,
+	}
+	repository.createIndexes(context.Background())
+	repository.backfillReviews(context.Background())
+	return repository
+}
+
+func (qr *QuestionRepository) createIndexes(ctx context.Context) {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{
+			{Key: "auction_id", Value: 1}, {Key: "status", Value: 1},
+			{Key: "created_at", Value: -1}, {Key: "_id", Value: 1},
+		}},
+		{Keys: bson.D{
+			{Key: "reviewed", Value: 1}, {Key: "flag_count", Value: 1},
+			{Key: "created_at", Value: -1}, {Key: "_id", Value: 1},
+		}},
+	}
+	if _, err := qr.Collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Error("Error trying to create questions indexes", err)
+	}
+}
+
+// backfillReviews requeues questions flagged again after a review that cleared their flags.
+func (qr *QuestionRepository) backfillReviews(ctx context.Context) {
+	filter := bson.M{"reviewed_flags": bson.M{"$exists": false}, "reviewed": true, "flag_count": bson.M{"$gt": 0}}
+	update := bson.M{"$set": bson.M{"reviewed": false, "reviewed_flags": 0}}
+	if _, err := qr.Collection.UpdateMany(ctx, filter, update); err != nil {
+		logger.Error("Error trying to backfill question reviews", err)
+	}
+}
+
+func (qr *QuestionRepository) CreateQuestion(ctx context.Context, question *entity.Question) error {
+	if _, err := qr.Collection.InsertOne(ctx, newQuestionMongo(question)); err != nil {
+		logger.Error("Error trying to insert question", err)
+		return internal_error.NewInternalServerError("Error trying to insert question")
+	}
+
+	return nil
+}
+
+// UpdateQuestion also keeps the answered question count of the auction in
+// step when the question starts or stops counting as publicly answered.
+func (qr *QuestionRepository) UpdateQuestion(ctx context.Context, question *entity.Question) error {
+	questionMongo := newQuestionMongo(question)
+	questionMongo.Version = question.Version + 1
+
+	filter := bson.M{"_id": question.Id, "version": question.Version}
+	opts := options.FindOneAndReplace().SetReturnDocument(options.Before)
+
+	var previous QuestionMongo
+	err := qr.Collection.FindOneAndReplace(ctx, filter, questionMongo, opts).Decode(&previous)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return internal_error.NewVersionConflictError("Question was modified by another request, reload it and try again")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to update question %s", question.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update question")
+	}
+	question.Version = questionMongo.Version
+
+	wasAnswered := previous.toEntity().IsPubliclyAnswered()
+	if isAnswered := question.IsPubliclyAnswered(); wasAnswered != isAnswered {
+		delta := 1
+		if wasAnswered {
+			delta = -1
+		}
+		if err := qr.AuctionRepository.countAnsweredQuestion(ctx, question.AuctionId, delta); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to count answered questions of auction %s", question.AuctionId), err)
+		}
+	}
+
+	return nil
+}
+
+func (qr *QuestionRepository) FindQuestionById(ctx context.Context, questionId string) (*entity.Question, error) {
+	var questionMongo QuestionMongo
+	if err := qr.Collection.FindOne(ctx, bson.M{"_id": questionId}).Decode(&questionMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Question not found with this id = %s", questionId))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find question by id = %s", questionId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find question by id")
+	}
+
+	return questionMongo.toEntity(), nil
+}
+
+// FindQuestionsByAuctionId lists the questions shown on the auction page,
+// leaving out hidden ones.
+func (qr *QuestionRepository) FindQuestionsByAuctionId(
+	ctx context.Context,
+	auctionId string,
+	page entity.PageRequest) (*entity.Page[entity.Question], error) {
+	filter := bson.M{"auction_id": auctionId, "status": entity.QuestionVisible}
+	return qr.findQuestions(ctx, filter, page)
+}
+
+// FindFlaggedQuestions lists the questions flagged since a moderator last
+// reviewed them, hidden ones included.
+func (qr *QuestionRepository) FindFlaggedQuestions(
+	ctx context.Context,
+	page entity.PageRequest) (*entity.Page[entity.Question], error) {
+	return qr.findQuestions(ctx, bson.M{"reviewed": false, "flag_count": bson.M{"$gt": 0}}, page)
+}
+
+func (qr *QuestionRepository) findQuestions(
+	ctx context.Context,
+	filter bson.M,
+	page entity.PageRequest) (*entity.Page[entity.Question], error) {
+	questionsPage, err := findPage(ctx, qr.Collection, filter, page, questionSorts,
+		func(question *QuestionMongo) (float64, string) {
+			return float64(question.CreatedAt), question.Id
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]entity.Question, 0, len(questionsPage.Items))
+	for _, questionMongo := range questionsPage.Items {
+		questions = append(questions, *questionMongo.toEntity())
+	}
+
+	return &entity.Page[entity.Question]{
+		Items:      questions,
+		NextCursor: questionsPage.NextCursor,
+		HasMore:    questionsPage.HasMore,
+		Total:      questionsPage.Total,
+	}, nil
+}
+
+func newQuestionMongo(question *entity.Question) *QuestionMongo {
+	questionMongo := &QuestionMongo{
+		Id:            question.Id,
+		AuctionId:     question.AuctionId,
+		AskerId:       question.AskerId,
+		Text:          question.Text,
+		Status:        question.Status,
+		Flags:         make([]QuestionFlagMongo, 0, len(question.Flags)),
+		FlagCount:     len(question.Flags),
+		Reviewed:      question.Reviewed,
+		ReviewedFlags: question.ReviewedFlags,
+		Version:       question.Version,
+		CreatedAt:     question.CreatedAt.Unix(),
+	}
+	if question.Answer != nil {
+		questionMongo.Answer = &AnswerMongo{
+			Text:       question.Answer.Text,
+			AnsweredAt: question.Answer.AnsweredAt.Unix(),
+		}
+	}
+	for _, flag := range question.Flags {
+		questionMongo.Flags = append(questionMongo.Flags, QuestionFlagMongo{
+			UserId:    flag.UserId,
+			Reason:    flag.Reason,
+			CreatedAt: flag.CreatedAt.Unix(),
+		})
+	}
+	return questionMongo
+}
+
+func (qm *QuestionMongo) toEntity() *entity.Question {
+	question := &entity.Question{
+		Id:            qm.Id,
+		AuctionId:     qm.AuctionId,
+		AskerId:       qm.AskerId,
+		Text:          qm.Text,
+		Status:        qm.Status,
+		Reviewed:      qm.Reviewed,
+		ReviewedFlags: qm.ReviewedFlags,
+		Version:       qm.Version,
+		CreatedAt:     time.Unix(qm.CreatedAt, 0),
+	}
+	if qm.Answer != nil {
+		question.Answer = &entity.Answer{
+			Text:       qm.Answer.Text,
+			AnsweredAt: time.Unix(qm.Answer.AnsweredAt, 0),
+		}
+	}
+	for _, flag := range qm.Flags {
+		question.Flags = append(question.Flags, entity.QuestionFlag{
+			UserId:    flag.UserId,
+			Reason:    flag.Reason,
+			CreatedAt: time.Unix(flag.CreatedAt, 0),
+		})
+	}
+	return question
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestQuestionRepository(t *testing.T) (*QuestionRepository, *AuctionRepository) {
+	t.Helper()
+	database := newTestDatabase(t)
+
+	auctionRepository := &AuctionRepository{
+		Collection:      database.Collection("auctions"),
+		bidCollection:   database.Collection("bids"),
+		events:          &recordingPublisher{},
+		auctionDuration: time.Minute,
+	}
+	return NewQuestionRepository(database, auctionRepository), auctionRepository
+}
+
+func newTestQuestion(t *testing.T, questionRepository *QuestionRepository, auction *entity.Auction) *entity.Question {
+	t.Helper()
+
+	question, err := entity.AskQuestion(auction, uuid.New().String(), "Does it ship abroad?")
+	require.NoError(t, err)
+	require.NoError(t, questionRepository.CreateQuestion(context.Background(), question))
+	return question
+}
+
+func TestQuestionRepositoryUpdateQuestionCountsAnswers(t *testing.T) {
+	questionRepository, auctionRepository := newTestQuestionRepository(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		steps []func(question *entity.Question, auction *entity.Auction) error
+		want  int64
+	}{
+		{name: "answered", want: 1, steps: []func(*entity.Question, *entity.Auction) error{
+			answerStep("Yes, worldwide"),
+		}},
+		{name: "answer edited", want: 1, steps: []func(*entity.Question, *entity.Auction) error{
+			answerStep("Yes, worldwide"),
+			answerStep("Yes, except islands"),
+		}},
+		{name: "answered then hidden", want: 0, steps: []func(*entity.Question, *entity.Auction) error{
+			answerStep("Yes, worldwide"),
+			moderateStep((*entity.Question).Hide),
+		}},
+		{name: "hidden then restored", want: 1, steps: []func(*entity.Question, *entity.Auction) error{
+			answerStep("Yes, worldwide"),
+			moderateStep((*entity.Question).Hide),
+			moderateStep((*entity.Question).Restore),
+		}},
+		{name: "flagged without answer", want: 0, steps: []func(*entity.Question, *entity.Auction) error{
+			func(question *entity.Question, _ *entity.Auction) error {
+				return question.Flag(testOtherUserId, entity.FlagSpam)
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := newTestAuction(t, auctionRepository, entity.Active)
+			question := newTestQuestion(t, questionRepository, auction)
+
+			for _, step := range tt.steps {
+				require.NoError(t, step(question, auction))
+				require.NoError(t, questionRepository.UpdateQuestion(ctx, question))
+			}
+
+			updated, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, updated.AnsweredQuestions)
+		})
+	}
+}
+
+func answerStep(text string) func(*entity.Question, *entity.Auction) error {
+	return func(question *entity.Question, auction *entity.Auction) error {
+		return question.AnswerWith(auction, text)
+	}
+}
+
+func moderateStep(decide func(*entity.Question)) func(*entity.Question, *entity.Auction) error {
+	return func(question *entity.Question, _ *entity.Auction) error {
+		decide(question)
+		return nil
+	}
+}
+
+// TestQuestionRepositoryUpdateQuestionCountsConcurrentAnswers answers many
+// questions of one auction at once; every answer must be counted.
+func TestQuestionRepositoryUpdateQuestionCountsConcurrentAnswers(t *testing.T) {
+	questionRepository, auctionRepository := newTestQuestionRepository(t)
+	skipWithoutAtomicUpdates(t, questionRepository.Collection.Database())
+	ctx := context.Background()
+	auction := newTestAuction(t, auctionRepository, entity.Active)
+
+	const answers = 20
+	questions := make([]*entity.Question, answers)
+	for i := range questions {
+		questions[i] = newTestQuestion(t, questionRepository, auction)
+	}
+
+	errs := make([]error, answers)
+	var wg sync.WaitGroup
+	for i, question := range questions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = question.AnswerWith(auction, "Yes, worldwide"); errs[i] == nil {
+				errs[i] = questionRepository.UpdateQuestion(ctx, question)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	updated, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+	require.NoError(t, err)
+	require.EqualValues(t, answers, updated.AnsweredQuestions)
+}
+
+func TestAuctionRepositoryCountAnsweredQuestionStaysPositive(t *testing.T) {
+	_, auctionRepository := newTestQuestionRepository(t)
+	ctx := context.Background()
+	auction := newTestAuction(t, auctionRepository, entity.Active)
+
+	require.NoError(t, auctionRepository.countAnsweredQuestion(ctx, auction.Id, 1))
+	require.NoError(t, auctionRepository.countAnsweredQuestion(ctx, auction.Id, -1))
+	require.NoError(t, auctionRepository.countAnsweredQuestion(ctx, auction.Id, -1))
+
+	updated, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+	require.NoError(t, err)
+	require.Zero(t, updated.AnsweredQuestions)
+}
+
+func TestQuestionRepositoryFindFlaggedQuestions(t *testing.T) {
+	tests := []struct {
+		name     string
+		moderate func(question *entity.Question)
+		want     bool
+	}{
+		{name: "not flagged", moderate: func(*entity.Question) {}},
+		{name: "flagged", want: true, moderate: func(question *entity.Question) {
+			_ = question.Flag("a", entity.FlagSpam)
+		}},
+		{name: "flagged then restored", moderate: func(question *entity.Question) {
+			_ = question.Flag("a", entity.FlagSpam)
+			question.Restore()
+		}},
+		{name: "flagged again after review", want: true, moderate: func(question *entity.Question) {
+			_ = question.Flag("a", entity.FlagSpam)
+			question.Hide()
+			_ = question.Flag("b", entity.FlagOffensive)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questionRepository, auctionRepository := newTestQuestionRepository(t)
+			ctx := context.Background()
+			auction := newTestAuction(t, auctionRepository, entity.Active)
+			question := newTestQuestion(t, questionRepository, auction)
+
+			tt.moderate(question)
+			require.NoError(t, questionRepository.UpdateQuestion(ctx, question))
+
+			flagged, err := questionRepository.FindFlaggedQuestions(ctx, entity.PageRequest{
+				Limit: 10, Sort: entity.QuestionSortNewest,
+			})
+			require.NoError(t, err)
+			require.Len(t, flagged.Items, map[bool]int{true: 1}[tt.want])
+
+			stored, err := questionRepository.FindQuestionById(ctx, question.Id)
+			require.NoError(t, err)
+			require.Len(t, stored.Flags, len(question.Flags))
+			require.Equal(t, question.ReviewedFlags, stored.ReviewedFlags)
+		})
+	}
+}
+
+func TestQuestionRepositoryBackfillReviews(t *testing.T) {
+	questionRepository, auctionRepository := newTestQuestionRepository(t)
+	ctx := context.Background()
+	auction := newTestAuction(t, auctionRepository, entity.Active)
+
+	// A question flagged after a review which cleared its flags, as stored
+	// before reviewed_flags existed.
+	question := newTestQuestion(t, questionRepository, auction)
+	_, err := questionRepository.Collection.UpdateOne(ctx, bson.M{"_id": question.Id}, bson.M{
+		"$set":   bson.M{"reviewed": true, "flag_count": 1, "flags": bson.A{bson.M{"user_id": "a", "reason": "spam"}}},
+		"$unset": bson.M{"reviewed_flags": ""},
+	})
+	require.NoError(t, err)
+
+	questionRepository.backfillReviews(ctx)
+
+	stored, err := questionRepository.FindQuestionById(ctx, question.Id)
+	require.NoError(t, err)
+	require.False(t, stored.Reviewed)
+	require.Equal(t, 1, stored.PendingFlags())
+}
@@ -9,12 +9,18 @@ import (
 	"fullcycle-auction_go/internal/internal_error"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
 )
 
 type (
 	UserMongo struct {
-		Id   string `bson:"_id"`
-		Name string `bson:"name"`
+		Id          string            `bson:"_id"`
+		Name        string            `bson:"name"`
+		Email       string            `bson:"email,omitempty"`
+		DisplayName string            `bson:"display_name,omitempty"`
+		Status      entity.UserStatus `bson:"status"`
+		CreatedAt   int64             `bson:"created_at,omitempty"`
 	}
 
 	UserRepository struct {
@@ -23,9 +29,61 @@ type (
 )
 
 func NewUserRepository(database *mongo.Database) *UserRepository {
-	return &UserRepository{
+	repository := &UserRepository{
 		Collection: database.Collection("users"),
 	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+func (ur *UserRepository) createIndexes(ctx context.Context) {
+	index := mongo.IndexModel{
+		Keys: bson.D{{Key: "email", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"email": bson.M{"$exists": true}}),
+	}
+	if _, err := ur.Collection.Indexes().CreateOne(ctx, index); err != nil {
+		logger.Error("Error trying to create users email index", err)
+	}
+}
+
+func (ur *UserRepository) CreateUser(ctx context.Context, user *entity.User) error {
+	if _, err := ur.Collection.InsertOne(ctx, newUserMongo(user)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewConflictError("Email is already registered")
+		}
+
+		logger.Error("Error trying to insert user", err)
+		return internal_error.NewInternalServerError("Error trying to insert user")
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) UpdateUser(ctx context.Context, user *entity.User) error {
+	userMongo := newUserMongo(user)
+	update := bson.M{"$set": bson.M{
+		"name":         userMongo.Name,
+		"email":        userMongo.Email,
+		"display_name": userMongo.DisplayName,
+		"status":       userMongo.Status,
+	}}
+
+	result, err := ur.Collection.UpdateOne(ctx, bson.M{"_id": user.Id}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return internal_error.NewConflictError("Email is already registered")
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to update user %s", user.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update user")
+	}
+	if result.MatchedCount == 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("User not found with this id = %s", user.Id))
+	}
+
+	return nil
 }
 
 func (ur *UserRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
@@ -35,19 +93,71 @@ func (ur *UserRepository) FindUserById(ctx context.Context, userId string) (*ent
 	err := ur.Collection.FindOne(ctx, filter).Decode(&userMongo)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			logger.Error(fmt.Sprintf("User not found with this id = %d", userId), err)
+			logger.Error(fmt.Sprintf("User not found with this id = %s", userId), err)
 			return nil, internal_error.NewNotFoundError(
-				fmt.Sprintf("User not found with this id = %d", userId))
+				fmt.Sprintf("User not found with this id = %s", userId))
 		}
 
 		logger.Error("Error trying to find user by userId", err)
 		return nil, internal_error.NewInternalServerError("Error trying to find user by userId")
 	}
 
-	user := &entity.User{
-		Id:   userMongo.Id,
-		Name: userMongo.Name,
+	return userMongo.toEntity(), nil
+}
+
+func (ur *UserRepository) FindUsers(ctx context.Context, offset, limit int64) ([]entity.User, int64, error) {
+	total, err := ur.Collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		logger.Error("Error counting users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error counting users")
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+	cursor, err := ur.Collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		logger.Error("Error finding users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error finding users")
 	}
+	defer cursor.Close(ctx)
 
-	return user, nil
+	var usersMongo []UserMongo
+	if err := cursor.All(ctx, &usersMongo); err != nil {
+		logger.Error("Error decoding users", err)
+		return nil, 0, internal_error.NewInternalServerError("Error decoding users")
+	}
+
+	var users []entity.User
+	for _, userMongo := range usersMongo {
+		users = append(users, *userMongo.toEntity())
+	}
+
+	return users, total, nil
+}
+
+func newUserMongo(user *entity.User) *UserMongo {
+	return &UserMongo{
+		Id:          user.Id,
+		Name:        user.Name,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Status:      user.Status,
+		CreatedAt:   user.CreatedAt.Unix(),
+	}
+}
+
+func (um *UserMongo) toEntity() *entity.User {
+	user := &entity.User{
+		Id:          um.Id,
+		Name:        um.Name,
+		Email:       um.Email,
+		DisplayName: um.DisplayName,
+		Status:      um.Status,
+	}
+	if um.CreatedAt != 0 {
+		user.CreatedAt = time.Unix(um.CreatedAt, 0)
+	}
+	return user
 }
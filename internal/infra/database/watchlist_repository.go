@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type (
+	WatchlistEntryMongo struct {
+		Id        string `bson:"_id"`
+		UserId    string `bson:"user_id"`
+		AuctionId string `bson:"auction_id"`
+		CreatedAt int64  `bson:"created_at"`
+	}
+
+	WatchlistRepository struct {
+		Collection        *mongo.Collection
+		AuctionRepository *AuctionRepository
+	}
+)
+
+var watchlistSorts = map[string]sortSpec{
+	entity.WatchlistSortNewest: {field: "created_at", descending: true},
+}
+
+func NewWatchlistRepository(database *mongo.Database, auctionRepository *AuctionRepository) *WatchlistRepository {
+	repository := &WatchlistRepository{
+		Collection:        database.Collection("watchlists"),
+		AuctionRepository: auctionRepository,
+	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+func (wr *WatchlistRepository) createIndexes(ctx context.Context) {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "auction_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	if _, err := wr.Collection.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Error("Error trying to create watchlists indexes", err)
+	}
+}
+
+func (wr *WatchlistRepository) AddToWatchlist(ctx context.Context, entry *entity.WatchlistEntry) (int64, error) {
+	entryMongo := &WatchlistEntryMongo{
+		Id:        entry.Id,
+		UserId:    entry.UserId,
+		AuctionId: entry.AuctionId,
+		CreatedAt: entry.CreatedAt.Unix(),
+	}
+	if _, err := wr.Collection.InsertOne(ctx, entryMongo); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return 0, internal_error.NewConflictError("Auction is already in the watchlist")
+		}
+
+		logger.Error("Error trying to insert watchlist entry", err)
+		return 0, internal_error.NewInternalServerError("Error trying to add auction to watchlist")
+	}
+
+	watcherCount, err := wr.AuctionRepository.addWatcher(ctx, entry.AuctionId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to count watchers of auction %s", entry.AuctionId), err)
+	}
+	return watcherCount, nil
+}
+
+func (wr *WatchlistRepository) RemoveFromWatchlist(ctx context.Context, userId, auctionId string) error {
+	result, err := wr.Collection.DeleteOne(ctx, bson.M{"user_id": userId, "auction_id": auctionId})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to remove auction %s from watchlist", auctionId), err)
+		return internal_error.NewInternalServerError("Error trying to remove auction from watchlist")
+	}
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("Auction %s is not in the watchlist", auctionId))
+	}
+
+	if err := wr.AuctionRepository.incrementCounter(ctx, auctionId, "watcher_count", -1); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to count watchers of auction %s", auctionId), err)
+	}
+	return nil
+}
+
+func (wr *WatchlistRepository) FindWatchlistByUserId(
+	ctx context.Context,
+	userId string,
+	page entity.PageRequest) (*entity.Page[entity.WatchlistEntry], error) {
+	entriesPage, err := findPage(ctx, wr.Collection, bson.M{"user_id": userId}, page, watchlistSorts,
+		func(entry *WatchlistEntryMongo) (float64, string) {
+			return float64(entry.CreatedAt), entry.Id
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]entity.WatchlistEntry, 0, len(entriesPage.Items))
+	for _, entryMongo := range entriesPage.Items {
+		entries = append(entries, entity.WatchlistEntry{
+			Id:        entryMongo.Id,
+			UserId:    entryMongo.UserId,
+			AuctionId: entryMongo.AuctionId,
+			CreatedAt: time.Unix(entryMongo.CreatedAt, 0),
+		})
+	}
+
+	return &entity.Page[entity.WatchlistEntry]{
+		Items:      entries,
+		NextCursor: entriesPage.NextCursor,
+		HasMore:    entriesPage.HasMore,
+		Total:      entriesPage.Total,
+	}, nil
+}
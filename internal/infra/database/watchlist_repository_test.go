@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestWatchlistRepository(t *testing.T) (*WatchlistRepository, *AuctionRepository) {
+	t.Helper()
+	database := newTestDatabase(t)
+
+	auctionRepository := &AuctionRepository{
+		Collection:      database.Collection("auctions"),
+		bidCollection:   database.Collection("bids"),
+		events:          &recordingPublisher{},
+		auctionDuration: time.Minute,
+	}
+	return NewWatchlistRepository(database, auctionRepository), auctionRepository
+}
+
+func TestWatchlistRepositoryCountsWatchers(t *testing.T) {
+	type step struct {
+		userId           string
+		remove           bool
+		wantErr          string
+		wantWatcherCount int64
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{name: "watchers added", steps: []step{
+			{userId: "a", wantWatcherCount: 1},
+			{userId: "b", wantWatcherCount: 2},
+		}},
+		{name: "watching twice", steps: []step{
+			{userId: "a", wantWatcherCount: 1},
+			{userId: "a", wantErr: "conflict", wantWatcherCount: 1},
+		}},
+		{name: "watcher removed", steps: []step{
+			{userId: "a", wantWatcherCount: 1},
+			{userId: "b", wantWatcherCount: 2},
+			{userId: "a", remove: true, wantWatcherCount: 1},
+		}},
+		{name: "removing an auction not watched", steps: []step{
+			{userId: "a", wantWatcherCount: 1},
+			{userId: "b", remove: true, wantErr: "not_found", wantWatcherCount: 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watchlistRepository, auctionRepository := newTestWatchlistRepository(t)
+			ctx := context.Background()
+			auction := newTestAuction(t, auctionRepository, entity.Active)
+
+			for i, step := range tt.steps {
+				var err error
+				if step.remove {
+					err = watchlistRepository.RemoveFromWatchlist(ctx, step.userId, auction.Id)
+				} else {
+					var entry *entity.WatchlistEntry
+					entry, err = entity.WatchAuction(auction, step.userId)
+					require.NoError(t, err)
+
+					var watcherCount int64
+					watcherCount, err = watchlistRepository.AddToWatchlist(ctx, entry)
+					if err == nil {
+						require.Equal(t, step.wantWatcherCount, watcherCount, "step %d", i)
+					}
+				}
+
+				if step.wantErr != "" {
+					requireErrorKind(t, err, step.wantErr)
+				} else {
+					require.NoError(t, err, "step %d", i)
+				}
+				updated, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+				require.NoError(t, err)
+				require.Equal(t, step.wantWatcherCount, updated.WatcherCount, "step %d", i)
+			}
+		})
+	}
+}
+
+// TestWatchlistRepositoryCountsConcurrentWatchers has many users watch one
+// auction at once; each must get a distinct count and all must add up.
+func TestWatchlistRepositoryCountsConcurrentWatchers(t *testing.T) {
+	watchlistRepository, auctionRepository := newTestWatchlistRepository(t)
+	skipWithoutAtomicUpdates(t, watchlistRepository.Collection.Database())
+	ctx := context.Background()
+	auction := newTestAuction(t, auctionRepository, entity.Active)
+
+	const watchers = 20
+	counts := make([]int64, watchers)
+	errs := make([]error, watchers)
+	var wg sync.WaitGroup
+	for i := range counts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			entry, _ := entity.WatchAuction(auction, uuid.New().String())
+			counts[i], errs[i] = watchlistRepository.AddToWatchlist(ctx, entry)
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool)
+	for i := range counts {
+		require.NoError(t, errs[i])
+		require.False(t, seen[counts[i]], "count %d handed out twice", counts[i])
+		seen[counts[i]] = true
+	}
+	updated, err := auctionRepository.FindAuctionById(ctx, auction.Id)
+	require.NoError(t, err)
+	require.EqualValues(t, watchers, updated.WatcherCount)
+}
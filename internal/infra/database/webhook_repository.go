@@ -0,0 +1,323 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type (
+	WebhookMongo struct {
+		Id         string                    `bson:"_id"`
+		OwnerId    string                    `bson:"owner_id"`
+		URL        string                    `bson:"url"`
+		EventTypes []entity.AuctionEventType `bson:"event_types"`
+		Secret     string                    `bson:"secret"`
+		CreatedAt  int64                     `bson:"created_at"`
+	}
+
+	WebhookDeliveryMongo struct {
+		Id            string                  `bson:"_id"`
+		WebhookId     string                  `bson:"webhook_id"`
+		EventId       string                  `bson:"event_id"`
+		EventType     entity.AuctionEventType `bson:"event_type"`
+		Payload       string                  `bson:"payload"`
+		Status        entity.DeliveryStatus   `bson:"status"`
+		Attempts      []DeliveryAttemptMongo  `bson:"attempts"`
+		NextAttemptAt int64                   `bson:"next_attempt_at"`
+		RedeliveryOf  string                  `bson:"redelivery_of,omitempty"`
+		CreatedAt     int64                   `bson:"created_at"`
+	}
+
+	DeliveryAttemptMongo struct {
+		At         int64  `bson:"at"`
+		StatusCode int    `bson:"status_code,omitempty"`
+		Error      string `bson:"error,omitempty"`
+		DurationMs int64  `bson:"duration_ms"`
+	}
+
+	WebhookRepository struct {
+		Collection         *mongo.Collection
+		DeliveryCollection *mongo.Collection
+	}
+)
+
+var deliverySorts = map[string]sortSpec{
+	entity.DeliverySortNewest: {field: "created_at", descending: true},
+}
+
+func NewWebhookRepository(database *mongo.Database) *WebhookRepository {
+	repository := &WebhookRepository{
+		Collection:         database.Collection("webhooks"),
+		DeliveryCollection: database.Collection("webhook_deliveries"),
+	}
+	repository.createIndexes(context.Background())
+	return repository
+}
+
+func (wr *WebhookRepository) createIndexes(ctx context.Context) {
+	if _, err := wr.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "event_types", Value: 1}},
+	}); err != nil {
+		logger.Error("Error trying to create webhooks indexes", err)
+	}
+
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}},
+		{Keys: bson.D{{Key: "webhook_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	if _, err := wr.DeliveryCollection.Indexes().CreateMany(ctx, indexes); err != nil {
+		logger.Error("Error trying to create webhook deliveries indexes", err)
+	}
+}
+
+func (wr *WebhookRepository) CreateWebhook(ctx context.Context, webhook *entity.Webhook) error {
+	webhookMongo := &WebhookMongo{
+		Id:         webhook.Id,
+		OwnerId:    webhook.OwnerId,
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		Secret:     webhook.Secret,
+		CreatedAt:  webhook.CreatedAt.Unix(),
+	}
+	if _, err := wr.Collection.InsertOne(ctx, webhookMongo); err != nil {
+		logger.Error("Error trying to insert webhook", err)
+		return internal_error.NewInternalServerError("Error trying to insert webhook")
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepository) DeleteWebhook(ctx context.Context, webhookId string) error {
+	result, err := wr.Collection.DeleteOne(ctx, bson.M{"_id": webhookId})
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to delete webhook %s", webhookId), err)
+		return internal_error.NewInternalServerError("Error trying to delete webhook")
+	}
+	if result.DeletedCount == 0 {
+		return internal_error.NewNotFoundError(fmt.Sprintf("Webhook not found with this id = %s", webhookId))
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepository) FindWebhookById(ctx context.Context, webhookId string) (*entity.Webhook, error) {
+	var webhookMongo WebhookMongo
+	if err := wr.Collection.FindOne(ctx, bson.M{"_id": webhookId}).Decode(&webhookMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Webhook not found with this id = %s", webhookId))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find webhook by id = %s", webhookId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find webhook by id")
+	}
+
+	return webhookMongo.toEntity(), nil
+}
+
+func (wr *WebhookRepository) FindWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	return wr.findWebhooks(ctx, bson.M{})
+}
+
+func (wr *WebhookRepository) FindWebhooksByEventType(
+	ctx context.Context,
+	eventType entity.AuctionEventType) ([]entity.Webhook, error) {
+	return wr.findWebhooks(ctx, bson.M{"event_types": eventType})
+}
+
+func (wr *WebhookRepository) findWebhooks(ctx context.Context, filter bson.M) ([]entity.Webhook, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := wr.Collection.Find(ctx, filter, opts)
+	if err != nil {
+		logger.Error("Error finding webhooks", err)
+		return nil, internal_error.NewInternalServerError("Error finding webhooks")
+	}
+	defer cursor.Close(ctx)
+
+	var webhooksMongo []WebhookMongo
+	if err := cursor.All(ctx, &webhooksMongo); err != nil {
+		logger.Error("Error decoding webhooks", err)
+		return nil, internal_error.NewInternalServerError("Error decoding webhooks")
+	}
+
+	webhooks := make([]entity.Webhook, 0, len(webhooksMongo))
+	for _, webhookMongo := range webhooksMongo {
+		webhooks = append(webhooks, *webhookMongo.toEntity())
+	}
+
+	return webhooks, nil
+}
+
+func (wr *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		documents = append(documents, newWebhookDeliveryMongo(&delivery))
+	}
+	// Deliveries queued before are skipped, so an event relayed again after
+	// an interruption is not sent twice.
+	opts := options.InsertMany().SetOrdered(false)
+	if _, err := wr.DeliveryCollection.InsertMany(ctx, documents, opts); err != nil && !onlyDuplicateKeys(err) {
+		logger.Error("Error trying to insert webhook deliveries", err)
+		return internal_error.NewInternalServerError("Error trying to insert webhook deliveries")
+	}
+
+	return nil
+}
+
+// onlyDuplicateKeys tells whether every document a bulk insert failed on was
+// already stored.
+func onlyDuplicateKeys(err error) bool {
+	var bulkWriteException mongo.BulkWriteException
+	if !errors.As(err, &bulkWriteException) || bulkWriteException.WriteConcernError != nil {
+		return false
+	}
+	for _, writeError := range bulkWriteException.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeError.WriteError) {
+			return false
+		}
+	}
+	return true
+}
+
+func (wr *WebhookRepository) ClaimDueDelivery(
+	ctx context.Context,
+	lease time.Duration) (*entity.WebhookDelivery, error) {
+	now := time.Now()
+	filter := bson.M{"status": entity.DeliveryPending, "next_attempt_at": bson.M{"$lte": now.Unix()}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": now.Add(lease).Unix()}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var deliveryMongo WebhookDeliveryMongo
+	err := wr.DeliveryCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&deliveryMongo)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Error trying to claim webhook delivery", err)
+		return nil, internal_error.NewInternalServerError("Error trying to claim webhook delivery")
+	}
+
+	return deliveryMongo.toEntity(), nil
+}
+
+func (wr *WebhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	_, err := wr.DeliveryCollection.ReplaceOne(ctx, bson.M{"_id": delivery.Id}, newWebhookDeliveryMongo(delivery))
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to update webhook delivery %s", delivery.Id), err)
+		return internal_error.NewInternalServerError("Error trying to update webhook delivery")
+	}
+
+	return nil
+}
+
+func (wr *WebhookRepository) FindDeliveryById(
+	ctx context.Context,
+	deliveryId string) (*entity.WebhookDelivery, error) {
+	var deliveryMongo WebhookDeliveryMongo
+	if err := wr.DeliveryCollection.FindOne(ctx, bson.M{"_id": deliveryId}).Decode(&deliveryMongo); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Delivery not found with this id = %s", deliveryId))
+		}
+
+		logger.Error(fmt.Sprintf("Error trying to find webhook delivery by id = %s", deliveryId), err)
+		return nil, internal_error.NewInternalServerError("Error trying to find webhook delivery by id")
+	}
+
+	return deliveryMongo.toEntity(), nil
+}
+
+func (wr *WebhookRepository) FindDeliveriesByWebhookId(
+	ctx context.Context,
+	webhookId string,
+	page entity.PageRequest) (*entity.Page[entity.WebhookDelivery], error) {
+	deliveriesPage, err := findPage(ctx, wr.DeliveryCollection, bson.M{"webhook_id": webhookId}, page, deliverySorts,
+		func(delivery *WebhookDeliveryMongo) (float64, string) {
+			return float64(delivery.CreatedAt), delivery.Id
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(deliveriesPage.Items))
+	for _, deliveryMongo := range deliveriesPage.Items {
+		deliveries = append(deliveries, *deliveryMongo.toEntity())
+	}
+
+	return &entity.Page[entity.WebhookDelivery]{
+		Items:      deliveries,
+		NextCursor: deliveriesPage.NextCursor,
+		HasMore:    deliveriesPage.HasMore,
+		Total:      deliveriesPage.Total,
+	}, nil
+}
+
+func (wm *WebhookMongo) toEntity() *entity.Webhook {
+	return &entity.Webhook{
+		Id:         wm.Id,
+		OwnerId:    wm.OwnerId,
+		URL:        wm.URL,
+		EventTypes: wm.EventTypes,
+		Secret:     wm.Secret,
+		CreatedAt:  time.Unix(wm.CreatedAt, 0),
+	}
+}
+
+func newWebhookDeliveryMongo(delivery *entity.WebhookDelivery) *WebhookDeliveryMongo {
+	deliveryMongo := &WebhookDeliveryMongo{
+		Id:            delivery.Id,
+		WebhookId:     delivery.WebhookId,
+		EventId:       delivery.EventId,
+		EventType:     delivery.EventType,
+		Payload:       string(delivery.Payload),
+		Status:        delivery.Status,
+		Attempts:      make([]DeliveryAttemptMongo, 0, len(delivery.Attempts)),
+		NextAttemptAt: delivery.NextAttemptAt.Unix(),
+		RedeliveryOf:  delivery.RedeliveryOf,
+		CreatedAt:     delivery.CreatedAt.Unix(),
+	}
+	for _, attempt := range delivery.Attempts {
+		deliveryMongo.Attempts = append(deliveryMongo.Attempts, DeliveryAttemptMongo{
+			At:         attempt.At.Unix(),
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+		})
+	}
+	return deliveryMongo
+}
+
+func (dm *WebhookDeliveryMongo) toEntity() *entity.WebhookDelivery {
+	delivery := &entity.WebhookDelivery{
+		Id:            dm.Id,
+		WebhookId:     dm.WebhookId,
+		EventId:       dm.EventId,
+		EventType:     dm.EventType,
+		Payload:       []byte(dm.Payload),
+		Status:        dm.Status,
+		NextAttemptAt: time.Unix(dm.NextAttemptAt, 0),
+		RedeliveryOf:  dm.RedeliveryOf,
+		CreatedAt:     time.Unix(dm.CreatedAt, 0),
+	}
+	for _, attempt := range dm.Attempts {
+		delivery.Attempts = append(delivery.Attempts, entity.DeliveryAttempt{
+			At:         time.Unix(attempt.At, 0),
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			Duration:   time.Duration(attempt.DurationMs) * time.Millisecond,
+		})
+	}
+	return delivery
+}
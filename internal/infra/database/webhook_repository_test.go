@@ -0,0 +1,45 @@
+package database
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"testing"
+)
+
+func TestWebhookRepositoryCreateDeliveries(t *testing.T) {
+	database := newTestDatabase(t)
+	ctx := context.Background()
+	webhook := &entity.Webhook{Id: "webhook"}
+	delivery := func(eventId string) entity.WebhookDelivery {
+		return entity.NewWebhookDelivery(webhook, eventId, entity.EventBidAccepted, []byte("{}"))
+	}
+
+	tests := []struct {
+		name    string
+		batches [][]entity.WebhookDelivery
+		want    int64
+	}{
+		{name: "new deliveries", batches: [][]entity.WebhookDelivery{{delivery("a"), delivery("b")}}, want: 2},
+		{name: "event queued again",
+			batches: [][]entity.WebhookDelivery{{delivery("a")}, {delivery("a")}}, want: 1},
+		{name: "batch partly queued before",
+			batches: [][]entity.WebhookDelivery{{delivery("a")}, {delivery("a"), delivery("b")}}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepository := NewWebhookRepository(database)
+			t.Cleanup(func() { _ = webhookRepository.DeliveryCollection.Drop(ctx) })
+
+			for _, batch := range tt.batches {
+				require.NoError(t, webhookRepository.CreateDeliveries(ctx, batch))
+			}
+
+			count, err := webhookRepository.DeliveryCollection.CountDocuments(ctx, bson.M{})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, count)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+// Package events delivers auction events inside the process, from the
+// repositories that publish them to the subscribers reacting to them.
+package events
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"sync"
+)
+
+const subscriberBuffer = 256
+
+type (
+	// Bus fans every published event out to all subscribers. Events a slow
+	// subscriber cannot keep up with are dropped.
+	Bus struct {
+		subscribers []*subscriber
+		mutex       *sync.RWMutex
+	}
+
+	Handler func(ctx context.Context, event entity.AuctionEvent)
+
+	subscriber struct {
+		name    string
+		events  chan entity.AuctionEvent
+		handler Handler
+	}
+)
+
+func NewBus() *Bus {
+	return &Bus{mutex: &sync.RWMutex{}}
+}
+
+func (b *Bus) Subscribe(name string, handler Handler) {
+	sub := &subscriber{
+		name:    name,
+		events:  make(chan entity.AuctionEvent, subscriberBuffer),
+		handler: handler,
+	}
+
+	b.mutex.Lock()
+	b.subscribers = append(b.subscribers, sub)
+	b.mutex.Unlock()
+
+	go func() {
+		for event := range sub.events {
+			sub.handler(context.Background(), event)
+		}
+	}()
+}
+
+func (b *Bus) Publish(_ context.Context, event entity.AuctionEvent) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			logger.Error(fmt.Sprintf("Dropping %s event of auction %s", event.Type, event.AuctionId),
+				fmt.Errorf("subscriber %s is not keeping up", sub.name))
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Package memory holds in-process implementations of the repository
+// interfaces, for tests and for running without a database.
+package memory
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"sort"
+	"sync"
+)
+
+// Field weights mirror the ones of the Mongo text index, so both backends
+// rank hits alike.
+const (
+	productNameWeight = 10
+	categoryWeight    = 5
+	descriptionWeight = 1
+)
+
+type AuctionSearchRepository struct {
+	auctions map[string]entity.Auction
+	mutex    *sync.RWMutex
+}
+
+func NewAuctionSearchRepository(auctions ...entity.Auction) *AuctionSearchRepository {
+	repository := &AuctionSearchRepository{
+		auctions: make(map[string]entity.Auction),
+		mutex:    &sync.RWMutex{},
+	}
+	for _, auction := range auctions {
+		repository.SaveAuction(auction)
+	}
+	return repository
+}
+
+// SaveAuction adds an auction to the index, replacing any previous copy.
+func (sr *AuctionSearchRepository) SaveAuction(auction entity.Auction) {
+	sr.mutex.Lock()
+	defer sr.mutex.Unlock()
+
+	sr.auctions[auction.Id] = auction
+}
+
+func (sr *AuctionSearchRepository) SearchAuctions(
+	_ context.Context,
+	text string,
+	limit int) ([]entity.AuctionSearchHit, error) {
+	terms := entity.SearchTerms(text)
+
+	sr.mutex.RLock()
+	var hits []entity.AuctionSearchHit
+	for _, auction := range sr.auctions {
+		if !auction.Status.IsPublic() {
+			continue
+		}
+
+		score := float64(productNameWeight*entity.CountTermMatches(auction.ProductName, terms) +
+			categoryWeight*entity.CountTermMatches(auction.Category, terms) +
+			descriptionWeight*entity.CountTermMatches(auction.Description, terms))
+		if score > 0 {
+			hits = append(hits, entity.AuctionSearchHit{Auction: auction, Score: score})
+		}
+	}
+	sr.mutex.RUnlock()
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Auction.Id < hits[j].Auction.Id
+	})
+
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAuctionSearchRepositorySearchAuctions(t *testing.T) {
+	searchRepository := NewAuctionSearchRepository(
+		entity.Auction{Id: "name", ProductName: "Leica lens", Category: "cameras",
+			Description: "Fits every body", Status: entity.Active},
+		entity.Auction{Id: "category", ProductName: "Tripod", Category: "lenses",
+			Description: "Carbon legs", Status: entity.Active},
+		entity.Auction{Id: "description", ProductName: "Camera bag", Category: "bags",
+			Description: "Room for a lens", Status: entity.Completed},
+		entity.Auction{Id: "draft", ProductName: "Zoom lens", Category: "cameras",
+			Description: "Not listed yet", Status: entity.Draft},
+		entity.Auction{Id: "other", ProductName: "Bicycle", Category: "sports",
+			Description: "Red frame", Status: entity.Active},
+	)
+
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{name: "ranked by field weight", text: "lens", limit: 10, want: []string{"name", "category", "description"}},
+		{name: "limited", text: "lens", limit: 2, want: []string{"name", "category"}},
+		{name: "negated words ignored", text: "bicycle -lens", limit: 10, want: []string{"other"}},
+		{name: "no match", text: "piano", limit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits, err := searchRepository.SearchAuctions(context.Background(), tt.text, tt.limit)
+			require.NoError(t, err)
+
+			var got []string
+			for _, hit := range hits {
+				got = append(got, hit.Auction.Id)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
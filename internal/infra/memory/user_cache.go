@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/repository"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// CachedUserRepository keeps up to maxEntries recently read users for a
+	// TTL in front of another user repository. Updates drop the cached copy.
+	CachedUserRepository struct {
+		repository.UserRepository
+
+		ttl        time.Duration
+		maxEntries int
+		entries    map[string]cachedUser
+		mutex      *sync.Mutex
+	}
+
+	cachedUser struct {
+		user      entity.User
+		expiresAt time.Time
+	}
+)
+
+// NewCachedUserRepository caches the users of userRepository for
+// USER_CACHE_TTL, holding at most USER_CACHE_MAX_ENTRIES of them.
+func NewCachedUserRepository(userRepository repository.UserRepository) *CachedUserRepository {
+	return &CachedUserRepository{
+		UserRepository: userRepository,
+		ttl:            getUserCacheTTL(),
+		maxEntries:     getUserCacheMaxEntries(),
+		entries:        make(map[string]cachedUser),
+		mutex:          &sync.Mutex{},
+	}
+}
+
+func (r *CachedUserRepository) FindUserById(ctx context.Context, userId string) (*entity.User, error) {
+	r.mutex.Lock()
+	cached, ok := r.entries[userId]
+	r.mutex.Unlock()
+
+	if ok && time.Now().Before(cached.expiresAt) {
+		user := cached.user
+		return &user, nil
+	}
+
+	user, err := r.UserRepository.FindUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.entries[userId]; !ok && len(r.entries) >= r.maxEntries {
+		r.evict()
+	}
+	if len(r.entries) < r.maxEntries {
+		r.entries[userId] = cachedUser{user: *user, expiresAt: time.Now().Add(r.ttl)}
+	}
+
+	return user, nil
+}
+
+func (r *CachedUserRepository) UpdateUser(ctx context.Context, user *entity.User) error {
+	err := r.UserRepository.UpdateUser(ctx, user)
+
+	r.mutex.Lock()
+	delete(r.entries, user.Id)
+	r.mutex.Unlock()
+
+	return err
+}
+
+// evict makes room for one more user, dropping the expired entries or, when
+// none has expired, the one closest to expiring.
+func (r *CachedUserRepository) evict() {
+	now := time.Now()
+	oldestId := ""
+	for userId, cached := range r.entries {
+		if now.After(cached.expiresAt) {
+			delete(r.entries, userId)
+			continue
+		}
+		if oldestId == "" || cached.expiresAt.Before(r.entries[oldestId].expiresAt) {
+			oldestId = userId
+		}
+	}
+
+	if len(r.entries) >= r.maxEntries && oldestId != "" {
+		delete(r.entries, oldestId)
+	}
+}
+
+func getUserCacheTTL() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("USER_CACHE_TTL"))
+	if err != nil {
+		return time.Minute
+	}
+	return duration
+}
+
+func getUserCacheMaxEntries() int {
+	value, err := strconv.Atoi(os.Getenv("USER_CACHE_MAX_ENTRIES"))
+	if err != nil || value <= 0 {
+		return 10000
+	}
+	return value
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingUserRepository serves users from a map, counting the lookups that
+// reached it.
+type countingUserRepository struct {
+	repository.UserRepository
+
+	users   map[string]entity.User
+	lookups int
+}
+
+func (r *countingUserRepository) FindUserById(_ context.Context, userId string) (*entity.User, error) {
+	r.lookups++
+	user, ok := r.users[userId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("User not found")
+	}
+	return &user, nil
+}
+
+func (r *countingUserRepository) UpdateUser(_ context.Context, user *entity.User) error {
+	r.users[user.Id] = *user
+	return nil
+}
+
+func newTestCache(backend *countingUserRepository, ttl time.Duration, maxEntries int) *CachedUserRepository {
+	return &CachedUserRepository{
+		UserRepository: backend,
+		ttl:            ttl,
+		maxEntries:     maxEntries,
+		entries:        make(map[string]cachedUser),
+		mutex:          &sync.Mutex{},
+	}
+}
+
+func TestCachedUserRepository(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		ttl         time.Duration
+		maxEntries  int
+		run         func(t *testing.T, cache *CachedUserRepository)
+		wantLookups int
+		wantStatus  entity.UserStatus
+	}{
+		{
+			name: "repeated lookups hit the cache", ttl: time.Hour, maxEntries: 10, wantLookups: 1,
+			run: func(t *testing.T, cache *CachedUserRepository) {
+				for i := 0; i < 3; i++ {
+					_, err := cache.FindUserById(ctx, "alice")
+					require.NoError(t, err)
+				}
+			},
+		},
+		{
+			name: "expired entries are read again", ttl: -time.Second, maxEntries: 10, wantLookups: 2,
+			run: func(t *testing.T, cache *CachedUserRepository) {
+				for i := 0; i < 2; i++ {
+					_, err := cache.FindUserById(ctx, "alice")
+					require.NoError(t, err)
+				}
+			},
+		},
+		{
+			name: "updates drop the cached copy", ttl: time.Hour, maxEntries: 10, wantLookups: 2,
+			wantStatus: entity.UserSuspended,
+			run: func(t *testing.T, cache *CachedUserRepository) {
+				user, err := cache.FindUserById(ctx, "alice")
+				require.NoError(t, err)
+				user.Status = entity.UserSuspended
+				require.NoError(t, cache.UpdateUser(ctx, user))
+
+				user, err = cache.FindUserById(ctx, "alice")
+				require.NoError(t, err)
+				require.Equal(t, entity.UserSuspended, user.Status)
+			},
+		},
+		{
+			name: "unknown users are not cached", ttl: time.Hour, maxEntries: 10, wantLookups: 2,
+			run: func(t *testing.T, cache *CachedUserRepository) {
+				for i := 0; i < 2; i++ {
+					_, err := cache.FindUserById(ctx, "nobody")
+					require.Error(t, err)
+				}
+				require.Empty(t, cache.entries)
+			},
+		},
+		{
+			name: "size is bounded", ttl: time.Hour, maxEntries: 1, wantLookups: 3,
+			run: func(t *testing.T, cache *CachedUserRepository) {
+				for _, userId := range []string{"alice", "bob", "alice"} {
+					_, err := cache.FindUserById(ctx, userId)
+					require.NoError(t, err)
+					require.Len(t, cache.entries, 1)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &countingUserRepository{users: map[string]entity.User{
+				"alice": {Id: "alice", Status: entity.UserActive},
+				"bob":   {Id: "bob", Status: entity.UserActive},
+			}}
+			cache := newTestCache(backend, tt.ttl, tt.maxEntries)
+
+			tt.run(t, cache)
+
+			require.Equal(t, tt.wantLookups, backend.lookups)
+			require.Equal(t, tt.wantStatus, backend.users["alice"].Status)
+		})
+	}
+}
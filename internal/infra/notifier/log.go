@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"go.uber.org/zap"
+)
+
+// LogNotifier writes notifications to the application log instead of sending
+// them, for development and for deployments without email.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, notification entity.Notification) error {
+	logger.Info("Notification",
+		zap.String("type", string(notification.Type)),
+		zap.String("user_id", notification.UserId),
+		zap.String("auction_id", notification.AuctionId),
+		zap.String("subject", notification.Subject))
+	return nil
+}
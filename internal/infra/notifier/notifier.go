@@ -0,0 +1,28 @@
+// Package notifier delivers user notifications, by email through SMTP or to
+// the application log.
+package notifier
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/repository"
+	"os"
+)
+
+const (
+	_notifierBackend = "NOTIFIER_BACKEND"
+	_backendLog      = "log"
+	_backendSMTP     = "smtp"
+)
+
+// NewNotifier builds the backend selected by NOTIFIER_BACKEND, which defaults
+// to logging notifications only.
+func NewNotifier() (repository.Notifier, error) {
+	switch backend := os.Getenv(_notifierBackend); backend {
+	case "", _backendLog:
+		return NewLogNotifier(), nil
+	case _backendSMTP:
+		return NewSMTPNotifier()
+	default:
+		return nil, fmt.Errorf("unknown notifier backend %q", backend)
+	}
+}
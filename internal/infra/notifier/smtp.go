@@ -0,0 +1,168 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"fullcycle-auction_go/internal/entity"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"time"
+)
+
+const (
+	_smtpHost     = "SMTP_HOST"
+	_smtpPort     = "SMTP_PORT"
+	_smtpUsername = "SMTP_USERNAME"
+	_smtpPassword = "SMTP_PASSWORD"
+	_smtpFrom     = "SMTP_FROM"
+	_defaultPort  = "25"
+	_smtpTimeout  = 30 * time.Second
+)
+
+// SMTPNotifier emails notifications through an SMTP relay. Credentials are
+// optional so that local catch-all servers such as Mailpit work unchanged.
+type SMTPNotifier struct {
+	address string
+	host    string
+	auth    smtp.Auth
+	from    mail.Address
+}
+
+func NewSMTPNotifier() (*SMTPNotifier, error) {
+	host := os.Getenv(_smtpHost)
+	if host == "" {
+		return nil, errors.New("SMTP notifier needs SMTP_HOST")
+	}
+	port := os.Getenv(_smtpPort)
+	if port == "" {
+		port = _defaultPort
+	}
+
+	from, err := mail.ParseAddress(os.Getenv(_smtpFrom))
+	if err != nil {
+		return nil, fmt.Errorf("parsing SMTP_FROM: %w", err)
+	}
+
+	notifier := &SMTPNotifier{address: net.JoinHostPort(host, port), host: host, from: *from}
+	if username := os.Getenv(_smtpUsername); username != "" {
+		notifier.auth = smtp.PlainAuth("", username, os.Getenv(_smtpPassword), host)
+	}
+	return notifier, nil
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification entity.Notification) error {
+	if notification.Email == "" {
+		return fmt.Errorf("user %s has no email address", notification.UserId)
+	}
+
+	to := mail.Address{Name: notification.Name, Address: notification.Email}
+	message, err := n.message(to, notification)
+	if err != nil {
+		return err
+	}
+
+	return n.send(ctx, to.Address, message)
+}
+
+// send runs the SMTP conversation as smtp.SendMail does, bounded by the
+// context deadline or _smtpTimeout.
+func (n *SMTPNotifier) send(ctx context.Context, to string, message []byte) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, _smtpTimeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", n.address)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return contextError(ctx, err)
+	}
+	defer client.Close()
+
+	if err := n.deliver(client, to, message); err != nil {
+		return contextError(ctx, err)
+	}
+	return nil
+}
+
+func (n *SMTPNotifier) deliver(client *smtp.Client, to string, message []byte) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		if err := client.Auth(n.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(n.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	data, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := data.Write(message); err != nil {
+		return err
+	}
+	if err := data.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// contextError reports why the conversation was cut short when it was the
+// context ending, rather than the resulting network error.
+func contextError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %w", ctxErr, err)
+	}
+	return err
+}
+
+// message renders a plain text email with the subject encoded as a MIME word.
+func (n *SMTPNotifier) message(to mail.Address, notification entity.Notification) ([]byte, error) {
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", n.from.String())
+	fmt.Fprintf(&message, "To: %s\r\n", to.String())
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", notification.Subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	body := quotedprintable.NewWriter(&message)
+	if _, err := body.Write([]byte(notification.Body)); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
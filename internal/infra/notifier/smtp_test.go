@@ -0,0 +1,231 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer speaks just enough SMTP to take one message per connection.
+// With stall set it accepts connections but never greets.
+type fakeSMTPServer struct {
+	listener      net.Listener
+	stall         bool
+	rejectRcpt    bool
+	requireAuth   bool
+	mu            sync.Mutex
+	messages      []string
+	authenticated bool
+}
+
+func newFakeSMTPServer(t *testing.T, configure func(server *fakeSMTPServer)) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener}
+	if configure != nil {
+		configure(server)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		<-done
+	})
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	if s.stall {
+		_, _ = conn.Read(make([]byte, 1))
+		return
+	}
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN"):
+			s.mu.Lock()
+			s.authenticated = true
+			s.mu.Unlock()
+			reply("235 Authenticated")
+		case strings.HasPrefix(command, "MAIL FROM"):
+			if s.requireAuth && !s.isAuthenticated() {
+				reply("530 Authentication required")
+				continue
+			}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO"):
+			if s.rejectRcpt {
+				reply("550 No such user")
+				continue
+			}
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 Go ahead")
+			var message strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				message.WriteString(line)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, message.String())
+			s.mu.Unlock()
+			reply("250 Queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) isAuthenticated() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.authenticated
+}
+
+func (s *fakeSMTPServer) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.messages...)
+}
+
+func newTestSMTPNotifier(t *testing.T, server *fakeSMTPServer, username string) *SMTPNotifier {
+	t.Helper()
+	host, port, err := net.SplitHostPort(server.listener.Addr().String())
+	require.NoError(t, err)
+
+	t.Setenv(_smtpHost, host)
+	t.Setenv(_smtpPort, port)
+	t.Setenv(_smtpFrom, "Auctions <auctions@example.com>")
+	t.Setenv(_smtpUsername, username)
+	t.Setenv(_smtpPassword, "secret")
+
+	notifier, err := NewSMTPNotifier()
+	require.NoError(t, err)
+	return notifier
+}
+
+func TestSMTPNotifierNotify(t *testing.T) {
+	notification := entity.Notification{
+		Type:    entity.NotificationOutbid,
+		UserId:  "user",
+		Email:   "bidder@example.com",
+		Name:    "Bidder",
+		Subject: "You were outbid on Câmera",
+		Body:    "Someone bid 12.00 on Câmera.\n",
+	}
+
+	tests := []struct {
+		name         string
+		configure    func(server *fakeSMTPServer)
+		username     string
+		email        string
+		timeout      time.Duration
+		cancel       bool
+		wantErr      error
+		wantErrText  string
+		wantMessages int
+	}{
+		{name: "delivered", email: "bidder@example.com", wantMessages: 1},
+		{name: "delivered with credentials", username: "relay", email: "bidder@example.com", wantMessages: 1,
+			configure: func(server *fakeSMTPServer) { server.requireAuth = true }},
+		{name: "credentials required", email: "bidder@example.com", wantErrText: "530",
+			configure: func(server *fakeSMTPServer) { server.requireAuth = true }},
+		{name: "recipient rejected", email: "bidder@example.com", wantErrText: "550",
+			configure: func(server *fakeSMTPServer) { server.rejectRcpt = true }},
+		{name: "no email address", wantErrText: "no email address"},
+		{name: "stalled server past the deadline", email: "bidder@example.com", timeout: 100 * time.Millisecond,
+			wantErr: context.DeadlineExceeded, configure: func(server *fakeSMTPServer) { server.stall = true }},
+		{name: "cancelled while stalled", email: "bidder@example.com", cancel: true,
+			wantErr: context.Canceled, configure: func(server *fakeSMTPServer) { server.stall = true }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, tt.configure)
+			notifier := newTestSMTPNotifier(t, server, tt.username)
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			if tt.cancel {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				time.AfterFunc(100*time.Millisecond, cancel)
+			}
+
+			sent := notification
+			sent.Email = tt.email
+			start := time.Now()
+			err := notifier.Notify(ctx, sent)
+
+			require.Less(t, time.Since(start), 5*time.Second, "Notify outlived its context")
+			switch {
+			case tt.wantErr != nil:
+				require.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			case tt.wantErrText != "":
+				require.ErrorContains(t, err, tt.wantErrText)
+			default:
+				require.NoError(t, err)
+			}
+			require.Len(t, server.received(), tt.wantMessages)
+		})
+	}
+}
+
+func TestSMTPNotifierMessage(t *testing.T) {
+	notifier := &SMTPNotifier{from: mail.Address{Name: "Auctions", Address: "auctions@example.com"}}
+
+	message, err := notifier.message(mail.Address{Name: "Bidder", Address: "bidder@example.com"},
+		entity.Notification{Subject: "Outbid on Câmera\r\nBcc: victim@example.com", Body: "Someone bid 12.00.\n"})
+
+	require.NoError(t, err)
+	parsed, err := mail.ReadMessage(strings.NewReader(string(message)))
+	require.NoError(t, err)
+	require.Empty(t, parsed.Header.Get("Bcc"))
+	require.Equal(t, `"Bidder" <bidder@example.com>`, parsed.Header.Get("To"))
+	require.True(t, strings.HasPrefix(parsed.Header.Get("Subject"), "=?utf-8?q?"))
+}
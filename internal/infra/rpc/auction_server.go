@@ -0,0 +1,423 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/infra/rpc/auctionpb"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+type AuctionServer struct {
+	auctionpb.UnimplementedAuctionServiceServer
+
+	auctionUseCase       usecase.AuctionUseCase
+	bidUseCase           usecase.BidUseCase
+	userUseCase          usecase.UserUseCase
+	auctionStreamUseCase usecase.AuctionStreamUseCase
+	policy               *auth.Policy
+}
+
+func NewAuctionServer(
+	auctionUseCase usecase.AuctionUseCase,
+	bidUseCase usecase.BidUseCase,
+	userUseCase usecase.UserUseCase,
+	auctionStreamUseCase usecase.AuctionStreamUseCase,
+	policy *auth.Policy,
+) *AuctionServer {
+	return &AuctionServer{
+		auctionUseCase:       auctionUseCase,
+		bidUseCase:           bidUseCase,
+		userUseCase:          userUseCase,
+		auctionStreamUseCase: auctionStreamUseCase,
+		policy:               policy,
+	}
+}
+
+func (s *AuctionServer) CreateAuction(
+	ctx context.Context,
+	req *auctionpb.CreateAuctionRequest,
+) (*auctionpb.Auction, error) {
+	auctionInputDTO := usecase.AuctionInputDTO{
+		ProductName:  req.GetProductName(),
+		Category:     req.GetCategory(),
+		Description:  req.GetDescription(),
+		Condition:    usecase.ProductCondition(req.GetCondition()),
+		Attributes:   req.GetAttributes().AsMap(),
+		ReservePrice: req.GetReservePrice(),
+		RelistRule: usecase.RelistRuleDTO{
+			MaxRelists:            int(req.GetRelistRule().GetMaxRelists()),
+			PriceReductionPercent: req.GetRelistRule().GetPriceReductionPercent(),
+		},
+	}
+	if err := validateInput(auctionInputDTO); err != nil {
+		return nil, err
+	}
+	auctionInputDTO.SellerId, _ = auth.SubjectFromContext(ctx)
+	if err := s.policy.Authorize(ctx, auth.ActionAuctionCreate, auctionInputDTO.SellerId); err != nil {
+		return nil, statusError(err)
+	}
+
+	auctionData, err := s.auctionUseCase.CreateAuction(ctx, auctionInputDTO)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return encodeAuction(auctionData)
+}
+
+func (s *AuctionServer) FindAuction(
+	ctx context.Context,
+	req *auctionpb.FindAuctionRequest,
+) (*auctionpb.Auction, error) {
+	if err := validateUUID("auction_id", req.GetAuctionId()); err != nil {
+		return nil, err
+	}
+
+	auctionData, err := s.findVisibleAuction(ctx, req.GetAuctionId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return encodeAuction(auctionData)
+}
+
+// auctionVisible reports whether the caller may see the auction. Drafts and
+// auctions under moderation are only shown to their seller and to admins.
+func (s *AuctionServer) auctionVisible(ctx context.Context, auction *usecase.AuctionOutputDTO) bool {
+	return auction.Status.IsPublic() || s.policy.Authorize(ctx, auth.ActionAuctionReadDraft, auction.SellerId) == nil
+}
+
+// findVisibleAuction loads an auction the caller may see, reporting the
+// others as not found.
+func (s *AuctionServer) findVisibleAuction(ctx context.Context, auctionId string) (*usecase.AuctionOutputDTO, error) {
+	auctionData, err := s.auctionUseCase.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+	if !s.auctionVisible(ctx, auctionData) {
+		return nil, auctionNotFound(auctionId)
+	}
+	return auctionData, nil
+}
+
+func auctionNotFound(auctionId string) error {
+	return internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", auctionId))
+}
+
+func (s *AuctionServer) FindAuctions(
+	ctx context.Context,
+	req *auctionpb.FindAuctionsRequest,
+) (*auctionpb.FindAuctionsResponse, error) {
+	auctionListInputDTO := usecase.AuctionListInputDTO{
+		Status:      usecase.AuctionStatus(req.GetStatus()),
+		Category:    req.GetCategory(),
+		SellerId:    req.GetSellerId(),
+		ProductName: req.GetProductName(),
+		Text:        req.GetQ(),
+		MinPrice:    req.MinPrice,
+		MaxPrice:    req.MaxPrice,
+		CreatedFrom: fromTimestamp(req.GetCreatedFrom()),
+		CreatedTo:   fromTimestamp(req.GetCreatedTo()),
+		EndingFrom:  fromTimestamp(req.GetEndingFrom()),
+		EndingTo:    fromTimestamp(req.GetEndingTo()),
+		HasBids:     req.HasBids,
+		Attributes:  req.GetAttributes(),
+		Cursor:      req.GetCursor(),
+		Limit:       int(req.GetLimit()),
+		Sort:        req.GetSort(),
+	}
+	if req.Condition != nil {
+		condition := usecase.ProductCondition(req.GetCondition())
+		auctionListInputDTO.Condition = &condition
+	}
+	if err := validateInput(auctionListInputDTO); err != nil {
+		return nil, err
+	}
+	if !auctionListInputDTO.Status.IsPublic() {
+		err := s.policy.Authorize(ctx, auth.ActionAuctionReadDraft, auctionListInputDTO.SellerId)
+		if err != nil {
+			return nil, statusError(err)
+		}
+	}
+
+	auctions, err := s.auctionUseCase.FindAuctions(ctx, auctionListInputDTO)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &auctionpb.FindAuctionsResponse{
+		Auctions:   make([]*auctionpb.Auction, 0, len(auctions.Auctions)),
+		NextCursor: auctions.NextCursor,
+		HasMore:    auctions.HasMore,
+		Total:      auctions.Total,
+	}
+	for i := range auctions.Auctions {
+		auction, err := encodeAuction(&auctions.Auctions[i])
+		if err != nil {
+			return nil, err
+		}
+		response.Auctions = append(response.Auctions, auction)
+	}
+	if response.Facets, err = toFacets(auctions.Facets); err != nil {
+		return nil, encodeError(err)
+	}
+
+	return response, nil
+}
+
+func (s *AuctionServer) FindWinningBid(
+	ctx context.Context,
+	req *auctionpb.FindWinningBidRequest,
+) (*auctionpb.WinningBid, error) {
+	if err := validateUUID("auction_id", req.GetAuctionId()); err != nil {
+		return nil, err
+	}
+
+	winningInfo, err := s.auctionUseCase.FindWinningBidByAuctionId(ctx, req.GetAuctionId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if !s.auctionVisible(ctx, &winningInfo.Auction) {
+		return nil, statusError(auctionNotFound(req.GetAuctionId()))
+	}
+
+	auction, err := encodeAuction(&winningInfo.Auction)
+	if err != nil {
+		return nil, err
+	}
+	response := &auctionpb.WinningBid{Auction: auction}
+	if winningInfo.Bid != nil {
+		response.Bid = toBid(winningInfo.Bid)
+	}
+
+	return response, nil
+}
+
+func (s *AuctionServer) CreateBid(ctx context.Context, req *auctionpb.CreateBidRequest) (*auctionpb.Bid, error) {
+	bidInputDTO := usecase.BidInputDTO{
+		AuctionId: req.GetAuctionId(),
+		Amount:    req.GetAmount(),
+	}
+	if err := validateInput(bidInputDTO); err != nil {
+		return nil, err
+	}
+	bidInputDTO.UserId, _ = auth.SubjectFromContext(ctx)
+	if err := s.policy.Authorize(ctx, auth.ActionBidCreate, bidInputDTO.UserId); err != nil {
+		return nil, statusError(err)
+	}
+
+	bidData, err := s.bidUseCase.CreateBid(ctx, bidInputDTO)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toBid(bidData), nil
+}
+
+func (s *AuctionServer) FindBids(
+	ctx context.Context,
+	req *auctionpb.FindBidsRequest,
+) (*auctionpb.FindBidsResponse, error) {
+	if err := validateUUID("auction_id", req.GetAuctionId()); err != nil {
+		return nil, err
+	}
+
+	bidListInputDTO := usecase.BidListInputDTO{
+		Cursor: req.GetCursor(),
+		Limit:  int(req.GetLimit()),
+		Sort:   req.GetSort(),
+	}
+	if err := validateInput(bidListInputDTO); err != nil {
+		return nil, err
+	}
+	if _, err := s.findVisibleAuction(ctx, req.GetAuctionId()); err != nil {
+		return nil, statusError(err)
+	}
+
+	bids, err := s.bidUseCase.FindBidByAuctionId(ctx, req.GetAuctionId(), bidListInputDTO)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &auctionpb.FindBidsResponse{
+		Bids:       make([]*auctionpb.Bid, 0, len(bids.Bids)),
+		NextCursor: bids.NextCursor,
+		HasMore:    bids.HasMore,
+		Total:      bids.Total,
+	}
+	for i := range bids.Bids {
+		response.Bids = append(response.Bids, toBid(&bids.Bids[i]))
+	}
+
+	return response, nil
+}
+
+// StreamBids relays the bids accepted on an auction. When the stream drops
+// the subscription for lagging behind, it resumes from the last bid sent.
+func (s *AuctionServer) StreamBids(
+	req *auctionpb.StreamBidsRequest,
+	stream auctionpb.AuctionService_StreamBidsServer,
+) error {
+	if err := validateUUID("auction_id", req.GetAuctionId()); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	if _, err := s.findVisibleAuction(ctx, req.GetAuctionId()); err != nil {
+		return statusError(err)
+	}
+
+	lastEventId := req.GetLastEventId()
+	for {
+		subscription, err := s.auctionStreamUseCase.Subscribe(
+			ctx, req.GetAuctionId(), strconv.FormatUint(lastEventId, 10))
+		if err != nil {
+			return statusError(err)
+		}
+
+		for _, event := range subscription.Replay {
+			if err := sendBidEvent(stream, event, &lastEventId); err != nil {
+				subscription.Close()
+				return err
+			}
+		}
+
+		err = forwardBidEvents(ctx, stream, subscription, &lastEventId)
+		subscription.Close()
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// forwardBidEvents relays live events until the subscription ends or the
+// client goes away.
+func forwardBidEvents(
+	ctx context.Context,
+	stream auctionpb.AuctionService_StreamBidsServer,
+	subscription *usecase.AuctionStreamSubscription,
+	lastEventId *uint64,
+) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return nil
+			}
+			if err := sendBidEvent(stream, event, lastEventId); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func sendBidEvent(
+	stream auctionpb.AuctionService_StreamBidsServer,
+	event usecase.AuctionStreamEventDTO,
+	lastEventId *uint64,
+) error {
+	*lastEventId = event.Id
+
+	data, ok := event.Data.(usecase.BidAcceptedEventDTO)
+	if event.Type != usecase.StreamBidAccepted || !ok {
+		return nil
+	}
+
+	return stream.Send(&auctionpb.BidEvent{
+		EventId: event.Id,
+		Bid:     toBid(&data.Bid),
+		Leading: data.Leading,
+	})
+}
+
+func (s *AuctionServer) CreateUser(ctx context.Context, req *auctionpb.CreateUserRequest) (*auctionpb.User, error) {
+	userInputDTO := usecase.UserInputDTO{
+		Name:        req.GetName(),
+		Email:       req.GetEmail(),
+		DisplayName: req.GetDisplayName(),
+	}
+	if err := validateInput(userInputDTO); err != nil {
+		return nil, err
+	}
+
+	userData, err := s.userUseCase.CreateUser(ctx, userInputDTO)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toUser(userData), nil
+}
+
+func (s *AuctionServer) FindUser(ctx context.Context, req *auctionpb.FindUserRequest) (*auctionpb.User, error) {
+	if err := validateUUID("user_id", req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	userData, err := s.userUseCase.FindUserById(ctx, req.GetUserId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return toUser(s.visibleUser(ctx, userData)), nil
+}
+
+func (s *AuctionServer) FindUsers(
+	ctx context.Context,
+	req *auctionpb.FindUsersRequest,
+) (*auctionpb.FindUsersResponse, error) {
+	userListInputDTO := usecase.UserListInputDTO{
+		Page:     req.GetPage(),
+		PageSize: req.GetPageSize(),
+	}
+	if err := validateInput(userListInputDTO); err != nil {
+		return nil, err
+	}
+
+	users, err := s.userUseCase.FindUsers(ctx, userListInputDTO)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	response := &auctionpb.FindUsersResponse{
+		Users:    make([]*auctionpb.User, 0, len(users.Users)),
+		Page:     users.Page,
+		PageSize: users.PageSize,
+		Total:    users.Total,
+	}
+	for i := range users.Users {
+		response.Users = append(response.Users, toUser(s.visibleUser(ctx, &users.Users[i])))
+	}
+
+	return response, nil
+}
+
+// visibleUser leaves out the contact details of the user unless the caller is
+// the user or an admin.
+func (s *AuctionServer) visibleUser(ctx context.Context, user *usecase.UserOutputDTO) *usecase.UserOutputDTO {
+	if s.policy.Authorize(ctx, auth.ActionUserReadPrivate, user.Id) != nil {
+		public := user.Public()
+		return &public
+	}
+	return user
+}
+
+func encodeAuction(auctionData *usecase.AuctionOutputDTO) (*auctionpb.Auction, error) {
+	auction, err := toAuction(auctionData)
+	if err != nil {
+		return nil, encodeError(err)
+	}
+	return auction, nil
+}
+
+func encodeError(err error) error {
+	logger.Error("Error trying to encode gRPC response", err)
+	return status.Error(codes.Internal, "Error trying to encode response")
+}
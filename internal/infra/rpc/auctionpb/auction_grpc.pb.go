@@ -0,0 +1,502 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: auction/v1/auction.proto
+
+package auctionpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	AuctionService_CreateAuction_FullMethodName  = "/auction.v1.AuctionService/CreateAuction"
+	AuctionService_FindAuction_FullMethodName    = "/auction.v1.AuctionService/FindAuction"
+	AuctionService_FindAuctions_FullMethodName   = "/auction.v1.AuctionService/FindAuctions"
+	AuctionService_FindWinningBid_FullMethodName = "/auction.v1.AuctionService/FindWinningBid"
+	AuctionService_CreateBid_FullMethodName      = "/auction.v1.AuctionService/CreateBid"
+	AuctionService_FindBids_FullMethodName       = "/auction.v1.AuctionService/FindBids"
+	AuctionService_StreamBids_FullMethodName     = "/auction.v1.AuctionService/StreamBids"
+	AuctionService_CreateUser_FullMethodName     = "/auction.v1.AuctionService/CreateUser"
+	AuctionService_FindUser_FullMethodName       = "/auction.v1.AuctionService/FindUser"
+	AuctionService_FindUsers_FullMethodName      = "/auction.v1.AuctionService/FindUsers"
+)
+
+// AuctionServiceClient is the client API for AuctionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AuctionService exposes the auction and bid operations of the REST API to
+// internal services. Calls that change data need the same credentials as the
+// REST API, sent as "authorization: Bearer <token>" or "x-api-key" metadata.
+//
+// Errors use the standard gRPC codes: INVALID_ARGUMENT, NOT_FOUND,
+// ALREADY_EXISTS (conflicts), ABORTED (changed by another request since it
+// was read), PERMISSION_DENIED, UNAUTHENTICATED and INTERNAL.
+type AuctionServiceClient interface {
+	CreateAuction(ctx context.Context, in *CreateAuctionRequest, opts ...grpc.CallOption) (*Auction, error)
+	FindAuction(ctx context.Context, in *FindAuctionRequest, opts ...grpc.CallOption) (*Auction, error)
+	FindAuctions(ctx context.Context, in *FindAuctionsRequest, opts ...grpc.CallOption) (*FindAuctionsResponse, error)
+	FindWinningBid(ctx context.Context, in *FindWinningBidRequest, opts ...grpc.CallOption) (*WinningBid, error)
+	CreateBid(ctx context.Context, in *CreateBidRequest, opts ...grpc.CallOption) (*Bid, error)
+	FindBids(ctx context.Context, in *FindBidsRequest, opts ...grpc.CallOption) (*FindBidsResponse, error)
+	// StreamBids sends the bids accepted on an auction as they come in. A
+	// client reconnecting with the id of the last event it got first receives
+	// the bids it missed, as long as they are recent.
+	StreamBids(ctx context.Context, in *StreamBidsRequest, opts ...grpc.CallOption) (AuctionService_StreamBidsClient, error)
+	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error)
+	FindUser(ctx context.Context, in *FindUserRequest, opts ...grpc.CallOption) (*User, error)
+	FindUsers(ctx context.Context, in *FindUsersRequest, opts ...grpc.CallOption) (*FindUsersResponse, error)
+}
+
+type auctionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuctionServiceClient(cc grpc.ClientConnInterface) AuctionServiceClient {
+	return &auctionServiceClient{cc}
+}
+
+func (c *auctionServiceClient) CreateAuction(ctx context.Context, in *CreateAuctionRequest, opts ...grpc.CallOption) (*Auction, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Auction)
+	err := c.cc.Invoke(ctx, AuctionService_CreateAuction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) FindAuction(ctx context.Context, in *FindAuctionRequest, opts ...grpc.CallOption) (*Auction, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Auction)
+	err := c.cc.Invoke(ctx, AuctionService_FindAuction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) FindAuctions(ctx context.Context, in *FindAuctionsRequest, opts ...grpc.CallOption) (*FindAuctionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindAuctionsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_FindAuctions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) FindWinningBid(ctx context.Context, in *FindWinningBidRequest, opts ...grpc.CallOption) (*WinningBid, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WinningBid)
+	err := c.cc.Invoke(ctx, AuctionService_FindWinningBid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) CreateBid(ctx context.Context, in *CreateBidRequest, opts ...grpc.CallOption) (*Bid, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Bid)
+	err := c.cc.Invoke(ctx, AuctionService_CreateBid_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) FindBids(ctx context.Context, in *FindBidsRequest, opts ...grpc.CallOption) (*FindBidsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindBidsResponse)
+	err := c.cc.Invoke(ctx, AuctionService_FindBids_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) StreamBids(ctx context.Context, in *StreamBidsRequest, opts ...grpc.CallOption) (AuctionService_StreamBidsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AuctionService_ServiceDesc.Streams[0], AuctionService_StreamBids_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &auctionServiceStreamBidsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AuctionService_StreamBidsClient interface {
+	Recv() (*BidEvent, error)
+	grpc.ClientStream
+}
+
+type auctionServiceStreamBidsClient struct {
+	grpc.ClientStream
+}
+
+func (x *auctionServiceStreamBidsClient) Recv() (*BidEvent, error) {
+	m := new(BidEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *auctionServiceClient) CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, AuctionService_CreateUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) FindUser(ctx context.Context, in *FindUserRequest, opts ...grpc.CallOption) (*User, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(User)
+	err := c.cc.Invoke(ctx, AuctionService_FindUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *auctionServiceClient) FindUsers(ctx context.Context, in *FindUsersRequest, opts ...grpc.CallOption) (*FindUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FindUsersResponse)
+	err := c.cc.Invoke(ctx, AuctionService_FindUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuctionServiceServer is the server API for AuctionService service.
+// All implementations must embed UnimplementedAuctionServiceServer
+// for forward compatibility
+//
+// AuctionService exposes the auction and bid operations of the REST API to
+// internal services. Calls that change data need the same credentials as the
+// REST API, sent as "authorization: Bearer <token>" or "x-api-key" metadata.
+//
+// Errors use the standard gRPC codes: INVALID_ARGUMENT, NOT_FOUND,
+// ALREADY_EXISTS (conflicts), ABORTED (changed by another request since it
+// was read), PERMISSION_DENIED, UNAUTHENTICATED and INTERNAL.
+type AuctionServiceServer interface {
+	CreateAuction(context.Context, *CreateAuctionRequest) (*Auction, error)
+	FindAuction(context.Context, *FindAuctionRequest) (*Auction, error)
+	FindAuctions(context.Context, *FindAuctionsRequest) (*FindAuctionsResponse, error)
+	FindWinningBid(context.Context, *FindWinningBidRequest) (*WinningBid, error)
+	CreateBid(context.Context, *CreateBidRequest) (*Bid, error)
+	FindBids(context.Context, *FindBidsRequest) (*FindBidsResponse, error)
+	// StreamBids sends the bids accepted on an auction as they come in. A
+	// client reconnecting with the id of the last event it got first receives
+	// the bids it missed, as long as they are recent.
+	StreamBids(*StreamBidsRequest, AuctionService_StreamBidsServer) error
+	CreateUser(context.Context, *CreateUserRequest) (*User, error)
+	FindUser(context.Context, *FindUserRequest) (*User, error)
+	FindUsers(context.Context, *FindUsersRequest) (*FindUsersResponse, error)
+	mustEmbedUnimplementedAuctionServiceServer()
+}
+
+// UnimplementedAuctionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAuctionServiceServer struct {
+}
+
+func (UnimplementedAuctionServiceServer) CreateAuction(context.Context, *CreateAuctionRequest) (*Auction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAuction not implemented")
+}
+func (UnimplementedAuctionServiceServer) FindAuction(context.Context, *FindAuctionRequest) (*Auction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindAuction not implemented")
+}
+func (UnimplementedAuctionServiceServer) FindAuctions(context.Context, *FindAuctionsRequest) (*FindAuctionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindAuctions not implemented")
+}
+func (UnimplementedAuctionServiceServer) FindWinningBid(context.Context, *FindWinningBidRequest) (*WinningBid, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindWinningBid not implemented")
+}
+func (UnimplementedAuctionServiceServer) CreateBid(context.Context, *CreateBidRequest) (*Bid, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBid not implemented")
+}
+func (UnimplementedAuctionServiceServer) FindBids(context.Context, *FindBidsRequest) (*FindBidsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindBids not implemented")
+}
+func (UnimplementedAuctionServiceServer) StreamBids(*StreamBidsRequest, AuctionService_StreamBidsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBids not implemented")
+}
+func (UnimplementedAuctionServiceServer) CreateUser(context.Context, *CreateUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedAuctionServiceServer) FindUser(context.Context, *FindUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindUser not implemented")
+}
+func (UnimplementedAuctionServiceServer) FindUsers(context.Context, *FindUsersRequest) (*FindUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FindUsers not implemented")
+}
+func (UnimplementedAuctionServiceServer) mustEmbedUnimplementedAuctionServiceServer() {}
+
+// UnsafeAuctionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuctionServiceServer will
+// result in compilation errors.
+type UnsafeAuctionServiceServer interface {
+	mustEmbedUnimplementedAuctionServiceServer()
+}
+
+func RegisterAuctionServiceServer(s grpc.ServiceRegistrar, srv AuctionServiceServer) {
+	s.RegisterService(&AuctionService_ServiceDesc, srv)
+}
+
+func _AuctionService_CreateAuction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAuctionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).CreateAuction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_CreateAuction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).CreateAuction(ctx, req.(*CreateAuctionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_FindAuction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindAuctionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).FindAuction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_FindAuction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).FindAuction(ctx, req.(*FindAuctionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_FindAuctions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindAuctionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).FindAuctions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_FindAuctions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).FindAuctions(ctx, req.(*FindAuctionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_FindWinningBid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindWinningBidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).FindWinningBid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_FindWinningBid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).FindWinningBid(ctx, req.(*FindWinningBidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_CreateBid_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBidRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).CreateBid(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_CreateBid_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).CreateBid(ctx, req.(*CreateBidRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_FindBids_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindBidsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).FindBids(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_FindBids_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).FindBids(ctx, req.(*FindBidsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_StreamBids_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBidsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AuctionServiceServer).StreamBids(m, &auctionServiceStreamBidsServer{ServerStream: stream})
+}
+
+type AuctionService_StreamBidsServer interface {
+	Send(*BidEvent) error
+	grpc.ServerStream
+}
+
+type auctionServiceStreamBidsServer struct {
+	grpc.ServerStream
+}
+
+func (x *auctionServiceStreamBidsServer) Send(m *BidEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _AuctionService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_FindUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).FindUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_FindUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).FindUser(ctx, req.(*FindUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuctionService_FindUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuctionServiceServer).FindUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuctionService_FindUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuctionServiceServer).FindUsers(ctx, req.(*FindUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuctionService_ServiceDesc is the grpc.ServiceDesc for AuctionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuctionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "auction.v1.AuctionService",
+	HandlerType: (*AuctionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateAuction",
+			Handler:    _AuctionService_CreateAuction_Handler,
+		},
+		{
+			MethodName: "FindAuction",
+			Handler:    _AuctionService_FindAuction_Handler,
+		},
+		{
+			MethodName: "FindAuctions",
+			Handler:    _AuctionService_FindAuctions_Handler,
+		},
+		{
+			MethodName: "FindWinningBid",
+			Handler:    _AuctionService_FindWinningBid_Handler,
+		},
+		{
+			MethodName: "CreateBid",
+			Handler:    _AuctionService_CreateBid_Handler,
+		},
+		{
+			MethodName: "FindBids",
+			Handler:    _AuctionService_FindBids_Handler,
+		},
+		{
+			MethodName: "CreateUser",
+			Handler:    _AuctionService_CreateUser_Handler,
+		},
+		{
+			MethodName: "FindUser",
+			Handler:    _AuctionService_FindUser_Handler,
+		},
+		{
+			MethodName: "FindUsers",
+			Handler:    _AuctionService_FindUsers_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamBids",
+			Handler:       _AuctionService_StreamBids_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "auction/v1/auction.proto",
+}
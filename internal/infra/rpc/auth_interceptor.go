@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/infra/rpc/auctionpb"
+	"fullcycle-auction_go/internal/usecase"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	authorizationMetadata = "authorization"
+	apiKeyMetadata        = "x-api-key"
+)
+
+// authenticatedMethods need credentials; other methods use them when sent.
+var authenticatedMethods = map[string]bool{
+	auctionpb.AuctionService_CreateAuction_FullMethodName: true,
+	auctionpb.AuctionService_CreateBid_FullMethodName:     true,
+}
+
+type authenticator struct {
+	authUseCase usecase.AuthUseCase
+}
+
+// principalStream carries the context holding the principal to the handler
+// of a streaming call.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
+
+func (a *authenticator) unary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *authenticator) stream(
+	srv interface{},
+	ss grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate stores the principal identified by the credentials of the
+// call in its context.
+func (a *authenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	apiKey := firstMetadata(md, apiKeyMetadata)
+	authorization := firstMetadata(md, authorizationMetadata)
+	if apiKey == "" && authorization == "" && !authenticatedMethods[method] {
+		return ctx, nil
+	}
+
+	principal, err := a.authUseCase.Authenticate(ctx, apiKey, authorization)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,137 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fullcycle-auction_go/internal/infra/rpc/auctionpb"
+	"fullcycle-auction_go/internal/usecase"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"time"
+)
+
+func toAuction(auction *usecase.AuctionOutputDTO) (*auctionpb.Auction, error) {
+	var attributes *structpb.Struct
+	if len(auction.Attributes) > 0 {
+		attributes = &structpb.Struct{}
+		if err := toProtoJSON(auction.Attributes, attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	images := make([]*auctionpb.AuctionImage, 0, len(auction.Images))
+	for _, image := range auction.Images {
+		images = append(images, &auctionpb.AuctionImage{
+			Id:           image.Id,
+			Url:          image.URL,
+			ThumbnailUrl: image.ThumbnailURL,
+			ContentType:  image.ContentType,
+			Size:         image.Size,
+			Width:        int32(image.Width),
+			Height:       int32(image.Height),
+			Position:     int32(image.Position),
+			Primary:      image.Primary,
+			CreatedAt:    timestamppb.New(image.CreatedAt),
+		})
+	}
+
+	return &auctionpb.Auction{
+		Id:                 auction.Id,
+		SellerId:           auction.SellerId,
+		ProductName:        auction.ProductName,
+		Category:           auction.Category,
+		Description:        auction.Description,
+		Condition:          auctionpb.ProductCondition(auction.Condition),
+		Attributes:         attributes,
+		Images:             images,
+		Status:             auctionpb.AuctionStatus(auction.Status),
+		ReservePrice:       auction.ReservePrice,
+		CancellationReason: auction.CancellationReason,
+		CancelledAt:        toTimestamp(auction.CancelledAt),
+		RejectionReason:    auction.RejectionReason,
+		RelistRule: &auctionpb.RelistRule{
+			MaxRelists:            int32(auction.RelistRule.MaxRelists),
+			PriceReductionPercent: auction.RelistRule.PriceReductionPercent,
+		},
+		RelistCount:       int32(auction.RelistCount),
+		RelistedFromId:    auction.RelistedFromId,
+		RelistedAsId:      auction.RelistedAsId,
+		CurrentPrice:      auction.CurrentPrice,
+		BidCount:          auction.BidCount,
+		AnsweredQuestions: auction.AnsweredQuestions,
+		WatcherCount:      auction.WatcherCount,
+		Version:           auction.Version,
+		CreatedAt:         toTimestamp(auction.CreatedAt),
+		Timestamp:         timestamppb.New(auction.Timestamp),
+	}, nil
+}
+
+func toFacets(facets []usecase.AttributeFacetDTO) ([]*auctionpb.AttributeFacet, error) {
+	converted := make([]*auctionpb.AttributeFacet, 0, len(facets))
+	for _, facet := range facets {
+		values := make([]*auctionpb.FacetValue, 0, len(facet.Values))
+		for _, value := range facet.Values {
+			protoValue := &structpb.Value{}
+			if err := toProtoJSON(value.Value, protoValue); err != nil {
+				return nil, err
+			}
+			values = append(values, &auctionpb.FacetValue{Value: protoValue, Count: value.Count})
+		}
+
+		converted = append(converted, &auctionpb.AttributeFacet{
+			Key:    facet.Key,
+			Label:  facet.Label,
+			Type:   facet.Type,
+			Values: values,
+			Min:    facet.Min,
+			Max:    facet.Max,
+		})
+	}
+	return converted, nil
+}
+
+func toBid(bid *usecase.BidOutputDTO) *auctionpb.Bid {
+	return &auctionpb.Bid{
+		Id:        bid.Id,
+		UserId:    bid.UserId,
+		AuctionId: bid.AuctionId,
+		Amount:    bid.Amount,
+		Timestamp: timestamppb.New(bid.Timestamp),
+	}
+}
+
+func toUser(user *usecase.UserOutputDTO) *auctionpb.User {
+	return &auctionpb.User{
+		Id:          user.Id,
+		Name:        user.Name,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Status:      auctionpb.UserStatus(user.Status),
+		CreatedAt:   toTimestamp(user.CreatedAt),
+	}
+}
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// toProtoJSON converts free-form values through their JSON encoding, so that
+// they read the same as in the REST API whatever Go types hold them.
+func toProtoJSON(value interface{}, message proto.Message) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return protojson.Unmarshal(data, message)
+}
@@ -0,0 +1,105 @@
+package rpc
+
+import (
+	"errors"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/go-playground/locales/en"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	validatorEn "github.com/go-playground/validator/v10/translations/en"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const internalErrorMessage = "Internal server error"
+
+var (
+	validate   *validator.Validate
+	translator ut.Translator
+)
+
+func init() {
+	// Requests are checked against the binding rules of the use case DTOs, as
+	// Gin does for the REST API.
+	validate = validator.New()
+	validate.SetTagName("binding")
+
+	englishTranslator := en.New()
+	translator, _ = ut.New(englishTranslator, englishTranslator).GetTranslator("en")
+	_ = validatorEn.RegisterDefaultTranslations(validate, translator)
+}
+
+// statusError converts the errors of the use cases to gRPC statuses. Server
+// errors are logged and answered with a generic message.
+func statusError(err error) error {
+	var internalError *internal_error.InternalError
+	if !errors.As(err, &internalError) {
+		logger.Error("Error trying to serve gRPC request", err)
+		return status.Error(codes.Internal, internalErrorMessage)
+	}
+
+	switch internalError.Err {
+	case "bad_request":
+		return status.Error(codes.InvalidArgument, internalError.Error())
+	case "not_found":
+		return status.Error(codes.NotFound, internalError.Error())
+	case "conflict":
+		return status.Error(codes.AlreadyExists, internalError.Error())
+	case "version_conflict":
+		return status.Error(codes.Aborted, internalError.Error())
+	case "forbidden":
+		return status.Error(codes.PermissionDenied, internalError.Error())
+	case "unauthorized":
+		return status.Error(codes.Unauthenticated, internalError.Error())
+	default:
+		logger.Error("Error trying to serve gRPC request", err)
+		return status.Error(codes.Internal, internalErrorMessage)
+	}
+}
+
+// validateInput checks a use case DTO built from a request. The fields
+// failing validation are listed in the details of the status.
+func validateInput(input interface{}) error {
+	err := validate.Struct(input)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return status.Error(codes.InvalidArgument, "Error trying to convert fields")
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, e := range validationErrors {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       e.Field(),
+			Description: e.Translate(translator),
+		})
+	}
+	return invalidArgument(badRequest)
+}
+
+// validateUUID checks an id field of a request.
+func validateUUID(field, value string) error {
+	if err := uuid.Validate(value); err != nil {
+		return invalidArgument(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       field,
+				Description: "Invalid UUID value",
+			}},
+		})
+	}
+	return nil
+}
+
+func invalidArgument(badRequest *errdetails.BadRequest) error {
+	invalid := status.New(codes.InvalidArgument, "Invalid field values")
+	if withDetails, err := invalid.WithDetails(badRequest); err == nil {
+		invalid = withDetails
+	}
+	return invalid.Err()
+}
@@ -0,0 +1,32 @@
+// Package rpc serves the auction API over gRPC, generated into auctionpb
+// from proto/auction/v1/auction.proto.
+package rpc
+
+//go:generate protoc -I ../../../proto --go_out=auctionpb --go_opt=paths=source_relative --go-grpc_out=auctionpb --go-grpc_opt=paths=source_relative auction/v1/auction.proto
+
+import (
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/infra/rpc/auctionpb"
+	"fullcycle-auction_go/internal/usecase"
+	"google.golang.org/grpc"
+)
+
+// NewServer builds the gRPC server with the auction service registered.
+func NewServer(
+	auctionUseCase usecase.AuctionUseCase,
+	bidUseCase usecase.BidUseCase,
+	userUseCase usecase.UserUseCase,
+	auctionStreamUseCase usecase.AuctionStreamUseCase,
+	authUseCase usecase.AuthUseCase,
+	policy *auth.Policy,
+) *grpc.Server {
+	authenticator := &authenticator{authUseCase: authUseCase}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(authenticator.unary),
+		grpc.ChainStreamInterceptor(authenticator.stream),
+	)
+
+	auctionpb.RegisterAuctionServiceServer(server, NewAuctionServer(
+		auctionUseCase, bidUseCase, userUseCase, auctionStreamUseCase, policy))
+	return server
+}
@@ -0,0 +1,269 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/infra/rpc/auctionpb"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/usecase"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+	"time"
+)
+
+const testJWTSecret = "test-secret"
+
+var (
+	testAuctionId = "4c1f8e2a-9b3d-4e7f-a6c5-2d8b1e0f3a94"
+	testUserId    = "8f0c3a52-6d1e-4b9a-9a53-2f7d1c4e8b10"
+	testOtherId   = "1b7e2f90-3c4d-4e5f-8a6b-7c8d9e0f1a2b"
+)
+
+// fakeBidUseCase queues every bid, or fails them all with err. It embeds the
+// use case interface, so calling anything else fails the test.
+type fakeBidUseCase struct {
+	usecase.BidUseCase
+
+	err error
+}
+
+func (f *fakeBidUseCase) CreateBid(_ context.Context, input usecase.BidInputDTO) (*usecase.BidOutputDTO, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &usecase.BidOutputDTO{
+		Id:        testOtherId,
+		UserId:    input.UserId,
+		AuctionId: input.AuctionId,
+		Amount:    input.Amount,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// fakeUserUseCase serves a fixed set of users.
+type fakeUserUseCase struct {
+	usecase.UserUseCase
+
+	users []usecase.UserOutputDTO
+}
+
+func (f *fakeUserUseCase) FindUserById(_ context.Context, id string) (*usecase.UserOutputDTO, error) {
+	for _, user := range f.users {
+		if user.Id == id {
+			return &user, nil
+		}
+	}
+	return nil, internal_error.NewNotFoundError("User not found")
+}
+
+func (f *fakeUserUseCase) FindUsers(_ context.Context, _ usecase.UserListInputDTO) (*usecase.UserListOutputDTO, error) {
+	users := append([]usecase.UserOutputDTO(nil), f.users...)
+	return &usecase.UserListOutputDTO{Users: users, Page: 1, PageSize: 20, Total: int64(len(users))}, nil
+}
+
+// newTestClient serves the auction service over an in-memory connection.
+func newTestClient(
+	t *testing.T,
+	bidUseCase usecase.BidUseCase,
+	userUseCase usecase.UserUseCase,
+) auctionpb.AuctionServiceClient {
+	t.Helper()
+
+	t.Setenv("JWT_HS256_SECRET", testJWTSecret)
+	t.Setenv("JWT_RS256_PUBLIC_KEY_FILE", "")
+	t.Setenv("JWT_JWKS_FILE", "")
+	verifier, err := auth.NewJWTVerifier()
+	require.NoError(t, err)
+	t.Setenv("AUTHORIZATION_POLICY_FILE", "")
+	policy, err := auth.NewPolicy()
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(nil, bidUseCase, userUseCase, nil, usecase.NewAuthUseCase(verifier, nil), policy)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return auctionpb.NewAuctionServiceClient(conn)
+}
+
+// withToken sends a bearer token for userId, holding roles and expiring
+// after expiresIn, as the credentials of the call.
+func withToken(t *testing.T, userId string, expiresIn time.Duration, roles ...string) context.Context {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   userId,
+		"exp":   time.Now().Add(expiresIn).Unix(),
+		"roles": roles,
+	}).SignedString([]byte(testJWTSecret))
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationMetadata, "Bearer "+token)
+}
+
+func TestAuctionServerAuthentication(t *testing.T) {
+	users := []usecase.UserOutputDTO{{Id: testUserId, Name: "Bidder"}}
+
+	tests := []struct {
+		name     string
+		ctx      func(t *testing.T) context.Context
+		call     func(ctx context.Context, client auctionpb.AuctionServiceClient) error
+		wantCode codes.Code
+	}{
+		{name: "bid by a bidder", wantCode: codes.OK,
+			ctx:  func(t *testing.T) context.Context { return withToken(t, testUserId, time.Hour, auth.RoleBidder) },
+			call: createBid(testAuctionId, 10)},
+		{name: "anonymous bid", wantCode: codes.Unauthenticated,
+			ctx:  func(*testing.T) context.Context { return context.Background() },
+			call: createBid(testAuctionId, 10)},
+		{name: "bid with an expired token", wantCode: codes.Unauthenticated,
+			ctx:  func(t *testing.T) context.Context { return withToken(t, testUserId, -time.Hour, auth.RoleBidder) },
+			call: createBid(testAuctionId, 10)},
+		{name: "bid with a malformed token", wantCode: codes.Unauthenticated,
+			ctx: func(*testing.T) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationMetadata, "Bearer garbage")
+			},
+			call: createBid(testAuctionId, 10)},
+		{name: "bid by a seller", wantCode: codes.PermissionDenied,
+			ctx:  func(t *testing.T) context.Context { return withToken(t, testUserId, time.Hour, auth.RoleSeller) },
+			call: createBid(testAuctionId, 10)},
+		{name: "anonymous read", wantCode: codes.OK,
+			ctx:  func(*testing.T) context.Context { return context.Background() },
+			call: findUser(testUserId)},
+		{name: "read with an expired token", wantCode: codes.Unauthenticated,
+			ctx:  func(t *testing.T) context.Context { return withToken(t, testUserId, -time.Hour, auth.RoleBidder) },
+			call: findUser(testUserId)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &fakeBidUseCase{}, &fakeUserUseCase{users: users})
+
+			err := tt.call(tt.ctx(t), client)
+
+			require.Equal(t, tt.wantCode, status.Code(err), err)
+		})
+	}
+}
+
+func TestAuctionServerStatusMapping(t *testing.T) {
+	tests := []struct {
+		name        string
+		auctionId   string
+		amount      float64
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{name: "invalid auction id", auctionId: "not-a-uuid", amount: 10, wantCode: codes.InvalidArgument,
+			wantMessage: "Invalid field values"},
+		{name: "amount not positive", auctionId: testAuctionId, wantCode: codes.InvalidArgument,
+			wantMessage: "Invalid field values"},
+		{name: "bad request", err: internal_error.NewBadRequestError("Auction is not open for bids"),
+			wantCode: codes.InvalidArgument, wantMessage: "Auction is not open for bids"},
+		{name: "not found", err: internal_error.NewNotFoundError("Auction not found"),
+			wantCode: codes.NotFound, wantMessage: "Auction not found"},
+		{name: "conflict", err: internal_error.NewConflictError("Bid was already placed"),
+			wantCode: codes.AlreadyExists, wantMessage: "Bid was already placed"},
+		{name: "version conflict", err: internal_error.NewVersionConflictError("Auction was modified"),
+			wantCode: codes.Aborted, wantMessage: "Auction was modified"},
+		{name: "forbidden", err: internal_error.NewForbiddenError("Sellers cannot bid on their auctions"),
+			wantCode: codes.PermissionDenied, wantMessage: "Sellers cannot bid on their auctions"},
+		{name: "unauthorized", err: internal_error.NewUnauthorizedError("User is suspended"),
+			wantCode: codes.Unauthenticated, wantMessage: "User is suspended"},
+		{name: "internal error", err: internal_error.NewInternalServerError("Error trying to find auction"),
+			wantCode: codes.Internal, wantMessage: internalErrorMessage},
+		{name: "unexpected error", err: errors.New("connection refused by 10.0.0.7:27017"),
+			wantCode: codes.Internal, wantMessage: internalErrorMessage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &fakeBidUseCase{err: tt.err}, &fakeUserUseCase{})
+			auctionId, amount := tt.auctionId, tt.amount
+			if tt.err != nil {
+				auctionId, amount = testAuctionId, 10
+			}
+
+			err := createBid(auctionId, amount)(withToken(t, testUserId, time.Hour, auth.RoleBidder), client)
+
+			require.Equal(t, tt.wantCode, status.Code(err), err)
+			require.Equal(t, tt.wantMessage, status.Convert(err).Message())
+		})
+	}
+}
+
+func TestAuctionServerUserEmails(t *testing.T) {
+	users := []usecase.UserOutputDTO{
+		{Id: testUserId, Name: "Bidder", Email: "bidder@example.com"},
+		{Id: testOtherId, Name: "Other", Email: "other@example.com"},
+	}
+
+	tests := []struct {
+		name           string
+		ctx            func(t *testing.T) context.Context
+		wantFoundEmail string
+		wantEmails     []string
+	}{
+		{name: "anonymous", ctx: func(*testing.T) context.Context { return context.Background() },
+			wantEmails: []string{"", ""}},
+		{name: "the user", ctx: func(t *testing.T) context.Context {
+			return withToken(t, testUserId, time.Hour, auth.RoleBidder)
+		}, wantFoundEmail: "bidder@example.com", wantEmails: []string{"bidder@example.com", ""}},
+		{name: "another user", ctx: func(t *testing.T) context.Context {
+			return withToken(t, testOtherId, time.Hour, auth.RoleBidder)
+		}, wantEmails: []string{"", "other@example.com"}},
+		{name: "admin", ctx: func(t *testing.T) context.Context {
+			return withToken(t, testOtherId, time.Hour, auth.RoleAdmin)
+		}, wantFoundEmail: "bidder@example.com", wantEmails: []string{"bidder@example.com", "other@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &fakeBidUseCase{}, &fakeUserUseCase{users: users})
+			ctx := tt.ctx(t)
+
+			user, err := client.FindUser(ctx, &auctionpb.FindUserRequest{UserId: testUserId})
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFoundEmail, user.GetEmail())
+
+			listed, err := client.FindUsers(ctx, &auctionpb.FindUsersRequest{})
+			require.NoError(t, err)
+			emails := make([]string, 0, len(listed.GetUsers()))
+			for _, user := range listed.GetUsers() {
+				emails = append(emails, user.GetEmail())
+			}
+			require.Equal(t, tt.wantEmails, emails)
+		})
+	}
+}
+
+func createBid(auctionId string, amount float64) func(context.Context, auctionpb.AuctionServiceClient) error {
+	return func(ctx context.Context, client auctionpb.AuctionServiceClient) error {
+		_, err := client.CreateBid(ctx, &auctionpb.CreateBidRequest{AuctionId: auctionId, Amount: amount})
+		return err
+	}
+}
+
+func findUser(userId string) func(context.Context, auctionpb.AuctionServiceClient) error {
+	return func(ctx context.Context, client auctionpb.AuctionServiceClient) error {
+		_, err := client.FindUser(ctx, &auctionpb.FindUserRequest{UserId: userId})
+		return err
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	_imageStorageDir     = "IMAGE_STORAGE_DIR"
+	_imagePublicBaseURL  = "IMAGE_PUBLIC_BASE_URL"
+	_defaultStorageDir   = "./uploads"
+	_defaultPublicPrefix = "/media"
+)
+
+// LocalImageStorage writes images below a directory which the API serves
+// itself under BaseURL.
+type LocalImageStorage struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocalImageStorage() (*LocalImageStorage, error) {
+	storage := &LocalImageStorage{
+		dir:     os.Getenv(_imageStorageDir),
+		baseURL: os.Getenv(_imagePublicBaseURL),
+	}
+	if storage.dir == "" {
+		storage.dir = _defaultStorageDir
+	}
+	if storage.baseURL == "" {
+		storage.baseURL = _defaultPublicPrefix
+	}
+
+	if err := os.MkdirAll(storage.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating image storage directory: %w", err)
+	}
+	return storage, nil
+}
+
+func (s *LocalImageStorage) Dir() string {
+	return s.dir
+}
+
+func (s *LocalImageStorage) BaseURL() string {
+	return s.baseURL
+}
+
+// PutImage writes the file through a temporary one so a concurrent reader
+// never sees it half written.
+func (s *LocalImageStorage) PutImage(_ context.Context, key, _ string, data []byte) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid image key %q", key)
+	}
+
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), target)
+}
+
+func (s *LocalImageStorage) DeleteImage(_ context.Context, key string) error {
+	if !validKey(key) {
+		return fmt.Errorf("invalid image key %q", key)
+	}
+
+	err := os.Remove(filepath.Join(s.dir, filepath.FromSlash(key)))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalImageStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestLocalImageStorage(t *testing.T) *LocalImageStorage {
+	t.Helper()
+	t.Setenv(_imageStorageDir, filepath.Join(t.TempDir(), "uploads"))
+	t.Setenv(_imagePublicBaseURL, "")
+
+	storage, err := NewLocalImageStorage()
+	require.NoError(t, err)
+	return storage
+}
+
+func TestNewLocalImageStorage(t *testing.T) {
+	storage := newTestLocalImageStorage(t)
+
+	info, err := os.Stat(storage.Dir())
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+	require.Equal(t, "/media", storage.BaseURL())
+	require.Equal(t, "/media/auctions/1/image.jpg", storage.URL("auctions/1/image.jpg"))
+}
+
+func TestLocalImageStoragePutImage(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "nested key", key: "auctions/1/image.jpg"},
+		{name: "top level key", key: "image.jpg"},
+		{name: "key escaping the directory", key: "../image.jpg", wantErr: true},
+		{name: "absolute key", key: "/image.jpg", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newTestLocalImageStorage(t)
+
+			err := storage.PutImage(context.Background(), tt.key, "image/jpeg", []byte("image"))
+
+			if tt.wantErr {
+				require.Error(t, err)
+				entries, err := os.ReadDir(filepath.Dir(storage.Dir()))
+				require.NoError(t, err)
+				require.Len(t, entries, 1)
+				return
+			}
+			require.NoError(t, err)
+			data, err := os.ReadFile(filepath.Join(storage.Dir(), filepath.FromSlash(tt.key)))
+			require.NoError(t, err)
+			require.Equal(t, "image", string(data))
+
+			entries, err := os.ReadDir(filepath.Dir(filepath.Join(storage.Dir(), filepath.FromSlash(tt.key))))
+			require.NoError(t, err)
+			require.Len(t, entries, 1, "temporary upload file left behind")
+		})
+	}
+}
+
+func TestLocalImageStorageDeleteImage(t *testing.T) {
+	storage := newTestLocalImageStorage(t)
+	ctx := context.Background()
+	require.NoError(t, storage.PutImage(ctx, "auctions/1/image.jpg", "image/jpeg", []byte("image")))
+
+	require.NoError(t, storage.DeleteImage(ctx, "auctions/1/image.jpg"))
+	_, err := os.Stat(filepath.Join(storage.Dir(), "auctions", "1", "image.jpg"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, storage.DeleteImage(ctx, "auctions/1/image.jpg"), "deleting a missing image")
+	require.Error(t, storage.DeleteImage(ctx, "../image.jpg"))
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	_s3Endpoint        = "S3_ENDPOINT"
+	_s3Region          = "S3_REGION"
+	_s3Bucket          = "S3_BUCKET"
+	_s3AccessKeyId     = "S3_ACCESS_KEY_ID"
+	_s3SecretAccessKey = "S3_SECRET_ACCESS_KEY"
+	_s3PublicBaseURL   = "S3_PUBLIC_BASE_URL"
+	_defaultS3Region   = "us-east-1"
+
+	_s3Algorithm = "AWS4-HMAC-SHA256"
+	_s3Service   = "s3"
+	_s3Timeout   = 30 * time.Second
+)
+
+// S3ImageStorage stores images in a bucket of any S3-compatible service,
+// using path-style addressing and Signature Version 4.
+type S3ImageStorage struct {
+	endpoint        string
+	region          string
+	bucket          string
+	accessKeyId     string
+	secretAccessKey string
+	publicBaseURL   string
+	client          *http.Client
+}
+
+func NewS3ImageStorage() (*S3ImageStorage, error) {
+	storage := &S3ImageStorage{
+		endpoint:        strings.TrimRight(os.Getenv(_s3Endpoint), "/"),
+		region:          os.Getenv(_s3Region),
+		bucket:          os.Getenv(_s3Bucket),
+		accessKeyId:     os.Getenv(_s3AccessKeyId),
+		secretAccessKey: os.Getenv(_s3SecretAccessKey),
+		publicBaseURL:   os.Getenv(_s3PublicBaseURL),
+		client:          &http.Client{Timeout: _s3Timeout},
+	}
+	if storage.endpoint == "" || storage.bucket == "" {
+		return nil, errors.New("S3 image storage needs S3_ENDPOINT and S3_BUCKET")
+	}
+	if storage.accessKeyId == "" || storage.secretAccessKey == "" {
+		return nil, errors.New("S3 image storage needs S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY")
+	}
+	if storage.region == "" {
+		storage.region = _defaultS3Region
+	}
+	if storage.publicBaseURL == "" {
+		storage.publicBaseURL = storage.endpoint + "/" + storage.bucket
+	}
+	return storage, nil
+}
+
+func (s *S3ImageStorage) PutImage(ctx context.Context, key, contentType string, data []byte) error {
+	response, err := s.do(ctx, http.MethodPut, key, contentType, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return s.responseError(response, "uploading", key)
+	}
+	return nil
+}
+
+// DeleteImage removes the object; deleting one that is already gone is not
+// an error.
+func (s *S3ImageStorage) DeleteImage(ctx context.Context, key string) error {
+	response, err := s.do(ctx, http.MethodDelete, key, "", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent &&
+		response.StatusCode != http.StatusOK &&
+		response.StatusCode != http.StatusNotFound {
+		return s.responseError(response, "deleting", key)
+	}
+	return nil
+}
+
+func (s *S3ImageStorage) URL(key string) string {
+	return joinURL(s.publicBaseURL, key)
+}
+
+func (s *S3ImageStorage) do(
+	ctx context.Context, method, key, contentType string, data []byte) (*http.Response, error) {
+	if !validKey(key) {
+		return nil, fmt.Errorf("invalid image key %q", key)
+	}
+
+	objectPath := "/" + s.bucket + "/" + encodeS3Path(key)
+	request, err := http.NewRequestWithContext(ctx, method, s.endpoint+objectPath, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	s.sign(request, objectPath, data, time.Now())
+
+	return s.client.Do(request)
+}
+
+// sign adds the Signature Version 4 authorization header, covering the host,
+// the payload hash and the request time.
+func (s *S3ImageStorage) sign(request *http.Request, objectPath string, payload []byte, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	payloadHash := sha256Hex(payload)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + request.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		request.Method, objectPath, "", canonicalHeaders, signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.region, _s3Service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		_s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, _s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		_s3Algorithm, s.accessKeyId, scope, signedHeaders, signature))
+}
+
+func (s *S3ImageStorage) responseError(response *http.Response, operation, key string) error {
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("%s image %s: bucket answered %s: %s",
+		operation, key, response.Status, strings.TrimSpace(string(body)))
+}
+
+// encodeS3Path escapes every byte of the key outside the unreserved set,
+// keeping the slashes, as Signature Version 4 expects.
+func encodeS3Path(key string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var encoded strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			encoded.WriteByte(c)
+		default:
+			encoded.WriteByte('%')
+			encoded.WriteByte(hexDigits[c>>4])
+			encoded.WriteByte(hexDigits[c&0x0f])
+		}
+	}
+	return encoded.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testS3AccessKeyId     = "test-access-key"
+	testS3SecretAccessKey = "test-secret-key"
+)
+
+// fakeS3 serves the object API of a single bucket, answering with 403 the
+// requests whose signature does not match the secret key.
+type fakeS3 struct {
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string]fakeS3Object
+}
+
+type fakeS3Object struct {
+	contentType string
+	data        []byte
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	fake := &fakeS3{bucket: bucket, objects: map[string]fakeS3Object{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := verifyS3Signature(r, body); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if !ok {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeS3Object{contentType: r.Header.Get("Content-Type"), data: body}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) object(key string) (fakeS3Object, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, ok := f.objects[key]
+	return object, ok
+}
+
+// verifyS3Signature recomputes the Signature Version 4 of a request from what
+// was received on the wire.
+func verifyS3Signature(r *http.Request, body []byte) error {
+	if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+		return fmt.Errorf("payload hash mismatch")
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) != len("20060102T150405Z") {
+		return fmt.Errorf("invalid X-Amz-Date %q", amzDate)
+	}
+	scope := amzDate[:8] + "/" + _defaultS3Region + "/s3/aws4_request"
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		"",
+		"host:" + r.Host + "\n" +
+			"x-amz-content-sha256:" + r.Header.Get("X-Amz-Content-Sha256") + "\n" +
+			"x-amz-date:" + amzDate + "\n",
+		"host;x-amz-content-sha256;x-amz-date",
+		r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := []byte("AWS4" + testS3SecretAccessKey)
+	for _, part := range []string{amzDate[:8], _defaultS3Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	want := "AWS4-HMAC-SHA256 Credential=" + testS3AccessKeyId + "/" + scope +
+		", SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=" + signature
+	if !hmac.Equal([]byte(r.Header.Get("Authorization")), []byte(want)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
+func newTestS3ImageStorage(t *testing.T, endpoint, bucket, secretAccessKey string) *S3ImageStorage {
+	t.Helper()
+	t.Setenv(_s3Endpoint, endpoint)
+	t.Setenv(_s3Region, "")
+	t.Setenv(_s3Bucket, bucket)
+	t.Setenv(_s3AccessKeyId, testS3AccessKeyId)
+	t.Setenv(_s3SecretAccessKey, secretAccessKey)
+	t.Setenv(_s3PublicBaseURL, "")
+
+	storage, err := NewS3ImageStorage()
+	require.NoError(t, err)
+	return storage
+}
+
+func TestNewS3ImageStorage(t *testing.T) {
+	tests := []struct {
+		name          string
+		endpoint      string
+		bucket        string
+		publicBaseURL string
+		wantURL       string
+		wantErr       bool
+	}{
+		{name: "bucket URL by default", endpoint: "http://minio:9000/", bucket: "images",
+			wantURL: "http://minio:9000/images/a.jpg"},
+		{name: "public base URL", endpoint: "http://minio:9000", bucket: "images",
+			publicBaseURL: "https://cdn.example.com", wantURL: "https://cdn.example.com/a.jpg"},
+		{name: "no endpoint", bucket: "images", wantErr: true},
+		{name: "no bucket", endpoint: "http://minio:9000", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(_s3Endpoint, tt.endpoint)
+			t.Setenv(_s3Bucket, tt.bucket)
+			t.Setenv(_s3AccessKeyId, testS3AccessKeyId)
+			t.Setenv(_s3SecretAccessKey, testS3SecretAccessKey)
+			t.Setenv(_s3PublicBaseURL, tt.publicBaseURL)
+
+			storage, err := NewS3ImageStorage()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantURL, storage.URL("a.jpg"))
+		})
+	}
+}
+
+func TestS3ImageStoragePutImage(t *testing.T) {
+	tests := []struct {
+		name            string
+		key             string
+		bucket          string
+		secretAccessKey string
+		wantErr         bool
+	}{
+		{name: "stored", key: "auctions/1/image.jpg", bucket: "images", secretAccessKey: testS3SecretAccessKey},
+		{name: "key needing escaping", key: "auctions/1/image name+1.jpg", bucket: "images",
+			secretAccessKey: testS3SecretAccessKey},
+		{name: "wrong secret", key: "image.jpg", bucket: "images", secretAccessKey: "wrong", wantErr: true},
+		{name: "unknown bucket", key: "image.jpg", bucket: "missing", secretAccessKey: testS3SecretAccessKey,
+			wantErr: true},
+		{name: "invalid key", key: "../image.jpg", bucket: "images", secretAccessKey: testS3SecretAccessKey,
+			wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, server := newFakeS3(t, "images")
+			storage := newTestS3ImageStorage(t, server.URL, tt.bucket, tt.secretAccessKey)
+
+			err := storage.PutImage(context.Background(), tt.key, "image/png", []byte("image"))
+
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Empty(t, fake.objects)
+				return
+			}
+			require.NoError(t, err)
+			object, ok := fake.object(tt.key)
+			require.True(t, ok)
+			require.Equal(t, "image/png", object.contentType)
+			require.Equal(t, "image", string(object.data))
+		})
+	}
+}
+
+func TestS3ImageStorageDeleteImage(t *testing.T) {
+	fake, server := newFakeS3(t, "images")
+	storage := newTestS3ImageStorage(t, server.URL, "images", testS3SecretAccessKey)
+	ctx := context.Background()
+	require.NoError(t, storage.PutImage(ctx, "auctions/1/image.jpg", "image/jpeg", []byte("image")))
+
+	require.NoError(t, storage.DeleteImage(ctx, "auctions/1/image.jpg"))
+	_, ok := fake.object("auctions/1/image.jpg")
+	require.False(t, ok)
+
+	require.NoError(t, storage.DeleteImage(ctx, "auctions/1/image.jpg"), "deleting a missing image")
+	require.Error(t, storage.DeleteImage(ctx, "/image.jpg"))
+}
+
+func TestEncodeS3Path(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "auctions/1/image.jpg", want: "auctions/1/image.jpg"},
+		{key: "a b+c.jpg", want: "a%20b%2Bc.jpg"},
+		{key: "ção.jpg", want: "%C3%A7%C3%A3o.jpg"},
+		{key: "a~b_c-d.jpg", want: "a~b_c-d.jpg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			require.Equal(t, tt.want, encodeS3Path(tt.key))
+		})
+	}
+}
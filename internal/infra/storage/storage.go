@@ -0,0 +1,40 @@
+// Package storage keeps auction image files, either on the local filesystem
+// or in an S3-compatible bucket.
+package storage
+
+import (
+	"fmt"
+	"fullcycle-auction_go/internal/repository"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	_imageStorageBackend = "IMAGE_STORAGE_BACKEND"
+	_backendLocal        = "local"
+	_backendS3           = "s3"
+)
+
+// NewImageStorage builds the backend selected by IMAGE_STORAGE_BACKEND,
+// which defaults to the local filesystem.
+func NewImageStorage() (repository.ImageStorage, error) {
+	switch backend := os.Getenv(_imageStorageBackend); backend {
+	case "", _backendLocal:
+		return NewLocalImageStorage()
+	case _backendS3:
+		return NewS3ImageStorage()
+	default:
+		return nil, fmt.Errorf("unknown image storage backend %q", backend)
+	}
+}
+
+// validKey rejects keys that would escape the storage root once joined to it.
+func validKey(key string) bool {
+	return key != "" && !strings.HasPrefix(key, "/") && path.Clean(key) == key &&
+		key != ".." && !strings.HasPrefix(key, "../")
+}
+
+func joinURL(baseURL, key string) string {
+	return strings.TrimRight(baseURL, "/") + "/" + key
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "auctions/1/image.jpg", want: true},
+		{key: "image.jpg", want: true},
+		{key: "auctions/1/thumbnails/image name.jpg", want: true},
+		{key: ""},
+		{key: "/etc/passwd"},
+		{key: ".."},
+		{key: "../image.jpg"},
+		{key: "auctions/../../image.jpg"},
+		{key: "auctions/./image.jpg"},
+		{key: "auctions//image.jpg"},
+		{key: "auctions/1/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			require.Equal(t, tt.want, validKey(tt.key))
+		})
+	}
+}
+
+func TestNewImageStorage(t *testing.T) {
+	tests := []struct {
+		name    string
+		backend string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "default", want: &LocalImageStorage{}},
+		{name: "local", backend: "local", want: &LocalImageStorage{}},
+		{name: "s3", backend: "s3", want: &S3ImageStorage{}},
+		{name: "unknown", backend: "ftp", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(_imageStorageBackend, tt.backend)
+			t.Setenv(_imageStorageDir, t.TempDir())
+			t.Setenv(_s3Endpoint, "http://127.0.0.1:9000")
+			t.Setenv(_s3Bucket, "images")
+			t.Setenv(_s3AccessKeyId, "access")
+			t.Setenv(_s3SecretAccessKey, "secret")
+
+			storage, err := NewImageStorage()
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, tt.want, storage)
+		})
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	require.Equal(t, "/media/a/b.jpg", joinURL("/media", "a/b.jpg"))
+	require.Equal(t, "https://cdn.example.com/a.jpg", joinURL("https://cdn.example.com/", "a.jpg"))
+}
@@ -0,0 +1,59 @@
+// Package webhook posts webhook deliveries to their subscribers over HTTP.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	_sendTimeout = 10 * time.Second
+
+	// _maxResponseBody is how much of a receiver's answer is read before the
+	// connection is given back; the body itself is not kept.
+	_maxResponseBody = 64 << 10
+)
+
+// HTTPWebhookSender posts deliveries as JSON. Redirects are not followed, so
+// a delivery only counts when the subscribed URL itself accepts it.
+type HTTPWebhookSender struct {
+	client *http.Client
+}
+
+func NewHTTPWebhookSender() *HTTPWebhookSender {
+	return &HTTPWebhookSender{
+		client: &http.Client{
+			Timeout: _sendTimeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+func (s *HTTPWebhookSender) Send(
+	ctx context.Context,
+	url string,
+	headers map[string]string,
+	payload []byte,
+) (int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, _maxResponseBody))
+	return response.StatusCode, nil
+}
@@ -29,3 +29,31 @@ func NewBadRequestError(message string) *InternalError {
 		Err:     "bad_request",
 	}
 }
+
+func NewConflictError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "conflict",
+	}
+}
+
+func NewVersionConflictError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "version_conflict",
+	}
+}
+
+func NewForbiddenError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "forbidden",
+	}
+}
+
+func NewUnauthorizedError(message string) *InternalError {
+	return &InternalError{
+		Message: message,
+		Err:     "unauthorized",
+	}
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, apiKey *entity.APIKey) error
+
+	FindAPIKeyById(ctx context.Context, id string) (*entity.APIKey, error)
+
+	FindAPIKeyByHash(ctx context.Context, hash string) (*entity.APIKey, error)
+
+	FindAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+
+	RevokeAPIKey(ctx context.Context, apiKey *entity.APIKey) error
+
+	RotateAPIKey(ctx context.Context, apiKey, rotated *entity.APIKey) error
+}
@@ -8,7 +8,28 @@ import (
 type AuctionRepository interface {
 	CreateAuction(ctx context.Context, auction *entity.Auction) error
 
-	FindAuctions(ctx context.Context, status entity.AuctionStatus, category, productName string) ([]entity.Auction, error)
+	FindAuctions(ctx context.Context, query entity.AuctionQuery, page entity.PageRequest) (*entity.Page[entity.Auction], error)
 
 	FindAuctionById(ctx context.Context, id string) (*entity.Auction, error)
+
+	FindAuctionsByIds(ctx context.Context, ids []string) ([]entity.Auction, error)
+
+	UpdateAuctionStatus(ctx context.Context, auction *entity.Auction, previousStatus entity.AuctionStatus) error
+
+	UpdateAuction(ctx context.Context, auction *entity.Auction, requireNoBids bool) error
+
+	FindAuctionFacets(
+		ctx context.Context,
+		query entity.AuctionQuery,
+		definitions []entity.AttributeDefinition) ([]entity.AttributeFacet, error)
+
+	HasAuctionsInCategory(ctx context.Context, category string) (bool, error)
+
+	FindAuctionCategories(ctx context.Context) ([]string, error)
+
+	RenameAuctionCategory(ctx context.Context, from, to string) error
+
+	UpdateAuctionImages(ctx context.Context, auction *entity.Auction, previous []entity.AuctionImage) error
+
+	IsImageInUse(ctx context.Context, key string) (bool, error)
 }
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+// AuctionEventPublisher hands auction events to whoever reacts to them.
+// Publishing must not block the caller on slow subscribers.
+type AuctionEventPublisher interface {
+	Publish(ctx context.Context, event entity.AuctionEvent)
+}
+
+// AuctionEventOutbox holds the auction events that must reach outside
+// services until they are acknowledged.
+type AuctionEventOutbox interface {
+	// FindOutboxEvents returns the events waiting in the outbox of up to limit
+	// auctions, in the order they were recorded for each auction.
+	FindOutboxEvents(ctx context.Context, limit int) ([]entity.AuctionEvent, error)
+
+	AcknowledgeOutboxEvents(ctx context.Context, events []entity.AuctionEvent) error
+}
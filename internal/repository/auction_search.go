@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+type AuctionSearchRepository interface {
+	SearchAuctions(ctx context.Context, text string, limit int) ([]entity.AuctionSearchHit, error)
+}
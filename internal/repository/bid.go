@@ -8,7 +8,12 @@ import (
 type BidRepository interface {
 	CreateBid(ctx context.Context, bidEntities []entity.Bid) error
 
-	FindBidByAuctionId(ctx context.Context, auctionId string) ([]entity.Bid, error)
+	FindBidByAuctionId(ctx context.Context, auctionId string, page entity.PageRequest) (*entity.Page[entity.Bid], error)
 
 	FindWinningBidByAuctionId(ctx context.Context, auctionId string) (*entity.Bid, error)
 }
+
+// BidQueue writes the bids still waiting for their batch insert on FlushBids.
+type BidQueue interface {
+	FlushBids(ctx context.Context) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category *entity.Category) error
+
+	UpdateCategory(ctx context.Context, category *entity.Category) error
+
+	DeleteCategory(ctx context.Context, categoryId string) error
+
+	FindCategoryById(ctx context.Context, categoryId string) (*entity.Category, error)
+
+	FindCategoryBySlug(ctx context.Context, slug string) (*entity.Category, error)
+
+	FindCategories(ctx context.Context) ([]entity.Category, error)
+
+	FindDescendants(ctx context.Context, categoryId string) ([]entity.Category, error)
+}
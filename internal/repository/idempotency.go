@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+type IdempotencyRepository interface {
+	// CreateRecord must fail with a conflict error when the key already exists.
+	CreateRecord(ctx context.Context, record *entity.IdempotencyRecord) error
+
+	FindRecord(ctx context.Context, key string) (*entity.IdempotencyRecord, error)
+
+	CompleteRecord(ctx context.Context, record *entity.IdempotencyRecord) error
+
+	DeleteRecord(ctx context.Context, key string) error
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// ImageStorage keeps the files of auction images. Keys are slash separated
+// paths chosen by the caller; URL tells where clients can download a file.
+type ImageStorage interface {
+	PutImage(ctx context.Context, key, contentType string, data []byte) error
+
+	DeleteImage(ctx context.Context, key string) error
+
+	URL(key string) string
+}
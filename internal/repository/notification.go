@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+// Notifier delivers a notification to its user, by email or any other
+// channel.
+type Notifier interface {
+	Notify(ctx context.Context, notification entity.Notification) error
+}
+
+type NotificationPreferencesRepository interface {
+	// FindNotificationPreferences returns the defaults, every notification
+	// enabled, for users who never changed them.
+	FindNotificationPreferences(ctx context.Context, userId string) (*entity.NotificationPreferences, error)
+
+	SaveNotificationPreferences(ctx context.Context, preferences *entity.NotificationPreferences) error
+}
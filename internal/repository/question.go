@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+type QuestionRepository interface {
+	CreateQuestion(ctx context.Context, question *entity.Question) error
+
+	// UpdateQuestion saves the question if it is still at the version it was
+	// read at, and bumps that version.
+	UpdateQuestion(ctx context.Context, question *entity.Question) error
+
+	FindQuestionById(ctx context.Context, questionId string) (*entity.Question, error)
+
+	FindQuestionsByAuctionId(
+		ctx context.Context, auctionId string, page entity.PageRequest) (*entity.Page[entity.Question], error)
+
+	FindFlaggedQuestions(ctx context.Context, page entity.PageRequest) (*entity.Page[entity.Question], error)
+}
@@ -6,5 +6,11 @@ import (
 )
 
 type UserRepository interface {
+	CreateUser(ctx context.Context, user *entity.User) error
+
+	UpdateUser(ctx context.Context, user *entity.User) error
+
 	FindUserById(ctx context.Context, userId string) (*entity.User, error)
+
+	FindUsers(ctx context.Context, offset, limit int64) ([]entity.User, int64, error)
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+type WatchlistRepository interface {
+	// AddToWatchlist fails with a conflict when the user already watches the
+	// auction, and returns the new watcher count.
+	AddToWatchlist(ctx context.Context, entry *entity.WatchlistEntry) (int64, error)
+
+	RemoveFromWatchlist(ctx context.Context, userId, auctionId string) error
+
+	FindWatchlistByUserId(
+		ctx context.Context, userId string, page entity.PageRequest) (*entity.Page[entity.WatchlistEntry], error)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"time"
+)
+
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *entity.Webhook) error
+
+	DeleteWebhook(ctx context.Context, webhookId string) error
+
+	FindWebhookById(ctx context.Context, webhookId string) (*entity.Webhook, error)
+
+	FindWebhooks(ctx context.Context) ([]entity.Webhook, error)
+
+	FindWebhooksByEventType(ctx context.Context, eventType entity.AuctionEventType) ([]entity.Webhook, error)
+
+	CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+
+	// ClaimDueDelivery leases a due pending delivery, or returns nil when
+	// nothing is due.
+	ClaimDueDelivery(ctx context.Context, lease time.Duration) (*entity.WebhookDelivery, error)
+
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+
+	FindDeliveryById(ctx context.Context, deliveryId string) (*entity.WebhookDelivery, error)
+
+	FindDeliveriesByWebhookId(
+		ctx context.Context, webhookId string, page entity.PageRequest) (*entity.Page[entity.WebhookDelivery], error)
+}
+
+// WebhookSender posts a signed delivery to a webhook URL and reports the
+// status code it was answered with.
+type WebhookSender interface {
+	Send(ctx context.Context, url string, headers map[string]string, payload []byte) (int, error)
+}
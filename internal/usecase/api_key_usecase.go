@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"time"
+)
+
+type (
+	APIKeyInputDTO struct {
+		Name   string   `json:"name" binding:"required,min=3,max=100"`
+		UserId string   `json:"user_id" binding:"required,uuid"`
+		Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=read bid admin"`
+	}
+
+	APIKeyOutputDTO struct {
+		Id            string     `json:"id"`
+		Name          string     `json:"name"`
+		UserId        string     `json:"user_id"`
+		Prefix        string     `json:"prefix"`
+		Scopes        []string   `json:"scopes"`
+		RotatedFromId string     `json:"rotated_from_id,omitempty"`
+		CreatedAt     time.Time  `json:"created_at" time_format:"2006-01-02 15:04:05"`
+		RevokedAt     *time.Time `json:"revoked_at,omitempty" time_format:"2006-01-02 15:04:05"`
+	}
+
+	// IssuedAPIKeyOutputDTO carries the plain key, which is only ever
+	// returned when the key is issued or rotated.
+	IssuedAPIKeyOutputDTO struct {
+		APIKeyOutputDTO
+		Key string `json:"key"`
+	}
+
+	APIKeyPrincipalDTO struct {
+		UserId string
+		Scopes []string
+	}
+
+	APIKeyUseCase interface {
+		CreateAPIKey(ctx context.Context, input APIKeyInputDTO) (*IssuedAPIKeyOutputDTO, error)
+
+		FindAPIKeys(ctx context.Context) ([]APIKeyOutputDTO, error)
+
+		RotateAPIKey(ctx context.Context, id string) (*IssuedAPIKeyOutputDTO, error)
+
+		RevokeAPIKey(ctx context.Context, id string) (*APIKeyOutputDTO, error)
+
+		AuthenticateAPIKey(ctx context.Context, plainKey string) (*APIKeyPrincipalDTO, error)
+	}
+
+	apiKeyUseCase struct {
+		apiKeyRepository repository.APIKeyRepository
+		userRepository   repository.UserRepository
+	}
+)
+
+func NewAPIKeyUseCase(
+	apiKeyRepository repository.APIKeyRepository,
+	userRepository repository.UserRepository,
+) APIKeyUseCase {
+	return &apiKeyUseCase{
+		apiKeyRepository: apiKeyRepository,
+		userRepository:   userRepository,
+	}
+}
+
+func (ku *apiKeyUseCase) CreateAPIKey(ctx context.Context, input APIKeyInputDTO) (*IssuedAPIKeyOutputDTO, error) {
+	if _, err := ku.userRepository.FindUserById(ctx, input.UserId); err != nil {
+		return nil, err
+	}
+
+	scopes := make([]entity.APIKeyScope, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scopes = append(scopes, entity.APIKeyScope(scope))
+	}
+
+	apiKey, plainKey, err := entity.CreateAPIKey(input.Name, input.UserId, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ku.apiKeyRepository.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &IssuedAPIKeyOutputDTO{
+		APIKeyOutputDTO: newAPIKeyOutputDTO(apiKey),
+		Key:             plainKey,
+	}, nil
+}
+
+func (ku *apiKeyUseCase) FindAPIKeys(ctx context.Context) ([]APIKeyOutputDTO, error) {
+	apiKeys, err := ku.apiKeyRepository.FindAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiKeyOutputs []APIKeyOutputDTO
+	for _, apiKey := range apiKeys {
+		apiKeyOutputs = append(apiKeyOutputs, newAPIKeyOutputDTO(&apiKey))
+	}
+
+	return apiKeyOutputs, nil
+}
+
+func (ku *apiKeyUseCase) RotateAPIKey(ctx context.Context, id string) (*IssuedAPIKeyOutputDTO, error) {
+	apiKey, err := ku.apiKeyRepository.FindAPIKeyById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rotated, plainKey, err := apiKey.Rotate()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ku.apiKeyRepository.RotateAPIKey(ctx, apiKey, rotated); err != nil {
+		return nil, err
+	}
+
+	return &IssuedAPIKeyOutputDTO{
+		APIKeyOutputDTO: newAPIKeyOutputDTO(rotated),
+		Key:             plainKey,
+	}, nil
+}
+
+func (ku *apiKeyUseCase) RevokeAPIKey(ctx context.Context, id string) (*APIKeyOutputDTO, error) {
+	apiKey, err := ku.apiKeyRepository.FindAPIKeyById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := apiKey.Revoke(); err != nil {
+		return nil, err
+	}
+
+	if err := ku.apiKeyRepository.RevokeAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	output := newAPIKeyOutputDTO(apiKey)
+	return &output, nil
+}
+
+func (ku *apiKeyUseCase) AuthenticateAPIKey(ctx context.Context, plainKey string) (*APIKeyPrincipalDTO, error) {
+	apiKey, err := ku.apiKeyRepository.FindAPIKeyByHash(ctx, entity.HashAPIKey(plainKey))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, internal_error.NewUnauthorizedError("Invalid API key")
+		}
+		return nil, err
+	}
+	if apiKey.IsRevoked() {
+		return nil, internal_error.NewUnauthorizedError("API key was revoked")
+	}
+
+	owner, err := ku.userRepository.FindUserById(ctx, apiKey.UserId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, internal_error.NewUnauthorizedError("Invalid API key")
+		}
+		return nil, err
+	}
+	if owner.Status == entity.UserSuspended {
+		return nil, internal_error.NewUnauthorizedError("API key owner is suspended")
+	}
+
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	return &APIKeyPrincipalDTO{
+		UserId: apiKey.UserId,
+		Scopes: scopes,
+	}, nil
+}
+
+func newAPIKeyOutputDTO(apiKey *entity.APIKey) APIKeyOutputDTO {
+	scopes := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	output := APIKeyOutputDTO{
+		Id:            apiKey.Id,
+		Name:          apiKey.Name,
+		UserId:        apiKey.UserId,
+		Prefix:        apiKey.Prefix,
+		Scopes:        scopes,
+		RotatedFromId: apiKey.RotatedFromId,
+		CreatedAt:     apiKey.CreatedAt,
+	}
+	if apiKey.IsRevoked() {
+		revokedAt := apiKey.RevokedAt
+		output.RevokedAt = &revokedAt
+	}
+	return output
+}
@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		ownerState *entity.UserStatus
+		revoked    bool
+		plainKey   string
+		wantErr    string
+	}{
+		{name: "active owner", ownerState: userStatus(entity.UserActive)},
+		{name: "unverified owner", ownerState: userStatus(entity.UserUnverified)},
+		{name: "suspended owner", ownerState: userStatus(entity.UserSuspended), wantErr: "unauthorized"},
+		{name: "deleted owner", wantErr: "unauthorized"},
+		{name: "revoked key", ownerState: userStatus(entity.UserActive), revoked: true, wantErr: "unauthorized"},
+		{name: "unknown key", ownerState: userStatus(entity.UserActive), plainKey: "ak_unknown",
+			wantErr: "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ownerId := uuid.NewString()
+			apiKey, plainKey, err := entity.CreateAPIKey("partner", ownerId, []entity.APIKeyScope{entity.APIKeyScopeBid})
+			require.NoError(t, err)
+			if tt.revoked {
+				apiKey.RevokedAt = time.Now()
+			}
+			if tt.plainKey != "" {
+				plainKey = tt.plainKey
+			}
+
+			users := newFakeUserRepository()
+			if tt.ownerState != nil {
+				users = newFakeUserRepository(&entity.User{Id: ownerId, Status: *tt.ownerState})
+			}
+			apiKeyUseCase := NewAPIKeyUseCase(&fakeAPIKeyRepository{apiKeys: []*entity.APIKey{apiKey}}, users)
+
+			principal, err := apiKeyUseCase.AuthenticateAPIKey(context.Background(), plainKey)
+
+			if tt.wantErr != "" {
+				var internalError *internal_error.InternalError
+				require.ErrorAs(t, err, &internalError)
+				require.Equal(t, tt.wantErr, internalError.Err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, &APIKeyPrincipalDTO{UserId: ownerId, Scopes: []string{"bid"}}, principal)
+		})
+	}
+}
+
+func userStatus(status entity.UserStatus) *entity.UserStatus {
+	return &status
+}
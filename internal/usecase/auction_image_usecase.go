@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+const defaultMaxImageBytes = 5 << 20
+
+type (
+	AuctionImageOutputDTO struct {
+		Id           string    `json:"id"`
+		URL          string    `json:"url"`
+		ThumbnailURL string    `json:"thumbnail_url"`
+		ContentType  string    `json:"content_type"`
+		Size         int64     `json:"size"`
+		Width        int       `json:"width"`
+		Height       int       `json:"height"`
+		Position     int       `json:"position"`
+		Primary      bool      `json:"primary"`
+		CreatedAt    time.Time `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	}
+
+	AuctionImageOrderInputDTO struct {
+		ImageIds []string `json:"image_ids" binding:"required,max=10,dive,uuid"`
+	}
+
+	AuctionImageUseCase interface {
+		UploadImage(ctx context.Context, auctionId string, image io.Reader) (*AuctionImageOutputDTO, error)
+
+		DeleteImage(ctx context.Context, auctionId, imageId string) error
+
+		ReorderImages(ctx context.Context, auctionId string, input AuctionImageOrderInputDTO) (*AuctionOutputDTO, error)
+
+		SetPrimaryImage(ctx context.Context, auctionId, imageId string) (*AuctionOutputDTO, error)
+	}
+
+	auctionImageUseCase struct {
+		auctionRepository repository.AuctionRepository
+		imageStorage      repository.ImageStorage
+		maxImageBytes     int64
+	}
+)
+
+func NewAuctionImageUseCase(
+	auctionRepository repository.AuctionRepository,
+	imageStorage repository.ImageStorage,
+) AuctionImageUseCase {
+	return &auctionImageUseCase{
+		auctionRepository: auctionRepository,
+		imageStorage:      imageStorage,
+		maxImageBytes:     getMaxImageBytes(),
+	}
+}
+
+// UploadImage stores the image and its thumbnail and appends it to the auction
+// gallery, removing the files again if the gallery cannot be saved.
+func (iu *auctionImageUseCase) UploadImage(
+	ctx context.Context,
+	auctionId string,
+	image io.Reader,
+) (*AuctionImageOutputDTO, error) {
+	data, err := io.ReadAll(io.LimitReader(image, iu.maxImageBytes+1))
+	if err != nil {
+		return nil, internal_error.NewBadRequestError("Error trying to read image file")
+	}
+	if int64(len(data)) > iu.maxImageBytes {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("Image must be at most %d bytes", iu.maxImageBytes))
+	}
+
+	auction, err := iu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := processImage(data)
+	if err != nil {
+		return nil, err
+	}
+
+	imageId := entity.NewAuctionImageId()
+	auctionImage := entity.AuctionImage{
+		Id:           imageId,
+		Key:          fmt.Sprintf("auctions/%s/%s.%s", auction.Id, imageId, processed.extension),
+		ThumbnailKey: fmt.Sprintf("auctions/%s/%s_thumb.jpg", auction.Id, imageId),
+		ContentType:  processed.contentType,
+		Size:         int64(len(data)),
+		Width:        processed.width,
+		Height:       processed.height,
+		CreatedAt:    time.Now(),
+	}
+	auctionImage.URL = iu.imageStorage.URL(auctionImage.Key)
+	auctionImage.ThumbnailURL = iu.imageStorage.URL(auctionImage.ThumbnailKey)
+
+	previous := auction.Images
+	if err := auction.AddImage(auctionImage); err != nil {
+		return nil, err
+	}
+
+	if err := iu.imageStorage.PutImage(ctx, auctionImage.Key, auctionImage.ContentType, data); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to store image %s", auctionImage.Key), err)
+		return nil, internal_error.NewInternalServerError("Error trying to store image")
+	}
+	err = iu.imageStorage.PutImage(ctx, auctionImage.ThumbnailKey, thumbnailContentType, processed.thumbnail)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to store image %s", auctionImage.ThumbnailKey), err)
+		iu.deleteFiles(ctx, auctionImage)
+		return nil, internal_error.NewInternalServerError("Error trying to store image")
+	}
+
+	if err := iu.auctionRepository.UpdateAuctionImages(ctx, auction, previous); err != nil {
+		iu.deleteFiles(ctx, auctionImage)
+		return nil, err
+	}
+
+	output := newAuctionImageOutputDTO(auction.Images[len(auction.Images)-1])
+	return &output, nil
+}
+
+// DeleteImage takes the image out of the gallery. Its files are kept while a
+// relisted copy of the auction still shows them.
+func (iu *auctionImageUseCase) DeleteImage(ctx context.Context, auctionId, imageId string) error {
+	auction, err := iu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return err
+	}
+
+	previous := auction.Images
+	removed, err := auction.RemoveImage(imageId)
+	if err != nil {
+		return err
+	}
+
+	if err := iu.auctionRepository.UpdateAuctionImages(ctx, auction, previous); err != nil {
+		return err
+	}
+
+	inUse, err := iu.auctionRepository.IsImageInUse(ctx, removed.Key)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error checking whether image %s can be deleted", removed.Key), err)
+		return nil
+	}
+	if !inUse {
+		iu.deleteFiles(ctx, *removed)
+	}
+
+	return nil
+}
+
+func (iu *auctionImageUseCase) ReorderImages(
+	ctx context.Context,
+	auctionId string,
+	input AuctionImageOrderInputDTO,
+) (*AuctionOutputDTO, error) {
+	return iu.changeImages(ctx, auctionId, func(auction *entity.Auction) error {
+		return auction.ReorderImages(input.ImageIds)
+	})
+}
+
+func (iu *auctionImageUseCase) SetPrimaryImage(
+	ctx context.Context,
+	auctionId, imageId string,
+) (*AuctionOutputDTO, error) {
+	return iu.changeImages(ctx, auctionId, func(auction *entity.Auction) error {
+		return auction.SetPrimaryImage(imageId)
+	})
+}
+
+func (iu *auctionImageUseCase) changeImages(
+	ctx context.Context,
+	auctionId string,
+	change func(auction *entity.Auction) error,
+) (*AuctionOutputDTO, error) {
+	auction, err := iu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := auction.Images
+	if err := change(auction); err != nil {
+		return nil, err
+	}
+
+	if err := iu.auctionRepository.UpdateAuctionImages(ctx, auction, previous); err != nil {
+		return nil, err
+	}
+
+	output := newAuctionOutputDTO(auction)
+	return &output, nil
+}
+
+// deleteFiles is best effort: a leftover file is only wasted space, so
+// failures are logged rather than failing the request.
+func (iu *auctionImageUseCase) deleteFiles(ctx context.Context, image entity.AuctionImage) {
+	for _, key := range []string{image.Key, image.ThumbnailKey} {
+		if err := iu.imageStorage.DeleteImage(ctx, key); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to delete image %s", key), err)
+		}
+	}
+}
+
+func newAuctionImageOutputDTO(image entity.AuctionImage) AuctionImageOutputDTO {
+	return AuctionImageOutputDTO{
+		Id:           image.Id,
+		URL:          image.URL,
+		ThumbnailURL: image.ThumbnailURL,
+		ContentType:  image.ContentType,
+		Size:         image.Size,
+		Width:        image.Width,
+		Height:       image.Height,
+		Position:     image.Position,
+		Primary:      image.Primary,
+		CreatedAt:    image.CreatedAt,
+	}
+}
+
+func getMaxImageBytes() int64 {
+	value, err := strconv.ParseInt(os.Getenv("AUCTION_IMAGE_MAX_BYTES"), 10, 64)
+	if err != nil || value <= 0 {
+		return defaultMaxImageBytes
+	}
+	return value
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/repository"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// auctionStreamReplay is how many recent events of an auction are kept
+	// for clients resuming with Last-Event-ID.
+	auctionStreamReplay = 100
+
+	auctionStreamBuffer  = 64
+	auctionStreamIdleTTL = 10 * time.Minute
+)
+
+const (
+	StreamReset            = "stream.reset"
+	StreamBidAccepted      = "bid.accepted"
+	StreamPriceChanged     = "price.changed"
+	StreamAuctionExtended  = "auction.extended"
+	StreamAuctionCompleted = "auction.completed"
+)
+
+type (
+	// AuctionStreamEventDTO is one event of the live stream of an auction.
+	// Data is the *EventDTO matching Type.
+	AuctionStreamEventDTO struct {
+		Id   uint64
+		Type string
+		Data interface{}
+	}
+
+	PriceChangedEventDTO struct {
+		AuctionId     string   `json:"auction_id"`
+		CurrentPrice  float64  `json:"current_price"`
+		PreviousPrice *float64 `json:"previous_price,omitempty"`
+	}
+
+	AuctionExtendedEventDTO struct {
+		Auction AuctionOutputDTO `json:"auction"`
+		EndsAt  time.Time        `json:"ends_at"`
+	}
+
+	// StreamResetEventDTO replaces missed events too old to replay.
+	StreamResetEventDTO struct {
+		Auction AuctionOutputDTO `json:"auction"`
+		EndsAt  time.Time        `json:"ends_at"`
+	}
+
+	// AuctionStreamSubscription replays the missed events, then hands out the
+	// live ones. Events is closed when the subscriber falls too far behind.
+	AuctionStreamSubscription struct {
+		Replay []AuctionStreamEventDTO
+		Events <-chan AuctionStreamEventDTO
+		close  func()
+	}
+
+	AuctionStreamUseCase interface {
+		// HandleAuctionEvent forwards an auction event to the clients
+		// following the auction.
+		HandleAuctionEvent(ctx context.Context, event entity.AuctionEvent)
+
+		Subscribe(ctx context.Context, auctionId, lastEventId string) (*AuctionStreamSubscription, error)
+	}
+
+	auctionStreamUseCase struct {
+		auctionRepository repository.AuctionRepository
+		auctionDuration   time.Duration
+		topics            map[string]*auctionTopic
+		lastId            uint64
+		lastSweep         time.Time
+		mutex             *sync.Mutex
+	}
+
+	// auctionTopic keeps every event of the auction after since; a client
+	// resuming from before it may have missed some.
+	auctionTopic struct {
+		recent      []AuctionStreamEventDTO
+		since       uint64
+		subscribers map[chan AuctionStreamEventDTO]struct{}
+		updatedAt   time.Time
+	}
+)
+
+func NewAuctionStreamUseCase(auctionRepository repository.AuctionRepository) AuctionStreamUseCase {
+	return &auctionStreamUseCase{
+		auctionRepository: auctionRepository,
+		auctionDuration:   entity.AuctionDuration(),
+		topics:            make(map[string]*auctionTopic),
+		// Ids start from the boot time so that they keep increasing across
+		// restarts and a stale Last-Event-ID never hides new events.
+		lastId: uint64(time.Now().UnixMilli()) * 1000,
+		mutex:  &sync.Mutex{},
+	}
+}
+
+func (su *auctionStreamUseCase) HandleAuctionEvent(_ context.Context, event entity.AuctionEvent) {
+	switch event.Type {
+	case entity.EventBidAccepted:
+		data := BidAcceptedEventDTO{Bid: newBidOutputDTO(event.Bid), Leading: event.Leading}
+		if event.PreviousBid != nil {
+			previousBid := newBidOutputDTO(event.PreviousBid)
+			data.PreviousBid = &previousBid
+		}
+		events := []AuctionStreamEventDTO{{Type: StreamBidAccepted, Data: data}}
+
+		// Only a bid taking the lead moves the current price.
+		if event.Leading {
+			price := PriceChangedEventDTO{AuctionId: event.AuctionId, CurrentPrice: event.Bid.Amount}
+			if event.PreviousBid != nil {
+				price.PreviousPrice = &event.PreviousBid.Amount
+			}
+			events = append(events, AuctionStreamEventDTO{Type: StreamPriceChanged, Data: price})
+		}
+		su.publish(event.AuctionId, events...)
+	case entity.EventAuctionExtended:
+		su.publish(event.AuctionId, AuctionStreamEventDTO{
+			Type: StreamAuctionExtended,
+			Data: AuctionExtendedEventDTO{Auction: newAuctionOutputDTO(event.Auction), EndsAt: event.EndsAt},
+		})
+	case entity.EventAuctionCompleted:
+		data := newAuctionCompletedEventDTO(event)
+		su.publish(event.AuctionId, AuctionStreamEventDTO{Type: StreamAuctionCompleted, Data: data})
+	}
+}
+
+func (su *auctionStreamUseCase) Subscribe(
+	ctx context.Context,
+	auctionId, lastEventId string,
+) (*AuctionStreamSubscription, error) {
+	// The snapshot of a reset is read after snapshotId, so replaying the
+	// events after it cannot leave anything out.
+	su.mutex.Lock()
+	snapshotId := su.lastId
+	su.mutex.Unlock()
+
+	auction, err := su.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	// A missing or malformed Last-Event-ID starts from the live events only.
+	lastId, _ := strconv.ParseUint(lastEventId, 10, 64)
+
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+
+	topic := su.topic(auctionId)
+	subscription := &AuctionStreamSubscription{}
+	if lastId > 0 && lastId < topic.since {
+		subscription.Replay = append(subscription.Replay, AuctionStreamEventDTO{
+			Id:   snapshotId,
+			Type: StreamReset,
+			Data: StreamResetEventDTO{
+				Auction: newAuctionOutputDTO(auction),
+				EndsAt:  auction.EndsAt(su.auctionDuration),
+			},
+		})
+		lastId = snapshotId
+	}
+	if lastId > 0 {
+		for _, event := range topic.recent {
+			if event.Id > lastId {
+				subscription.Replay = append(subscription.Replay, event)
+			}
+		}
+	}
+
+	events := make(chan AuctionStreamEventDTO, auctionStreamBuffer)
+	topic.subscribers[events] = struct{}{}
+	subscription.Events = events
+	subscription.close = func() {
+		su.mutex.Lock()
+		defer su.mutex.Unlock()
+
+		if _, ok := topic.subscribers[events]; ok {
+			delete(topic.subscribers, events)
+			close(events)
+		}
+	}
+
+	return subscription, nil
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *AuctionStreamSubscription) Close() {
+	s.close()
+}
+
+// publish numbers the events, keeps them for replay and hands them to the
+// subscribers of the auction, dropping those whose queue is full.
+func (su *auctionStreamUseCase) publish(auctionId string, events ...AuctionStreamEventDTO) {
+	su.mutex.Lock()
+	defer su.mutex.Unlock()
+
+	topic := su.topic(auctionId)
+	for _, event := range events {
+		su.lastId++
+		event.Id = su.lastId
+
+		topic.recent = append(topic.recent, event)
+		if len(topic.recent) > auctionStreamReplay {
+			evicted := len(topic.recent) - auctionStreamReplay
+			topic.since = topic.recent[evicted-1].Id
+			topic.recent = topic.recent[evicted:]
+		}
+
+		for subscriber := range topic.subscribers {
+			select {
+			case subscriber <- event:
+			default:
+				delete(topic.subscribers, subscriber)
+				close(subscriber)
+			}
+		}
+	}
+
+	now := time.Now()
+	topic.updatedAt = now
+
+	// Topics nobody follows are swept once they have been idle long enough
+	// that no client could still resume from them.
+	if now.Sub(su.lastSweep) >= auctionStreamIdleTTL {
+		for id, other := range su.topics {
+			if len(other.subscribers) == 0 && now.Sub(other.updatedAt) >= auctionStreamIdleTTL {
+				delete(su.topics, id)
+			}
+		}
+		su.lastSweep = now
+	}
+}
+
+// topic returns the topic of an auction, creating it when needed. The caller
+// holds the mutex.
+func (su *auctionStreamUseCase) topic(auctionId string) *auctionTopic {
+	topic, ok := su.topics[auctionId]
+	if !ok {
+		topic = &auctionTopic{
+			since:       su.lastId,
+			subscribers: make(map[chan AuctionStreamEventDTO]struct{}),
+			updatedAt:   time.Now(),
+		}
+		su.topics[auctionId] = topic
+	}
+	return topic
+}
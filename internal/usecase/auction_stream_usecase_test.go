@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAuctionStreamSubscribe(t *testing.T) {
+	auction := &entity.Auction{Id: "auction", Status: entity.Active, CurrentPrice: 42, Timestamp: time.Now()}
+	quiet := &entity.Auction{Id: "quiet", Status: entity.Active, Timestamp: time.Now()}
+
+	tests := []struct {
+		name        string
+		auctionId   string
+		published   int
+		lastEventId func(ids []uint64) string
+		wantReset   bool
+		wantReplay  int
+	}{
+		{name: "without last event id", auctionId: "auction", published: 5,
+			lastEventId: func([]uint64) string { return "" }},
+		{name: "malformed last event id", auctionId: "auction", published: 5,
+			lastEventId: func([]uint64) string { return "latest" }},
+		{name: "resuming from a kept event", auctionId: "auction", published: 5,
+			lastEventId: func(ids []uint64) string { return strconv.FormatUint(ids[2], 10) }, wantReplay: 2},
+		{name: "resuming from an evicted event", auctionId: "auction", published: auctionStreamReplay + 5,
+			lastEventId: func(ids []uint64) string { return strconv.FormatUint(ids[2], 10) }, wantReset: true},
+		{name: "resuming from the oldest kept event", auctionId: "auction",
+			published:   auctionStreamReplay + 5,
+			lastEventId: func(ids []uint64) string { return strconv.FormatUint(ids[5], 10) }, wantReplay: auctionStreamReplay - 1},
+		{name: "resuming from before the topic existed", auctionId: "quiet", published: 5,
+			lastEventId: func(ids []uint64) string { return strconv.FormatUint(ids[0], 10) }, wantReset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamUseCase := NewAuctionStreamUseCase(newFakeAuctionRepository(auction, quiet)).(*auctionStreamUseCase)
+
+			ids := make([]uint64, 0, tt.published)
+			for i := 0; i < tt.published; i++ {
+				event := entity.NewAuctionEvent(entity.EventBidAccepted, auction.Id)
+				event.Bid = &entity.Bid{Id: strconv.Itoa(i), AuctionId: auction.Id, Amount: 1}
+				streamUseCase.HandleAuctionEvent(context.Background(), event)
+				ids = append(ids, streamUseCase.lastId)
+			}
+
+			subscription, err := streamUseCase.Subscribe(context.Background(), tt.auctionId, tt.lastEventId(ids))
+			require.NoError(t, err)
+			defer subscription.Close()
+
+			replay := subscription.Replay
+			if tt.wantReset {
+				require.NotEmpty(t, replay)
+				require.Equal(t, StreamReset, replay[0].Type)
+				require.Equal(t, streamUseCase.lastId, replay[0].Id)
+				reset := replay[0].Data.(StreamResetEventDTO)
+				require.Equal(t, tt.auctionId, reset.Auction.Id)
+				replay = replay[1:]
+			}
+			require.Len(t, replay, tt.wantReplay)
+			for _, event := range replay {
+				require.Equal(t, StreamBidAccepted, event.Type)
+			}
+		})
+	}
+}
+
+func TestAuctionStreamSubscribeUnknownAuction(t *testing.T) {
+	streamUseCase := NewAuctionStreamUseCase(newFakeAuctionRepository())
+
+	_, err := streamUseCase.Subscribe(context.Background(), "missing", "1")
+	require.True(t, isNotFound(err))
+}
+
+func TestAuctionStreamHandleAuctionEvent(t *testing.T) {
+	auction := &entity.Auction{Id: "auction", Status: entity.Active, ReservePrice: 10, Timestamp: time.Now()}
+	endsAt := time.Now().Add(time.Minute)
+
+	accepted := func(leading bool) entity.AuctionEvent {
+		event := entity.NewAuctionEvent(entity.EventBidAccepted, auction.Id)
+		event.Bid, event.Leading = &entity.Bid{AuctionId: auction.Id, Amount: 12}, leading
+		return event
+	}
+	extended := entity.NewAuctionEvent(entity.EventAuctionExtended, auction.Id)
+	extended.Auction, extended.EndsAt = auction, endsAt
+	completed := entity.NewAuctionEvent(entity.EventAuctionCompleted, auction.Id)
+	completed.Auction, completed.Bid = auction, &entity.Bid{AuctionId: auction.Id, Amount: 12}
+	created := entity.NewAuctionEvent(entity.EventAuctionCreated, auction.Id)
+	created.Auction = auction
+
+	tests := []struct {
+		name  string
+		event entity.AuctionEvent
+		want  []string
+	}{
+		{name: "leading bid", event: accepted(true), want: []string{StreamBidAccepted, StreamPriceChanged}},
+		{name: "overtaken bid", event: accepted(false), want: []string{StreamBidAccepted}},
+		{name: "extended", event: extended, want: []string{StreamAuctionExtended}},
+		{name: "completed", event: completed, want: []string{StreamAuctionCompleted}},
+		{name: "created", event: created},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamUseCase := NewAuctionStreamUseCase(newFakeAuctionRepository(auction))
+			subscription, err := streamUseCase.Subscribe(context.Background(), auction.Id, "")
+			require.NoError(t, err)
+			defer subscription.Close()
+
+			streamUseCase.HandleAuctionEvent(context.Background(), tt.event)
+
+			var types []string
+			for len(subscription.Events) > 0 {
+				event := <-subscription.Events
+				types = append(types, event.Type)
+				if event.Type == StreamAuctionExtended {
+					require.Equal(t, endsAt, event.Data.(AuctionExtendedEventDTO).EndsAt)
+				}
+			}
+			require.Equal(t, tt.want, types)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/internal_error"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+// apiKeyScopeRoles grants each API key scope the roles checked by the policy.
+var apiKeyScopeRoles = map[string][]string{
+	"read":  {auth.RoleReader},
+	"bid":   {auth.RoleBidder},
+	"admin": {auth.RoleAdmin},
+}
+
+type (
+	AuthUseCase interface {
+		// Authenticate identifies the caller by its API key or, when there is
+		// none, by the bearer token of the authorization value.
+		Authenticate(ctx context.Context, apiKey, authorization string) (*auth.Principal, error)
+	}
+
+	authUseCase struct {
+		verifier      *auth.JWTVerifier
+		apiKeyUseCase APIKeyUseCase
+	}
+)
+
+func NewAuthUseCase(verifier *auth.JWTVerifier, apiKeyUseCase APIKeyUseCase) AuthUseCase {
+	return &authUseCase{
+		verifier:      verifier,
+		apiKeyUseCase: apiKeyUseCase,
+	}
+}
+
+func (au *authUseCase) Authenticate(ctx context.Context, apiKey, authorization string) (*auth.Principal, error) {
+	if apiKey != "" {
+		apiKeyPrincipal, err := au.apiKeyUseCase.AuthenticateAPIKey(ctx, apiKey)
+		if err != nil {
+			return nil, err
+		}
+
+		principal := &auth.Principal{UserId: apiKeyPrincipal.UserId}
+		for _, scope := range apiKeyPrincipal.Scopes {
+			principal.Roles = append(principal.Roles, apiKeyScopeRoles[scope]...)
+		}
+		return principal, nil
+	}
+
+	if !strings.HasPrefix(authorization, bearerPrefix) {
+		return nil, internal_error.NewUnauthorizedError("Missing bearer token or API key")
+	}
+
+	principal, err := au.verifier.Verify(strings.TrimPrefix(authorization, bearerPrefix))
+	if err != nil {
+		return nil, internal_error.NewUnauthorizedError("Invalid bearer token")
+	}
+	return principal, nil
+}
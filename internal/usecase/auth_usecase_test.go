@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/configuration/auth"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestAuthUseCaseAuthenticate(t *testing.T) {
+	const secret = "test-secret"
+	t.Setenv("JWT_HS256_SECRET", secret)
+	t.Setenv("JWT_RS256_PUBLIC_KEY_FILE", "")
+	t.Setenv("JWT_JWKS_FILE", "")
+	verifier, err := auth.NewJWTVerifier()
+	require.NoError(t, err)
+
+	ownerId := uuid.NewString()
+	apiKey, plainKey, err := entity.CreateAPIKey("partner", ownerId,
+		[]entity.APIKeyScope{entity.APIKeyScopeRead, entity.APIKeyScopeBid})
+	require.NoError(t, err)
+	authUseCase := NewAuthUseCase(verifier, NewAPIKeyUseCase(
+		&fakeAPIKeyRepository{apiKeys: []*entity.APIKey{apiKey}},
+		newFakeUserRepository(&entity.User{Id: ownerId, Status: entity.UserActive})))
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	token := func(key string, expiresAt time.Time) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":   "user-1",
+			"exp":   expiresAt.Unix(),
+			"roles": []string{auth.RoleBidder},
+		}).SignedString([]byte(key))
+		require.NoError(t, err)
+		return signed
+	}
+
+	tests := []struct {
+		name          string
+		apiKey        string
+		authorization string
+		want          *auth.Principal
+		wantErr       string
+	}{
+		{name: "API key", apiKey: plainKey,
+			want: &auth.Principal{UserId: ownerId, Roles: []string{auth.RoleReader, auth.RoleBidder}}},
+		{name: "unknown API key", apiKey: "ak_unknown", wantErr: "unauthorized"},
+		{name: "bearer token", authorization: "Bearer " + token(secret, expiresAt),
+			want: &auth.Principal{UserId: "user-1", Roles: []string{auth.RoleBidder}, ExpiresAt: expiresAt}},
+		{name: "expired token", authorization: "Bearer " + token(secret, time.Now().Add(-time.Hour)),
+			wantErr: "unauthorized"},
+		{name: "not a bearer token", authorization: "Basic dXNlcjpwYXNz", wantErr: "unauthorized"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authUseCase.Authenticate(context.Background(), tt.apiKey, tt.authorization)
+
+			if tt.wantErr != "" {
+				var internalError *internal_error.InternalError
+				require.ErrorAs(t, err, &internalError)
+				require.Equal(t, tt.wantErr, internalError.Err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, principal)
+		})
+	}
+}
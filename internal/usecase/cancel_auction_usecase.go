@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"context"
+)
+
+func (au *auctionUseCase) CancelAuction(
+	ctx context.Context,
+	auctionId string,
+	input AuctionCancelInputDTO,
+) (*AuctionOutputDTO, error) {
+	// Bids still waiting for their batch count as much as stored ones when
+	// deciding whether the reserve was reached.
+	if err := au.bidQueue.FlushBids(ctx); err != nil {
+		return nil, err
+	}
+
+	auction, err := au.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	highestBid, err := au.bidRepository.FindWinningBidByAuctionId(ctx, auctionId)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	previousStatus := auction.Status
+	if err := auction.Cancel(input.Reason, highestBid); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepository.UpdateAuctionStatus(ctx, auction, previousStatus); err != nil {
+		return nil, err
+	}
+
+	output := newAuctionOutputDTO(auction)
+	return &output, nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCancelAuction(t *testing.T) {
+	tests := []struct {
+		name         string
+		reservePrice float64
+		storedBids   []float64
+		queuedBids   []float64
+		wantErr      bool
+	}{
+		{name: "no bids", reservePrice: 100},
+		{name: "stored bid below reserve", reservePrice: 100, storedBids: []float64{50}},
+		{name: "stored bid reaching reserve", reservePrice: 100, storedBids: []float64{100}, wantErr: true},
+		{name: "queued bid reaching reserve", reservePrice: 100, storedBids: []float64{50}, queuedBids: []float64{120}, wantErr: true},
+		{name: "queued bid without reserve", queuedBids: []float64{10}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &entity.Auction{
+				Id:           uuid.New().String(),
+				SellerId:     uuid.New().String(),
+				Status:       entity.Active,
+				ReservePrice: tt.reservePrice,
+				Timestamp:    time.Now(),
+			}
+			auctionRepository := newFakeAuctionRepository(auction)
+			bidRepository := &fakeBidRepository{bids: testBids(auction.Id, tt.storedBids)}
+			bidQueue := &fakeBidQueue{bids: bidRepository, pending: testBids(auction.Id, tt.queuedBids)}
+			auctionUseCase := &auctionUseCase{
+				auctionRepository: auctionRepository,
+				bidRepository:     bidRepository,
+				bidQueue:          bidQueue,
+			}
+
+			output, err := auctionUseCase.CancelAuction(
+				context.Background(), auction.Id, AuctionCancelInputDTO{Reason: "Item was damaged"})
+
+			require.Equal(t, 1, bidQueue.flushes)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, entity.Active, auctionRepository.auctions[auction.Id].Status)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, AuctionStatus(entity.Cancelled), output.Status)
+			require.Equal(t, entity.Cancelled, auctionRepository.auctions[auction.Id].Status)
+		})
+	}
+}
+
+func testBids(auctionId string, amounts []float64) []entity.Bid {
+	bids := make([]entity.Bid, 0, len(amounts))
+	for _, amount := range amounts {
+		bids = append(bids, entity.Bid{
+			Id:        uuid.New().String(),
+			UserId:    uuid.New().String(),
+			AuctionId: auctionId,
+			Amount:    amount,
+			Timestamp: time.Now(),
+		})
+	}
+	return bids
+}
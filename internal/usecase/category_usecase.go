@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"time"
+)
+
+type (
+	CategoryInputDTO struct {
+		Name       string                   `json:"name" binding:"required,min=2,max=50"`
+		Slug       string                   `json:"slug" binding:"omitempty,max=50"`
+		ParentId   string                   `json:"parent_id" binding:"omitempty,uuid"`
+		Attributes []AttributeDefinitionDTO `json:"attributes" binding:"omitempty,max=30,dive"`
+	}
+
+	// CategoryUpdateInputDTO changes a category. An empty ParentId moves it to
+	// the root; a missing one leaves it where it is.
+	CategoryUpdateInputDTO struct {
+		Name       *string                   `json:"name" binding:"omitempty,min=2,max=50"`
+		ParentId   *string                   `json:"parent_id" binding:"omitempty,eq=|uuid"`
+		Attributes *[]AttributeDefinitionDTO `json:"attributes" binding:"omitempty,max=30,dive"`
+	}
+
+	AttributeDefinitionDTO struct {
+		Key      string   `json:"key" binding:"required,max=30"`
+		Label    string   `json:"label" binding:"max=50"`
+		Type     string   `json:"type" binding:"required,oneof=string number integer boolean enum"`
+		Required bool     `json:"required"`
+		Options  []string `json:"options,omitempty" binding:"omitempty,max=100,dive,min=1,max=50"`
+	}
+
+	CategoryOutputDTO struct {
+		Id          string                   `json:"id"`
+		Name        string                   `json:"name"`
+		Slug        string                   `json:"slug"`
+		ParentId    string                   `json:"parent_id,omitempty"`
+		AncestorIds []string                 `json:"ancestor_ids"`
+		Attributes  []AttributeDefinitionDTO `json:"attributes"`
+		CreatedAt   time.Time                `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	}
+
+	CategoryListOutputDTO struct {
+		Categories []CategoryOutputDTO `json:"categories"`
+	}
+
+	CategoryUseCase interface {
+		CreateCategory(ctx context.Context, input CategoryInputDTO) (*CategoryOutputDTO, error)
+
+		UpdateCategory(ctx context.Context, categoryId string, input CategoryUpdateInputDTO) (*CategoryOutputDTO, error)
+
+		DeleteCategory(ctx context.Context, categoryId string) error
+
+		FindCategoryById(ctx context.Context, categoryId string) (*CategoryOutputDTO, error)
+
+		FindCategories(ctx context.Context) (*CategoryListOutputDTO, error)
+
+		// BackfillAuctionCategories links free-text auction categories to the
+		// category of the same slug, creating it at the root when missing.
+		BackfillAuctionCategories(ctx context.Context)
+	}
+
+	categoryUseCase struct {
+		categoryRepository repository.CategoryRepository
+		auctionRepository  repository.AuctionRepository
+	}
+)
+
+func NewCategoryUseCase(
+	categoryRepository repository.CategoryRepository,
+	auctionRepository repository.AuctionRepository,
+) CategoryUseCase {
+	return &categoryUseCase{
+		categoryRepository: categoryRepository,
+		auctionRepository:  auctionRepository,
+	}
+}
+
+func (cu *categoryUseCase) CreateCategory(ctx context.Context, input CategoryInputDTO) (*CategoryOutputDTO, error) {
+	parent, err := cu.findParent(ctx, input.ParentId)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := entity.CreateCategory(input.Name, input.Slug, parent, toAttributeDefinitions(input.Attributes))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cu.categoryRepository.CreateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	output := newCategoryOutputDTO(category)
+	return &output, nil
+}
+
+func (cu *categoryUseCase) UpdateCategory(
+	ctx context.Context,
+	categoryId string,
+	input CategoryUpdateInputDTO,
+) (*CategoryOutputDTO, error) {
+	category, err := cu.categoryRepository.FindCategoryById(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		if err := category.Rename(*input.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.Attributes != nil {
+		if err := category.SetAttributes(toAttributeDefinitions(*input.Attributes)); err != nil {
+			return nil, err
+		}
+	}
+
+	if input.ParentId != nil {
+		parent, err := cu.findParent(ctx, *input.ParentId)
+		if err != nil {
+			return nil, err
+		}
+		if err := category.MoveTo(parent); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cu.categoryRepository.UpdateCategory(ctx, category); err != nil {
+		return nil, err
+	}
+
+	output := newCategoryOutputDTO(category)
+	return &output, nil
+}
+
+// DeleteCategory removes a leaf category nobody lists under, since auctions
+// keep referencing the slug of their category.
+func (cu *categoryUseCase) DeleteCategory(ctx context.Context, categoryId string) error {
+	category, err := cu.categoryRepository.FindCategoryById(ctx, categoryId)
+	if err != nil {
+		return err
+	}
+
+	descendants, err := cu.categoryRepository.FindDescendants(ctx, category.Id)
+	if err != nil {
+		return err
+	}
+	if len(descendants) > 0 {
+		return internal_error.NewConflictError("Category has subcategories, move or delete them first")
+	}
+
+	inUse, err := cu.auctionRepository.HasAuctionsInCategory(ctx, category.Slug)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return internal_error.NewConflictError("Category is used by auctions and cannot be deleted")
+	}
+
+	return cu.categoryRepository.DeleteCategory(ctx, category.Id)
+}
+
+func (cu *categoryUseCase) FindCategoryById(ctx context.Context, categoryId string) (*CategoryOutputDTO, error) {
+	category, err := cu.categoryRepository.FindCategoryById(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	output := newCategoryOutputDTO(category)
+	return &output, nil
+}
+
+func (cu *categoryUseCase) FindCategories(ctx context.Context) (*CategoryListOutputDTO, error) {
+	categories, err := cu.categoryRepository.FindCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]CategoryOutputDTO, 0, len(categories))
+	for _, category := range categories {
+		outputs = append(outputs, newCategoryOutputDTO(&category))
+	}
+
+	return &CategoryListOutputDTO{Categories: outputs}, nil
+}
+
+func (cu *categoryUseCase) BackfillAuctionCategories(ctx context.Context) {
+	values, err := cu.auctionRepository.FindAuctionCategories(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, value := range values {
+		if err := cu.backfillAuctionCategory(ctx, value); err != nil {
+			logger.Error(fmt.Sprintf("Error trying to backfill auction category %s", value), err)
+		}
+	}
+}
+
+func (cu *categoryUseCase) backfillAuctionCategory(ctx context.Context, value string) error {
+	slug := entity.Slugify(value)
+	_, err := cu.categoryRepository.FindCategoryBySlug(ctx, slug)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	if err != nil {
+		category, err := entity.CreateCategory(value, slug, nil, nil)
+		if err != nil {
+			return err
+		}
+		// Another instance may be running the same backfill.
+		if err := cu.categoryRepository.CreateCategory(ctx, category); err != nil && !isConflict(err) {
+			return err
+		}
+	}
+
+	if value == slug {
+		return nil
+	}
+	return cu.auctionRepository.RenameAuctionCategory(ctx, value, slug)
+}
+
+func (cu *categoryUseCase) findParent(ctx context.Context, parentId string) (*entity.Category, error) {
+	if parentId == "" {
+		return nil, nil
+	}
+
+	parent, err := cu.categoryRepository.FindCategoryById(ctx, parentId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, internal_error.NewBadRequestError(fmt.Sprintf("Parent category not found with this id = %s", parentId))
+		}
+		return nil, err
+	}
+
+	return parent, nil
+}
+
+// findCategoryBySlug resolves a category given by clients, who may spell it
+// as a name ("Eletrônicos") or as its slug ("eletronicos").
+func findCategoryBySlug(
+	ctx context.Context,
+	categoryRepository repository.CategoryRepository,
+	value string,
+) (*entity.Category, error) {
+	category, err := categoryRepository.FindCategoryBySlug(ctx, entity.Slugify(value))
+	if err != nil {
+		if isNotFound(err) {
+			return nil, internal_error.NewBadRequestError(fmt.Sprintf("Unknown category %s", value))
+		}
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func newCategoryOutputDTO(category *entity.Category) CategoryOutputDTO {
+	ancestorIds := category.Ancestors
+	if ancestorIds == nil {
+		ancestorIds = []string{}
+	}
+
+	attributes := make([]AttributeDefinitionDTO, 0, len(category.Attributes))
+	for _, attribute := range category.Attributes {
+		attributes = append(attributes, AttributeDefinitionDTO{
+			Key:      attribute.Key,
+			Label:    attribute.Label,
+			Type:     string(attribute.Type),
+			Required: attribute.Required,
+			Options:  attribute.Options,
+		})
+	}
+
+	return CategoryOutputDTO{
+		Id:          category.Id,
+		Name:        category.Name,
+		Slug:        category.Slug,
+		ParentId:    category.ParentId,
+		AncestorIds: ancestorIds,
+		Attributes:  attributes,
+		CreatedAt:   category.CreatedAt,
+	}
+}
+
+func toAttributeDefinitions(inputs []AttributeDefinitionDTO) []entity.AttributeDefinition {
+	definitions := make([]entity.AttributeDefinition, 0, len(inputs))
+	for _, input := range inputs {
+		definitions = append(definitions, entity.AttributeDefinition{
+			Key:      input.Key,
+			Label:    input.Label,
+			Type:     entity.AttributeType(input.Type),
+			Required: input.Required,
+			Options:  input.Options,
+		})
+	}
+	return definitions
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestBackfillAuctionCategories(t *testing.T) {
+	cameras, err := entity.CreateCategory("Cameras", "", nil, nil)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		category     string
+		wantCategory string
+		wantCreated  bool
+	}{
+		{name: "listed under a slug", category: "cameras", wantCategory: "cameras"},
+		{name: "listed under the name of a category", category: "Cameras", wantCategory: "cameras"},
+		{name: "listed under an unknown name", category: "Eletrônicos", wantCategory: "eletronicos", wantCreated: true},
+		{name: "listed under an unknown slug", category: "toys", wantCategory: "toys", wantCreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &entity.Auction{Id: "auction", Category: tt.category}
+			auctionRepository := newFakeAuctionRepository(auction)
+			categoryRepository := newFakeCategoryRepository(cameras)
+			categoryUseCase := NewCategoryUseCase(categoryRepository, auctionRepository)
+
+			categoryUseCase.BackfillAuctionCategories(context.Background())
+
+			require.Equal(t, tt.wantCategory, auctionRepository.auctions[auction.Id].Category)
+			category := categoryRepository.bySlug(tt.wantCategory)
+			require.NotNil(t, category)
+			require.Empty(t, category.ParentId)
+			if tt.wantCreated {
+				require.Len(t, categoryRepository.categories, 2)
+				require.Equal(t, tt.category, category.Name)
+			} else {
+				require.Len(t, categoryRepository.categories, 1)
+			}
+		})
+	}
+}
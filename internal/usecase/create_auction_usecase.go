@@ -9,20 +9,106 @@ import (
 
 type (
 	AuctionInputDTO struct {
-		ProductName string           `json:"product_name" binding:"required,min=1"`
-		Category    string           `json:"category" binding:"required,min=2"`
-		Description string           `json:"description" binding:"required,min=10,max=200"`
-		Condition   ProductCondition `json:"condition" binding:"oneof=0 1 2"`
+		SellerId     string                 `json:"-"`
+		ProductName  string                 `json:"product_name" binding:"required,min=1"`
+		Category     string                 `json:"category" binding:"required,min=2"`
+		Description  string                 `json:"description" binding:"required,min=10,max=200"`
+		Condition    ProductCondition       `json:"condition" binding:"oneof=1 2 3"`
+		Attributes   map[string]interface{} `json:"attributes"`
+		ReservePrice float64                `json:"reserve_price" binding:"gte=0"`
+		RelistRule   RelistRuleDTO          `json:"relist_rule"`
+	}
+
+	RelistRuleDTO struct {
+		MaxRelists            int     `json:"max_relists" binding:"gte=0,lte=10"`
+		PriceReductionPercent float64 `json:"price_reduction_percent" binding:"gte=0,lt=100"`
+	}
+
+	AuctionUpdateInputDTO struct {
+		ProductName  *string                `json:"product_name" binding:"omitempty,min=1"`
+		Category     *string                `json:"category" binding:"omitempty,min=2"`
+		Description  *string                `json:"description" binding:"omitempty,min=10,max=200"`
+		Condition    *ProductCondition      `json:"condition" binding:"omitempty,oneof=1 2 3"`
+		Attributes   map[string]interface{} `json:"attributes"`
+		ReservePrice *float64               `json:"reserve_price" binding:"omitempty,gte=0"`
+		Version      int64                  `json:"version" binding:"required"`
+	}
+
+	AuctionCancelInputDTO struct {
+		Reason string `json:"reason" binding:"required,min=5,max=200"`
+	}
+
+	AuctionRejectInputDTO struct {
+		Reason string `json:"reason" binding:"required,min=5,max=200"`
+	}
+
+	AuctionListInputDTO struct {
+		Status      AuctionStatus     `form:"status" binding:"omitempty,oneof=1 2 3 4 5"`
+		Category    string            `form:"category"`
+		SellerId    string            `form:"seller_id" binding:"omitempty,uuid"`
+		Condition   *ProductCondition `form:"condition" binding:"omitempty,oneof=1 2 3"`
+		ProductName string            `form:"productName" binding:"max=100"`
+		Text        string            `form:"q" binding:"max=100"`
+		MinPrice    *float64          `form:"min_price" binding:"omitempty,gte=0"`
+		MaxPrice    *float64          `form:"max_price" binding:"omitempty,gte=0"`
+		CreatedFrom time.Time         `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+		CreatedTo   time.Time         `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+		EndingFrom  time.Time         `form:"ending_from" time_format:"2006-01-02T15:04:05Z07:00"`
+		EndingTo    time.Time         `form:"ending_to" time_format:"2006-01-02T15:04:05Z07:00"`
+		HasBids     *bool             `form:"has_bids"`
+		Attributes  map[string]string `form:"-"`
+		Cursor      string            `form:"cursor"`
+		Limit       int               `form:"limit" binding:"omitempty,min=1,max=100"`
+		Sort        string            `form:"sort" binding:"omitempty,oneof=ending_soonest newest price_asc price_desc bid_count"`
 	}
 
 	AuctionOutputDTO struct {
-		Id          string           `json:"id"`
-		ProductName string           `json:"product_name"`
-		Category    string           `json:"category"`
-		Description string           `json:"description"`
-		Condition   ProductCondition `json:"condition"`
-		Status      AuctionStatus    `json:"status"`
-		Timestamp   time.Time        `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+		Id                 string                  `json:"id"`
+		SellerId           string                  `json:"seller_id"`
+		ProductName        string                  `json:"product_name"`
+		Category           string                  `json:"category"`
+		Description        string                  `json:"description"`
+		Condition          ProductCondition        `json:"condition"`
+		Attributes         map[string]interface{}  `json:"attributes,omitempty"`
+		Images             []AuctionImageOutputDTO `json:"images"`
+		Status             AuctionStatus           `json:"status"`
+		ReservePrice       float64                 `json:"reserve_price"`
+		CancellationReason string                  `json:"cancellation_reason,omitempty"`
+		CancelledAt        *time.Time              `json:"cancelled_at,omitempty" time_format:"2006-01-02 15:04:05"`
+		RejectionReason    string                  `json:"rejection_reason,omitempty"`
+		RelistRule         RelistRuleDTO           `json:"relist_rule"`
+		RelistCount        int                     `json:"relist_count"`
+		RelistedFromId     string                  `json:"relisted_from_id,omitempty"`
+		RelistedAsId       string                  `json:"relisted_as_id,omitempty"`
+		CurrentPrice       float64                 `json:"current_price"`
+		BidCount           int64                   `json:"bid_count"`
+		AnsweredQuestions  int64                   `json:"answered_questions"`
+		WatcherCount       int64                   `json:"watcher_count"`
+		Version            int64                   `json:"version"`
+		CreatedAt          *time.Time              `json:"created_at,omitempty" time_format:"2006-01-02 15:04:05"`
+		Timestamp          time.Time               `json:"timestamp" time_format:"2006-01-02 15:04:05"`
+	}
+
+	AuctionListOutputDTO struct {
+		Auctions   []AuctionOutputDTO  `json:"auctions"`
+		NextCursor string              `json:"next_cursor,omitempty"`
+		HasMore    bool                `json:"has_more"`
+		Total      int64               `json:"total"`
+		Facets     []AttributeFacetDTO `json:"facets,omitempty"`
+	}
+
+	AttributeFacetDTO struct {
+		Key    string          `json:"key"`
+		Label  string          `json:"label,omitempty"`
+		Type   string          `json:"type"`
+		Values []FacetValueDTO `json:"values,omitempty"`
+		Min    *float64        `json:"min,omitempty"`
+		Max    *float64        `json:"max,omitempty"`
+	}
+
+	FacetValueDTO struct {
+		Value interface{} `json:"value"`
+		Count int64       `json:"count"`
 	}
 
 	WinningInfoOutputDTO struct {
@@ -31,47 +117,147 @@ type (
 	}
 
 	AuctionUseCase interface {
-		CreateAuction(ctx context.Context, auctionInput AuctionInputDTO) error
+		CreateAuction(ctx context.Context, auctionInput AuctionInputDTO) (*AuctionOutputDTO, error)
 
 		FindAuctionById(ctx context.Context, id string) (*AuctionOutputDTO, error)
 
-		FindAuctions(ctx context.Context, status AuctionStatus, category, productName string) ([]AuctionOutputDTO, error)
+		FindAuctions(ctx context.Context, input AuctionListInputDTO) (*AuctionListOutputDTO, error)
 
 		FindWinningBidByAuctionId(ctx context.Context, auctionId string) (*WinningInfoOutputDTO, error)
+
+		CancelAuction(ctx context.Context, auctionId string, input AuctionCancelInputDTO) (*AuctionOutputDTO, error)
+
+		UpdateAuction(ctx context.Context, auctionId string, input AuctionUpdateInputDTO) (*AuctionOutputDTO, error)
+
+		SubmitAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error)
+
+		FindPendingAuctions(ctx context.Context, input AuctionListInputDTO) (*AuctionListOutputDTO, error)
+
+		ApproveAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error)
+
+		RejectAuction(ctx context.Context, auctionId string, input AuctionRejectInputDTO) (*AuctionOutputDTO, error)
+
+		CloseAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error)
+
+		ReopenAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error)
+
+		SearchAuctions(ctx context.Context, input AuctionSearchInputDTO) (*AuctionSearchOutputDTO, error)
 	}
 
 	ProductCondition int64
 	AuctionStatus    int64
 
 	auctionUseCase struct {
-		auctionRepository repository.AuctionRepository
-		bidRepository     repository.BidRepository
+		auctionRepository       repository.AuctionRepository
+		bidRepository           repository.BidRepository
+		bidQueue                repository.BidQueue
+		userRepository          repository.UserRepository
+		auctionSearchRepository repository.AuctionSearchRepository
+		categoryRepository      repository.CategoryRepository
 	}
 )
 
+// IsPublic reports whether auctions in the status are listed to everyone.
+func (s AuctionStatus) IsPublic() bool {
+	return entity.AuctionStatus(s).IsPublic()
+}
+
 func NewAuctionUseCase(
 	auctionRepository repository.AuctionRepository,
 	bidRepository repository.BidRepository,
+	bidQueue repository.BidQueue,
+	userRepository repository.UserRepository,
+	auctionSearchRepository repository.AuctionSearchRepository,
+	categoryRepository repository.CategoryRepository,
 ) AuctionUseCase {
 	return &auctionUseCase{
-		auctionRepository: auctionRepository,
-		bidRepository:     bidRepository,
+		auctionRepository:       auctionRepository,
+		bidRepository:           bidRepository,
+		bidQueue:                bidQueue,
+		userRepository:          userRepository,
+		auctionSearchRepository: auctionSearchRepository,
+		categoryRepository:      categoryRepository,
 	}
 }
 
-func (au *auctionUseCase) CreateAuction(ctx context.Context, input AuctionInputDTO) error {
+func (au *auctionUseCase) CreateAuction(ctx context.Context, input AuctionInputDTO) (*AuctionOutputDTO, error) {
+	if _, err := au.userRepository.FindUserById(ctx, input.SellerId); err != nil {
+		return nil, err
+	}
+
+	category, err := findCategoryBySlug(ctx, au.categoryRepository, input.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := category.ValidateAttributes(input.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
 	auction, err := entity.CreateAuction(
+		input.SellerId,
 		input.ProductName,
-		input.Category,
+		category.Slug,
 		input.Description,
 		entity.ProductCondition(input.Condition),
+		attributes,
+		input.ReservePrice,
+		entity.RelistRule{
+			MaxRelists:            input.RelistRule.MaxRelists,
+			PriceReductionPercent: input.RelistRule.PriceReductionPercent,
+		},
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := au.auctionRepository.CreateAuction(ctx, auction); err != nil {
-		return err
+		return nil, err
+	}
+
+	output := newAuctionOutputDTO(auction)
+	return &output, nil
+}
+
+func newAuctionOutputDTO(auction *entity.Auction) AuctionOutputDTO {
+	output := AuctionOutputDTO{
+		Id:                 auction.Id,
+		SellerId:           auction.SellerId,
+		ProductName:        auction.ProductName,
+		Category:           auction.Category,
+		Description:        auction.Description,
+		Condition:          ProductCondition(auction.Condition),
+		Attributes:         auction.Attributes,
+		Status:             AuctionStatus(auction.Status),
+		ReservePrice:       auction.ReservePrice,
+		CancellationReason: auction.CancellationReason,
+		RejectionReason:    auction.RejectionReason,
+		RelistRule: RelistRuleDTO{
+			MaxRelists:            auction.RelistRule.MaxRelists,
+			PriceReductionPercent: auction.RelistRule.PriceReductionPercent,
+		},
+		RelistCount:       auction.RelistCount,
+		RelistedFromId:    auction.RelistedFromId,
+		RelistedAsId:      auction.RelistedAsId,
+		CurrentPrice:      auction.CurrentPrice,
+		BidCount:          auction.BidCount,
+		AnsweredQuestions: auction.AnsweredQuestions,
+		WatcherCount:      auction.WatcherCount,
+		Version:           auction.Version,
+		Timestamp:         auction.Timestamp,
+	}
+	output.Images = make([]AuctionImageOutputDTO, 0, len(auction.Images))
+	for _, image := range auction.Images {
+		output.Images = append(output.Images, newAuctionImageOutputDTO(image))
+	}
+	if !auction.CancelledAt.IsZero() {
+		cancelledAt := auction.CancelledAt
+		output.CancelledAt = &cancelledAt
+	}
+	if !auction.CreatedAt.IsZero() {
+		createdAt := auction.CreatedAt
+		output.CreatedAt = &createdAt
 	}
-	return nil
+	return output
 }
@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
 	"fullcycle-auction_go/internal/repository"
 	"os"
 	"strconv"
@@ -12,9 +14,9 @@ import (
 
 type (
 	BidInputDTO struct {
-		UserId    string  `json:"user_id"`
-		AuctionId string  `json:"auction_id"`
-		Amount    float64 `json:"amount"`
+		UserId    string  `json:"-"`
+		AuctionId string  `json:"auction_id" binding:"required,uuid"`
+		Amount    float64 `json:"amount" binding:"gt=0"`
 	}
 
 	BidOutputDTO struct {
@@ -25,34 +27,60 @@ type (
 		Timestamp time.Time `json:"timestamp" time_format:"2006-01-02 15:04:05"`
 	}
 
+	BidListInputDTO struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+		Sort   string `form:"sort" binding:"omitempty,oneof=newest amount"`
+	}
+
+	BidListOutputDTO struct {
+		Bids       []BidOutputDTO `json:"bids"`
+		NextCursor string         `json:"next_cursor,omitempty"`
+		HasMore    bool           `json:"has_more"`
+		Total      int64          `json:"total"`
+	}
+
 	bidUseCase struct {
-		BidRepository repository.BidRepository
+		BidRepository     repository.BidRepository
+		AuctionRepository repository.AuctionRepository
+		UserRepository    repository.UserRepository
 
 		timer               *time.Timer
 		maxBatchSize        int
 		batchInsertInterval time.Duration
 		bidChannel          chan entity.Bid
+		bidBatch            []entity.Bid
+		flushRequests       chan chan error
 	}
 
 	BidUseCase interface {
-		CreateBid(ctx context.Context, bidInputDTO BidInputDTO) error
+		CreateBid(ctx context.Context, bidInputDTO BidInputDTO) (*BidOutputDTO, error)
 
 		FindWinningBidByAuctionId(ctx context.Context, auctionId string) (*BidOutputDTO, error)
 
-		FindBidByAuctionId(ctx context.Context, auctionId string) ([]BidOutputDTO, error)
+		FindBidByAuctionId(ctx context.Context, auctionId string, input BidListInputDTO) (*BidListOutputDTO, error)
+
+		FlushBids(ctx context.Context) error
 	}
 )
 
-func NewBidUseCase(bidRepository repository.BidRepository) BidUseCase {
+func NewBidUseCase(
+	bidRepository repository.BidRepository,
+	auctionRepository repository.AuctionRepository,
+	userRepository repository.UserRepository,
+) BidUseCase {
 	maxSizeInterval := getMaxBatchSizeInterval()
 	maxBatchSize := getMaxBatchSize()
 
 	bidUseCase := &bidUseCase{
 		BidRepository:       bidRepository,
+		AuctionRepository:   auctionRepository,
+		UserRepository:      userRepository,
 		maxBatchSize:        maxBatchSize,
 		batchInsertInterval: maxSizeInterval,
 		timer:               time.NewTimer(maxSizeInterval),
 		bidChannel:          make(chan entity.Bid, maxBatchSize),
+		flushRequests:       make(chan chan error),
 	}
 
 	go bidUseCase.triggerCreateRoutine(context.Background())
@@ -60,8 +88,6 @@ func NewBidUseCase(bidRepository repository.BidRepository) BidUseCase {
 	return bidUseCase
 }
 
-var bidBatch []entity.Bid
-
 func (bu *bidUseCase) triggerCreateRoutine(ctx context.Context) {
 	defer close(bu.bidChannel)
 
@@ -69,43 +95,119 @@ func (bu *bidUseCase) triggerCreateRoutine(ctx context.Context) {
 		select {
 		case bid, ok := <-bu.bidChannel:
 			if !ok {
-				if len(bidBatch) > 0 {
-					if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-						logger.Error("error trying to process bid batch list", err)
-					}
-				}
+				bu.insertBatch(ctx)
 				return
 			}
 
-			bidBatch = append(bidBatch, bid)
+			bu.bidBatch = append(bu.bidBatch, bid)
 
-			if len(bidBatch) >= bu.maxBatchSize {
-				if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-					logger.Error("error trying to process bid batch list", err)
-				}
-
-				bidBatch = nil
+			if len(bu.bidBatch) >= bu.maxBatchSize {
+				bu.insertBatch(ctx)
 				bu.timer.Reset(bu.batchInsertInterval)
 			}
 		case <-bu.timer.C:
-			if err := bu.BidRepository.CreateBid(ctx, bidBatch); err != nil {
-				logger.Error("error trying to process bid batch list", err)
-			}
-			bidBatch = nil
+			bu.insertBatch(ctx)
 			bu.timer.Reset(bu.batchInsertInterval)
+		case done := <-bu.flushRequests:
+			bu.drainBidChannel()
+			done <- bu.insertBatch(ctx)
 		}
 	}
 }
 
-func (bu *bidUseCase) CreateBid(ctx context.Context, input BidInputDTO) error {
-	bid, err := entity.CreateBid(input.UserId, input.AuctionId, input.Amount)
+// drainBidChannel moves the bids already sent to the channel into the batch
+// without waiting for more.
+func (bu *bidUseCase) drainBidChannel() {
+	for {
+		select {
+		case bid := <-bu.bidChannel:
+			bu.bidBatch = append(bu.bidBatch, bid)
+		default:
+			return
+		}
+	}
+}
+
+func (bu *bidUseCase) insertBatch(ctx context.Context) error {
+	if len(bu.bidBatch) == 0 {
+		return nil
+	}
+
+	err := bu.BidRepository.CreateBid(ctx, bu.bidBatch)
 	if err != nil {
+		logger.Error("error trying to process bid batch list", err)
+	}
+	bu.bidBatch = nil
+	return err
+}
+
+// FlushBids has the batch routine write every bid queued so far, instead of
+// waiting for the batch to fill up or for the insert interval.
+func (bu *bidUseCase) FlushBids(ctx context.Context) error {
+	done := make(chan error, 1)
+	select {
+	case bu.flushRequests <- done:
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Error trying to write queued bids")
+	}
+
+	select {
+	case err := <-done:
 		return err
+	case <-ctx.Done():
+		return internal_error.NewInternalServerError("Error trying to write queued bids")
+	}
+}
+
+func (bu *bidUseCase) CreateBid(ctx context.Context, input BidInputDTO) (*BidOutputDTO, error) {
+	bid, err := entity.CreateBid(input.UserId, input.AuctionId, input.Amount)
+	if err != nil {
+		return nil, err
 	}
 
-	bu.bidChannel <- *bid
+	bidder, err := bu.findBidder(ctx, bid.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if err := bidder.CanBid(); err != nil {
+		return nil, err
+	}
+
+	auction, err := bu.AuctionRepository.FindAuctionById(ctx, bid.AuctionId)
+	if err != nil {
+		return nil, err
+	}
+	if err := auction.ValidateBid(bid); err != nil {
+		return nil, err
+	}
+
+	select {
+	case bu.bidChannel <- *bid:
+	case <-ctx.Done():
+		return nil, internal_error.NewInternalServerError("Error trying to queue bid")
+	}
+
+	return &BidOutputDTO{
+		Id:        bid.Id,
+		UserId:    bid.UserId,
+		AuctionId: bid.AuctionId,
+		Amount:    bid.Amount,
+		Timestamp: bid.Timestamp,
+	}, nil
+}
+
+// findBidder looks the bidder up, through a cached repository, before the bid
+// is queued.
+func (bu *bidUseCase) findBidder(ctx context.Context, userId string) (*entity.User, error) {
+	user, err := bu.UserRepository.FindUserById(ctx, userId)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, internal_error.NewNotFoundError(fmt.Sprintf("Bidder not found with this id = %s", userId))
+		}
+		return nil, err
+	}
 
-	return nil
+	return user, nil
 }
 
 func getMaxBatchSizeInterval() time.Duration {
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestBidUseCaseFlushBids(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []float64
+	}{
+		{name: "empty queue"},
+		{name: "partial batch", amounts: []float64{10, 20}},
+		{name: "full batch and more", amounts: []float64{10, 20, 30, 40, 50, 60, 70}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("BATCH_INSERT_INTERVAL", "1h")
+			t.Setenv("MAX_BATCH_SIZE", "5")
+
+			bidder := &entity.User{Id: uuid.New().String(), Status: entity.UserActive}
+			auction := &entity.Auction{
+				Id:        uuid.New().String(),
+				SellerId:  uuid.New().String(),
+				Status:    entity.Active,
+				Timestamp: time.Now(),
+			}
+			bidRepository := &fakeBidRepository{}
+			bidUseCase := NewBidUseCase(bidRepository, newFakeAuctionRepository(auction), newFakeUserRepository(bidder))
+
+			for _, amount := range tt.amounts {
+				_, err := bidUseCase.CreateBid(context.Background(), BidInputDTO{
+					UserId:    bidder.Id,
+					AuctionId: auction.Id,
+					Amount:    amount,
+				})
+				require.NoError(t, err)
+			}
+
+			require.NoError(t, bidUseCase.FlushBids(context.Background()))
+			require.Len(t, bidRepository.bids, len(tt.amounts))
+		})
+	}
+}
+
+func TestCreateBid(t *testing.T) {
+	t.Setenv("BATCH_INSERT_INTERVAL", "1h")
+
+	seller := &entity.User{Id: uuid.New().String(), Status: entity.UserActive}
+	bidder := &entity.User{Id: uuid.New().String(), Status: entity.UserActive}
+	suspended := &entity.User{Id: uuid.New().String(), Status: entity.UserSuspended}
+	active := &entity.Auction{Id: uuid.New().String(), SellerId: seller.Id, Status: entity.Active}
+	completed := &entity.Auction{Id: uuid.New().String(), SellerId: seller.Id, Status: entity.Completed}
+
+	tests := []struct {
+		name      string
+		userId    string
+		auctionId string
+		amount    float64
+		wantErr   bool
+	}{
+		{name: "bid on active auction", userId: bidder.Id, auctionId: active.Id, amount: 10},
+		{name: "seller bidding on own auction", userId: seller.Id, auctionId: active.Id, amount: 10, wantErr: true},
+		{name: "completed auction", userId: bidder.Id, auctionId: completed.Id, amount: 10, wantErr: true},
+		{name: "unknown auction", userId: bidder.Id, auctionId: uuid.New().String(), amount: 10, wantErr: true},
+		{name: "unknown bidder", userId: uuid.New().String(), auctionId: active.Id, amount: 10, wantErr: true},
+		{name: "suspended bidder", userId: suspended.Id, auctionId: active.Id, amount: 10, wantErr: true},
+		{name: "zero amount", userId: bidder.Id, auctionId: active.Id, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bidRepository := &fakeBidRepository{}
+			bidUseCase := NewBidUseCase(
+				bidRepository,
+				newFakeAuctionRepository(active, completed),
+				newFakeUserRepository(seller, bidder, suspended))
+
+			output, err := bidUseCase.CreateBid(context.Background(), BidInputDTO{
+				UserId:    tt.userId,
+				AuctionId: tt.auctionId,
+				Amount:    tt.amount,
+			})
+			require.NoError(t, bidUseCase.FlushBids(context.Background()))
+
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Empty(t, bidRepository.bids)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.amount, output.Amount)
+			require.Len(t, bidRepository.bids, 1)
+		})
+	}
+}
+
+func TestCreateBidGivesUpWhenQueueIsFull(t *testing.T) {
+	bidder := &entity.User{Id: uuid.New().String(), Status: entity.UserActive}
+	auction := &entity.Auction{Id: uuid.New().String(), SellerId: uuid.New().String(), Status: entity.Active}
+	bidUseCase := &bidUseCase{
+		AuctionRepository: newFakeAuctionRepository(auction),
+		UserRepository:    newFakeUserRepository(bidder),
+		bidChannel:        make(chan entity.Bid),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := bidUseCase.CreateBid(ctx, BidInputDTO{UserId: bidder.Id, AuctionId: auction.Id, Amount: 10})
+
+	require.Error(t, err)
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+func (u *userUseCase) CreateUser(ctx context.Context, input UserInputDTO) (*UserOutputDTO, error) {
+	user, err := entity.CreateUser(input.Name, input.Email, input.DisplayName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepository.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	output := newUserOutputDTO(user)
+	return &output, nil
+}
+
+func (u *userUseCase) UpdateUser(ctx context.Context, id string, input UserUpdateInputDTO) (*UserOutputDTO, error) {
+	user, err := u.userRepository.FindUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := entity.UserChanges{
+		Name:        input.Name,
+		Email:       input.Email,
+		DisplayName: input.DisplayName,
+	}
+	if input.Status != nil {
+		status := entity.UserStatus(*input.Status)
+		changes.Status = &status
+	}
+
+	if err := user.Update(changes); err != nil {
+		return nil, err
+	}
+
+	if err := u.userRepository.UpdateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	output := newUserOutputDTO(user)
+	return &output, nil
+}
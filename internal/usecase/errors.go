@@ -0,0 +1,19 @@
+package usecase
+
+import (
+	"errors"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+func isNotFound(err error) bool {
+	return hasErrorKind(err, "not_found")
+}
+
+func isConflict(err error) bool {
+	return hasErrorKind(err, "conflict")
+}
+
+func hasErrorKind(err error, kind string) bool {
+	var internalError *internal_error.InternalError
+	return errors.As(err, &internalError) && internalError.Err == kind
+}
@@ -0,0 +1,444 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"slices"
+	"sync"
+	"time"
+)
+
+// The fakes embed the repository interfaces they stand in for, so a test
+// calling a method it did not expect fails loudly on the nil embedded value.
+
+type fakeAuctionRepository struct {
+	repository.AuctionRepository
+
+	auctions map[string]*entity.Auction
+	queries  []entity.AuctionQuery
+}
+
+func newFakeAuctionRepository(auctions ...*entity.Auction) *fakeAuctionRepository {
+	repository := &fakeAuctionRepository{auctions: make(map[string]*entity.Auction)}
+	for _, auction := range auctions {
+		stored := *auction
+		repository.auctions[auction.Id] = &stored
+	}
+	return repository
+}
+
+func (r *fakeAuctionRepository) FindAuctionById(_ context.Context, id string) (*entity.Auction, error) {
+	auction, ok := r.auctions[id]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Auction not found with this id = %s", id))
+	}
+	found := *auction
+	return &found, nil
+}
+
+// FindAuctions records the query and finds nothing.
+func (r *fakeAuctionRepository) FindAuctions(
+	_ context.Context,
+	query entity.AuctionQuery,
+	_ entity.PageRequest,
+) (*entity.Page[entity.Auction], error) {
+	r.queries = append(r.queries, query)
+	return &entity.Page[entity.Auction]{}, nil
+}
+
+func (r *fakeAuctionRepository) UpdateAuctionStatus(
+	_ context.Context,
+	auction *entity.Auction,
+	previousStatus entity.AuctionStatus) error {
+	stored, ok := r.auctions[auction.Id]
+	if !ok || stored.Status != previousStatus {
+		return internal_error.NewVersionConflictError("Auction status was changed by another request, reload it and try again")
+	}
+	*stored = *auction
+	return nil
+}
+
+// UpdateAuction checks the version and, with requireNoBids, the bids stored in
+// bids, which the test shares with the bid repository.
+func (r *fakeAuctionRepository) UpdateAuction(_ context.Context, auction *entity.Auction, requireNoBids bool) error {
+	stored, ok := r.auctions[auction.Id]
+	if !ok || stored.Version != auction.Version-1 || requireNoBids && stored.BidCount > 0 {
+		return internal_error.NewVersionConflictError("Auction was modified by another request, reload it and try again")
+	}
+	*stored = *auction
+	return nil
+}
+
+type fakeBidRepository struct {
+	repository.BidRepository
+
+	bids []entity.Bid
+}
+
+func (r *fakeBidRepository) CreateBid(_ context.Context, bids []entity.Bid) error {
+	r.bids = append(r.bids, bids...)
+	return nil
+}
+
+func (r *fakeBidRepository) FindWinningBidByAuctionId(_ context.Context, auctionId string) (*entity.Bid, error) {
+	var winning *entity.Bid
+	for i, bid := range r.bids {
+		if bid.AuctionId == auctionId && (winning == nil || bid.Amount > winning.Amount) {
+			winning = &r.bids[i]
+		}
+	}
+	if winning == nil {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("No bids found for auctionId %s", auctionId))
+	}
+	return winning, nil
+}
+
+// fakeBidQueue holds bids as the batch of the bid use case would, writing
+// them to bids when flushed.
+type fakeBidQueue struct {
+	bids    *fakeBidRepository
+	pending []entity.Bid
+	flushes int
+}
+
+func (q *fakeBidQueue) FlushBids(ctx context.Context) error {
+	q.flushes++
+	err := q.bids.CreateBid(ctx, q.pending)
+	q.pending = nil
+	return err
+}
+
+type fakeUserRepository struct {
+	repository.UserRepository
+
+	users map[string]*entity.User
+}
+
+func newFakeUserRepository(users ...*entity.User) *fakeUserRepository {
+	repository := &fakeUserRepository{users: make(map[string]*entity.User)}
+	for _, user := range users {
+		stored := *user
+		repository.users[user.Id] = &stored
+	}
+	return repository
+}
+
+func (r *fakeUserRepository) FindUserById(_ context.Context, userId string) (*entity.User, error) {
+	user, ok := r.users[userId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("User not found with this id = %s", userId))
+	}
+	found := *user
+	return &found, nil
+}
+
+type fakeAPIKeyRepository struct {
+	repository.APIKeyRepository
+
+	apiKeys []*entity.APIKey
+}
+
+func (r *fakeAPIKeyRepository) FindAPIKeyByHash(_ context.Context, hash string) (*entity.APIKey, error) {
+	for _, apiKey := range r.apiKeys {
+		if apiKey.Hash == hash {
+			found := *apiKey
+			return &found, nil
+		}
+	}
+	return nil, internal_error.NewNotFoundError("API key not found")
+}
+
+// FindAuctionCategories lists the categories of the stored auctions, sorted.
+func (r *fakeAuctionRepository) FindAuctionCategories(_ context.Context) ([]string, error) {
+	var categories []string
+	for _, auction := range r.auctions {
+		if !slices.Contains(categories, auction.Category) {
+			categories = append(categories, auction.Category)
+		}
+	}
+	slices.Sort(categories)
+	return categories, nil
+}
+
+func (r *fakeAuctionRepository) RenameAuctionCategory(_ context.Context, from, to string) error {
+	for _, auction := range r.auctions {
+		if auction.Category == from {
+			auction.Category = to
+		}
+	}
+	return nil
+}
+
+type fakeCategoryRepository struct {
+	repository.CategoryRepository
+
+	categories map[string]*entity.Category
+}
+
+func newFakeCategoryRepository(categories ...*entity.Category) *fakeCategoryRepository {
+	repository := &fakeCategoryRepository{categories: make(map[string]*entity.Category)}
+	for _, category := range categories {
+		stored := *category
+		repository.categories[category.Id] = &stored
+	}
+	return repository
+}
+
+func (r *fakeCategoryRepository) CreateCategory(_ context.Context, category *entity.Category) error {
+	if _, err := r.FindCategoryBySlug(context.Background(), category.Slug); err == nil {
+		return internal_error.NewConflictError("Category slug is already taken")
+	}
+	stored := *category
+	r.categories[category.Id] = &stored
+	return nil
+}
+
+func (r *fakeCategoryRepository) FindCategoryById(_ context.Context, categoryId string) (*entity.Category, error) {
+	category, ok := r.categories[categoryId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError("Category not found")
+	}
+	found := *category
+	return &found, nil
+}
+
+func (r *fakeCategoryRepository) FindCategoryBySlug(_ context.Context, slug string) (*entity.Category, error) {
+	for _, category := range r.categories {
+		if category.Slug == slug {
+			found := *category
+			return &found, nil
+		}
+	}
+	return nil, internal_error.NewNotFoundError("Category not found")
+}
+
+func (r *fakeCategoryRepository) bySlug(slug string) *entity.Category {
+	category, _ := r.FindCategoryBySlug(context.Background(), slug)
+	return category
+}
+
+func (r *fakeCategoryRepository) FindDescendants(_ context.Context, categoryId string) ([]entity.Category, error) {
+	var descendants []entity.Category
+	for _, category := range r.categories {
+		if slices.Contains(category.Ancestors, categoryId) {
+			descendants = append(descendants, *category)
+		}
+	}
+	return descendants, nil
+}
+
+// FindAuctionFacets returns an empty facet for each attribute.
+func (r *fakeAuctionRepository) FindAuctionFacets(
+	_ context.Context,
+	_ entity.AuctionQuery,
+	definitions []entity.AttributeDefinition,
+) ([]entity.AttributeFacet, error) {
+	facets := make([]entity.AttributeFacet, 0, len(definitions))
+	for _, definition := range definitions {
+		facets = append(facets, entity.AttributeFacet{Definition: definition})
+	}
+	return facets, nil
+}
+
+type fakeQuestionRepository struct {
+	repository.QuestionRepository
+
+	questions map[string]*entity.Question
+}
+
+func newFakeQuestionRepository(questions ...*entity.Question) *fakeQuestionRepository {
+	repository := &fakeQuestionRepository{questions: make(map[string]*entity.Question)}
+	for _, question := range questions {
+		stored := *question
+		repository.questions[question.Id] = &stored
+	}
+	return repository
+}
+
+func (r *fakeQuestionRepository) FindQuestionById(_ context.Context, questionId string) (*entity.Question, error) {
+	question, ok := r.questions[questionId]
+	if !ok {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Question not found with this id = %s", questionId))
+	}
+	found := *question
+	found.Flags = slices.Clone(question.Flags)
+	return &found, nil
+}
+
+func (r *fakeQuestionRepository) UpdateQuestion(_ context.Context, question *entity.Question) error {
+	stored, ok := r.questions[question.Id]
+	if !ok || stored.Version != question.Version {
+		return internal_error.NewVersionConflictError("Question was modified by another request, reload it and try again")
+	}
+	*stored = *question
+	stored.Version++
+	question.Version = stored.Version
+	return nil
+}
+
+func (r *fakeAuctionRepository) FindAuctionsByIds(_ context.Context, ids []string) ([]entity.Auction, error) {
+	var auctions []entity.Auction
+	for _, id := range ids {
+		if auction, ok := r.auctions[id]; ok {
+			auctions = append(auctions, *auction)
+		}
+	}
+	return auctions, nil
+}
+
+// fakeWatchlistRepository keeps the watcher counts in the auctions of the
+// auction repository, as the database does.
+type fakeWatchlistRepository struct {
+	repository.WatchlistRepository
+
+	auctions *fakeAuctionRepository
+	entries  []entity.WatchlistEntry
+}
+
+func (r *fakeWatchlistRepository) AddToWatchlist(_ context.Context, entry *entity.WatchlistEntry) (int64, error) {
+	for _, existing := range r.entries {
+		if existing.UserId == entry.UserId && existing.AuctionId == entry.AuctionId {
+			return 0, internal_error.NewConflictError("Auction is already in the watchlist")
+		}
+	}
+	r.entries = append(r.entries, *entry)
+
+	auction := r.auctions.auctions[entry.AuctionId]
+	auction.WatcherCount++
+	return auction.WatcherCount, nil
+}
+
+func (r *fakeWatchlistRepository) FindWatchlistByUserId(
+	_ context.Context,
+	userId string,
+	_ entity.PageRequest,
+) (*entity.Page[entity.WatchlistEntry], error) {
+	page := &entity.Page[entity.WatchlistEntry]{}
+	for _, entry := range r.entries {
+		if entry.UserId == userId {
+			page.Items = append(page.Items, entry)
+			page.Total++
+		}
+	}
+	return page, nil
+}
+
+type fakeNotifier struct {
+	notifications []entity.Notification
+}
+
+func (n *fakeNotifier) Notify(_ context.Context, notification entity.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+type fakeNotificationPreferencesRepository struct {
+	repository.NotificationPreferencesRepository
+
+	preferences map[string]*entity.NotificationPreferences
+}
+
+func (r *fakeNotificationPreferencesRepository) FindNotificationPreferences(
+	_ context.Context,
+	userId string,
+) (*entity.NotificationPreferences, error) {
+	if preferences, ok := r.preferences[userId]; ok {
+		return preferences, nil
+	}
+	return &entity.NotificationPreferences{UserId: userId}, nil
+}
+
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+
+	webhooks   []entity.Webhook
+	deliveries map[string]*entity.WebhookDelivery
+	failCreate bool
+	mutex      sync.Mutex
+}
+
+func newFakeWebhookRepository(webhooks ...entity.Webhook) *fakeWebhookRepository {
+	return &fakeWebhookRepository{webhooks: webhooks, deliveries: map[string]*entity.WebhookDelivery{}}
+}
+
+func (r *fakeWebhookRepository) FindWebhookById(_ context.Context, webhookId string) (*entity.Webhook, error) {
+	for i := range r.webhooks {
+		if r.webhooks[i].Id == webhookId {
+			return &r.webhooks[i], nil
+		}
+	}
+	return nil, internal_error.NewNotFoundError("webhook not found")
+}
+
+func (r *fakeWebhookRepository) FindWebhooksByEventType(
+	_ context.Context,
+	eventType entity.AuctionEventType,
+) ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook
+	for _, webhook := range r.webhooks {
+		if webhook.Subscribes(eventType) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *fakeWebhookRepository) CreateDeliveries(_ context.Context, deliveries []entity.WebhookDelivery) error {
+	if r.failCreate {
+		return internal_error.NewInternalServerError("insert failed")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, delivery := range deliveries {
+		if _, ok := r.deliveries[delivery.Id]; !ok {
+			r.deliveries[delivery.Id] = &delivery
+		}
+	}
+	return nil
+}
+
+func (r *fakeWebhookRepository) ClaimDueDelivery(_ context.Context, lease time.Duration) (*entity.WebhookDelivery, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, delivery := range r.deliveries {
+		if delivery.Status == entity.DeliveryPending && !delivery.NextAttemptAt.After(time.Now()) {
+			delivery.NextAttemptAt = time.Now().Add(lease)
+			claimed := *delivery
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeWebhookRepository) UpdateDelivery(_ context.Context, delivery *entity.WebhookDelivery) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.deliveries[delivery.Id] = delivery
+	return nil
+}
+
+type fakeAuctionEventOutbox struct {
+	events          []entity.AuctionEvent
+	failAcknowledge bool
+}
+
+func (o *fakeAuctionEventOutbox) FindOutboxEvents(_ context.Context, _ int) ([]entity.AuctionEvent, error) {
+	return slices.Clone(o.events), nil
+}
+
+func (o *fakeAuctionEventOutbox) AcknowledgeOutboxEvents(_ context.Context, events []entity.AuctionEvent) error {
+	if o.failAcknowledge {
+		return internal_error.NewInternalServerError("acknowledge failed")
+	}
+	o.events = slices.DeleteFunc(o.events, func(event entity.AuctionEvent) bool {
+		return slices.ContainsFunc(events, func(acknowledged entity.AuctionEvent) bool {
+			return acknowledged.Id == event.Id
+		})
+	})
+	return nil
+}
@@ -2,51 +2,172 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"fullcycle-auction_go/configuration/logger"
 	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
 )
 
+// defaultPageSize is used by the auction and bid listings when the client
+// does not ask for a page size.
+const defaultPageSize = 20
+
 func (au *auctionUseCase) FindAuctionById(ctx context.Context, id string) (*AuctionOutputDTO, error) {
 	auction, err := au.auctionRepository.FindAuctionById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return &AuctionOutputDTO{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   ProductCondition(auction.Condition),
-		Status:      AuctionStatus(auction.Status),
-		Timestamp:   auction.Timestamp,
-	}, nil
+	output := newAuctionOutputDTO(auction)
+	return &output, nil
 }
 
 func (au *auctionUseCase) FindAuctions(
 	ctx context.Context,
-	status AuctionStatus,
-	category, productName string,
-) ([]AuctionOutputDTO, error) {
-	auctionEntities, err := au.auctionRepository.FindAuctions(ctx, entity.AuctionStatus(status), category, productName)
+	input AuctionListInputDTO,
+) (*AuctionListOutputDTO, error) {
+	page := entity.PageRequest{Cursor: input.Cursor, Limit: input.Limit, Sort: input.Sort}
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+	if page.Sort == "" {
+		page.Sort = entity.AuctionSortNewest
+	}
+
+	query := entity.AuctionQuery{
+		SellerId:    input.SellerId,
+		ProductName: input.ProductName,
+		Text:        input.Text,
+		MinPrice:    input.MinPrice,
+		MaxPrice:    input.MaxPrice,
+		CreatedFrom: input.CreatedFrom,
+		CreatedTo:   input.CreatedTo,
+		EndingFrom:  input.EndingFrom,
+		EndingTo:    input.EndingTo,
+		HasBids:     input.HasBids,
+	}
+	switch {
+	case input.Status != 0:
+		query.Statuses = []entity.AuctionStatus{entity.AuctionStatus(input.Status)}
+	case page.Sort == entity.AuctionSortEndingSoonest:
+		// Only active auctions are still to end.
+		query.Statuses = []entity.AuctionStatus{entity.Active}
+	}
+	if input.Condition != nil {
+		condition := entity.ProductCondition(*input.Condition)
+		query.Condition = &condition
+	}
+
+	var category *entity.Category
+	if input.Category != "" {
+		var err error
+		if category, err = findCategoryBySlug(ctx, au.categoryRepository, input.Category); err != nil {
+			return nil, err
+		}
+		if query.Categories, category, err = au.categorySubtree(ctx, category); err != nil {
+			return nil, err
+		}
+	}
+	if err := addAttributeFilters(&query, category, input.Attributes); err != nil {
+		return nil, err
+	}
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	auctionsPage, err := au.auctionRepository.FindAuctions(ctx, query, page)
 	if err != nil {
 		return nil, err
 	}
 
-	var auctionOutputs []AuctionOutputDTO
-	for _, value := range auctionEntities {
-		auctionOutputs = append(auctionOutputs, AuctionOutputDTO{
-			Id:          value.Id,
-			ProductName: value.ProductName,
-			Category:    value.Category,
-			Description: value.Description,
-			Condition:   ProductCondition(value.Condition),
-			Status:      AuctionStatus(value.Status),
-			Timestamp:   value.Timestamp,
-		})
+	var facets []AttributeFacetDTO
+	if category != nil {
+		attributeFacets, err := au.auctionRepository.FindAuctionFacets(ctx, query, category.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		facets = newAttributeFacetDTOs(attributeFacets)
+	}
+
+	auctionOutputs := make([]AuctionOutputDTO, 0, len(auctionsPage.Items))
+	for _, value := range auctionsPage.Items {
+		auctionOutputs = append(auctionOutputs, newAuctionOutputDTO(&value))
+	}
+
+	return &AuctionListOutputDTO{
+		Auctions:   auctionOutputs,
+		NextCursor: auctionsPage.NextCursor,
+		HasMore:    auctionsPage.HasMore,
+		Total:      auctionsPage.Total,
+		Facets:     facets,
+	}, nil
+}
+
+// addAttributeFilters turns the attr[key]=value query parameters into filters
+// typed by the category schema.
+func addAttributeFilters(query *entity.AuctionQuery, category *entity.Category, attributes map[string]string) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	if category == nil {
+		return internal_error.NewBadRequestError("Filtering by attribute requires a category")
+	}
+
+	for key, raw := range attributes {
+		definition := category.FindAttribute(key)
+		if definition == nil {
+			return internal_error.NewBadRequestError(
+				fmt.Sprintf("Attribute %s is not defined for category %s", key, category.Slug))
+		}
+
+		filter, err := definition.ParseFilter(raw)
+		if err != nil {
+			return err
+		}
+		query.Attributes = append(query.Attributes, *filter)
 	}
 
-	return auctionOutputs, nil
+	return nil
+}
+
+func newAttributeFacetDTOs(attributeFacets []entity.AttributeFacet) []AttributeFacetDTO {
+	facets := make([]AttributeFacetDTO, 0, len(attributeFacets))
+	for _, attributeFacet := range attributeFacets {
+		facet := AttributeFacetDTO{
+			Key:   attributeFacet.Definition.Key,
+			Label: attributeFacet.Definition.Label,
+			Type:  string(attributeFacet.Definition.Type),
+			Min:   attributeFacet.Min,
+			Max:   attributeFacet.Max,
+		}
+		for _, value := range attributeFacet.Values {
+			facet.Values = append(facet.Values, FacetValueDTO{Value: value.Value, Count: value.Count})
+		}
+		facets = append(facets, facet)
+	}
+
+	return facets
+}
+
+// categorySubtree returns the slugs of a category and its descendants, and the
+// category with the attributes of the whole subtree.
+func (au *auctionUseCase) categorySubtree(
+	ctx context.Context,
+	category *entity.Category,
+) ([]string, *entity.Category, error) {
+	descendants, err := au.categoryRepository.FindDescendants(ctx, category.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slugs := []string{category.Slug}
+	for _, descendant := range descendants {
+		slugs = append(slugs, descendant.Slug)
+	}
+
+	subtree := *category
+	subtree.Attributes = category.SubtreeAttributes(descendants)
+	return slugs, &subtree, nil
 }
 
 func (au *auctionUseCase) FindWinningBidByAuctionId(
@@ -58,14 +179,12 @@ func (au *auctionUseCase) FindWinningBidByAuctionId(
 		return nil, err
 	}
 
-	auctionOutputDTO := AuctionOutputDTO{
-		Id:          auction.Id,
-		ProductName: auction.ProductName,
-		Category:    auction.Category,
-		Description: auction.Description,
-		Condition:   ProductCondition(auction.Condition),
-		Status:      AuctionStatus(auction.Status),
-		Timestamp:   auction.Timestamp,
+	auctionOutputDTO := newAuctionOutputDTO(auction)
+	if auction.Status == entity.Cancelled {
+		return &WinningInfoOutputDTO{
+			Auction: auctionOutputDTO,
+			Bid:     nil,
+		}, nil
 	}
 
 	bidWinning, err := au.bidRepository.FindWinningBidByAuctionId(ctx, auction.Id)
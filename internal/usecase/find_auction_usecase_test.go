@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFindAuctionsStatuses(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        AuctionListInputDTO
+		wantStatuses []entity.AuctionStatus
+	}{
+		{name: "public by default"},
+		{name: "newest first", input: AuctionListInputDTO{Sort: entity.AuctionSortNewest}},
+		{name: "ending soonest lists active auctions", input: AuctionListInputDTO{Sort: entity.AuctionSortEndingSoonest},
+			wantStatuses: []entity.AuctionStatus{entity.Active}},
+		{name: "status asked for", input: AuctionListInputDTO{Status: AuctionStatus(entity.Completed)},
+			wantStatuses: []entity.AuctionStatus{entity.Completed}},
+		{name: "status asked for ending soonest",
+			input:        AuctionListInputDTO{Status: AuctionStatus(entity.Draft), Sort: entity.AuctionSortEndingSoonest},
+			wantStatuses: []entity.AuctionStatus{entity.Draft}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionRepository := newFakeAuctionRepository()
+			auctionUseCase := &auctionUseCase{auctionRepository: auctionRepository}
+
+			_, err := auctionUseCase.FindAuctions(context.Background(), tt.input)
+
+			require.NoError(t, err)
+			require.Len(t, auctionRepository.queries, 1)
+			require.Equal(t, tt.wantStatuses, auctionRepository.queries[0].Statuses)
+		})
+	}
+}
+
+func TestFindAuctionsSubcategoryAttributes(t *testing.T) {
+	vehicles, err := entity.CreateCategory("Vehicles", "", nil,
+		[]entity.AttributeDefinition{{Key: "year", Type: entity.AttributeInteger}})
+	require.NoError(t, err)
+	cars, err := entity.CreateCategory("Cars", "", vehicles,
+		[]entity.AttributeDefinition{{Key: "fuel", Type: entity.AttributeEnum, Options: []string{"petrol", "diesel"}}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		category   string
+		attributes map[string]string
+		wantFacets []string
+		wantErr    bool
+	}{
+		{name: "parent lists subcategory attributes", category: "vehicles", wantFacets: []string{"year", "fuel"}},
+		{name: "parent filters on subcategory attribute", category: "vehicles",
+			attributes: map[string]string{"fuel": "petrol"}, wantFacets: []string{"year", "fuel"}},
+		{name: "subcategory only has its own", category: "cars", wantFacets: []string{"fuel"}},
+		{name: "subcategory does not filter on parent attribute", category: "cars",
+			attributes: map[string]string{"year": "1970"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auctionRepository := newFakeAuctionRepository()
+			auctionUseCase := &auctionUseCase{
+				auctionRepository:  auctionRepository,
+				categoryRepository: newFakeCategoryRepository(vehicles, cars),
+			}
+
+			output, err := auctionUseCase.FindAuctions(context.Background(),
+				AuctionListInputDTO{Category: tt.category, Attributes: tt.attributes})
+
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var facets []string
+			for _, facet := range output.Facets {
+				facets = append(facets, facet.Key)
+			}
+			require.Equal(t, tt.wantFacets, facets)
+			require.Len(t, auctionRepository.queries[0].Attributes, len(tt.attributes))
+		})
+	}
+}
@@ -2,16 +2,29 @@ package usecase
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity"
 )
 
-func (bu *bidUseCase) FindBidByAuctionId(ctx context.Context, auctionId string) ([]BidOutputDTO, error) {
-	bidList, err := bu.BidRepository.FindBidByAuctionId(ctx, auctionId)
+func (bu *bidUseCase) FindBidByAuctionId(
+	ctx context.Context,
+	auctionId string,
+	input BidListInputDTO,
+) (*BidListOutputDTO, error) {
+	page := entity.PageRequest{Cursor: input.Cursor, Limit: input.Limit, Sort: input.Sort}
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+	if page.Sort == "" {
+		page.Sort = entity.BidSortNewest
+	}
+
+	bidsPage, err := bu.BidRepository.FindBidByAuctionId(ctx, auctionId, page)
 	if err != nil {
 		return nil, err
 	}
 
-	var bidOutputList []BidOutputDTO
-	for _, bid := range bidList {
+	bidOutputList := make([]BidOutputDTO, 0, len(bidsPage.Items))
+	for _, bid := range bidsPage.Items {
 		bidOutputList = append(bidOutputList, BidOutputDTO{
 			Id:        bid.Id,
 			UserId:    bid.UserId,
@@ -21,7 +34,12 @@ func (bu *bidUseCase) FindBidByAuctionId(ctx context.Context, auctionId string)
 		})
 	}
 
-	return bidOutputList, nil
+	return &BidListOutputDTO{
+		Bids:       bidOutputList,
+		NextCursor: bidsPage.NextCursor,
+		HasMore:    bidsPage.HasMore,
+		Total:      bidsPage.Total,
+	}, nil
 }
 
 func (bu *bidUseCase) FindWinningBidByAuctionId(
@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"fullcycle-auction_go/internal/entity"
 	"fullcycle-auction_go/internal/repository"
+	"time"
 )
 
 type (
@@ -10,16 +12,55 @@ type (
 		userRepository repository.UserRepository
 	}
 
+	UserInputDTO struct {
+		Name        string `json:"name" binding:"required,min=2,max=100"`
+		Email       string `json:"email" binding:"required,email"`
+		DisplayName string `json:"display_name" binding:"omitempty,min=2,max=50"`
+	}
+
+	UserUpdateInputDTO struct {
+		Name        *string     `json:"name" binding:"omitempty,min=2,max=100"`
+		Email       *string     `json:"email" binding:"omitempty,email"`
+		DisplayName *string     `json:"display_name" binding:"omitempty,min=2,max=50"`
+		Status      *UserStatus `json:"status" binding:"omitempty,oneof=0 1 2"`
+	}
+
+	UserListInputDTO struct {
+		Page     int64 `form:"page" binding:"omitempty,min=1"`
+		PageSize int64 `form:"page_size" binding:"omitempty,min=1,max=100"`
+	}
+
 	UserOutputDTO struct {
-		Id   string `json:"id"`
-		Name string `json:"name"`
+		Id          string     `json:"id"`
+		Name        string     `json:"name"`
+		Email       string     `json:"email,omitempty"`
+		DisplayName string     `json:"display_name,omitempty"`
+		Status      UserStatus `json:"status"`
+		CreatedAt   *time.Time `json:"created_at,omitempty" time_format:"2006-01-02 15:04:05"`
+	}
+
+	UserListOutputDTO struct {
+		Users    []UserOutputDTO `json:"users"`
+		Page     int64           `json:"page"`
+		PageSize int64           `json:"page_size"`
+		Total    int64           `json:"total"`
 	}
 
 	UserUseCase interface {
+		CreateUser(ctx context.Context, input UserInputDTO) (*UserOutputDTO, error)
+
+		UpdateUser(ctx context.Context, id string, input UserUpdateInputDTO) (*UserOutputDTO, error)
+
 		FindUserById(ctx context.Context, id string) (*UserOutputDTO, error)
+
+		FindUsers(ctx context.Context, input UserListInputDTO) (*UserListOutputDTO, error)
 	}
+
+	UserStatus int64
 )
 
+const defaultUserPageSize = 20
+
 func NewUserUseCase(userRepository repository.UserRepository) UserUseCase {
 	return &userUseCase{userRepository: userRepository}
 }
@@ -30,8 +71,54 @@ func (u *userUseCase) FindUserById(ctx context.Context, id string) (*UserOutputD
 		return nil, err
 	}
 
-	return &UserOutputDTO{
-		Id:   user.Id,
-		Name: user.Name,
+	output := newUserOutputDTO(user)
+	return &output, nil
+}
+
+func (u *userUseCase) FindUsers(ctx context.Context, input UserListInputDTO) (*UserListOutputDTO, error) {
+	page, pageSize := input.Page, input.PageSize
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = defaultUserPageSize
+	}
+
+	users, total, err := u.userRepository.FindUsers(ctx, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	userOutputs := make([]UserOutputDTO, 0, len(users))
+	for _, user := range users {
+		userOutputs = append(userOutputs, newUserOutputDTO(&user))
+	}
+
+	return &UserListOutputDTO{
+		Users:    userOutputs,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
 	}, nil
 }
+
+// Public leaves out the contact details that only the user and admins see.
+func (u UserOutputDTO) Public() UserOutputDTO {
+	u.Email = ""
+	return u
+}
+
+func newUserOutputDTO(user *entity.User) UserOutputDTO {
+	output := UserOutputDTO{
+		Id:          user.Id,
+		Name:        user.Name,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Status:      UserStatus(user.Status),
+	}
+	if !user.CreatedAt.IsZero() {
+		createdAt := user.CreatedAt
+		output.CreatedAt = &createdAt
+	}
+	return output
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/repository"
+	"os"
+	"time"
+)
+
+type (
+	IdempotentResponseDTO struct {
+		StatusCode  int
+		ContentType string
+		Body        []byte
+	}
+
+	IdempotencyUseCase interface {
+		// Begin claims key for a new request, or returns the stored response
+		// when the request is an identical retry of a finished one.
+		Begin(ctx context.Context, key, method, path string, body []byte) (*IdempotentResponseDTO, error)
+
+		Complete(ctx context.Context, key string, response IdempotentResponseDTO) error
+
+		// Abort releases key so that a failed request can be retried.
+		Abort(ctx context.Context, key string) error
+	}
+
+	idempotencyUseCase struct {
+		idempotencyRepository repository.IdempotencyRepository
+		ttl                   time.Duration
+	}
+)
+
+func NewIdempotencyUseCase(idempotencyRepository repository.IdempotencyRepository) IdempotencyUseCase {
+	return &idempotencyUseCase{
+		idempotencyRepository: idempotencyRepository,
+		ttl:                   getIdempotencyKeyTTL(),
+	}
+}
+
+func (iu *idempotencyUseCase) Begin(
+	ctx context.Context,
+	key, method, path string,
+	body []byte,
+) (*IdempotentResponseDTO, error) {
+	requestHash := entity.HashIdempotentRequest(method, path, body)
+	record, err := entity.CreateIdempotencyRecord(key, requestHash, iu.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	err = iu.idempotencyRepository.CreateRecord(ctx, record)
+	if err == nil || !isConflict(err) {
+		return nil, err
+	}
+
+	stored, err := iu.idempotencyRepository.FindRecord(ctx, key)
+	if isNotFound(err) {
+		// The stored record expired but was not removed yet.
+		if err := iu.idempotencyRepository.DeleteRecord(ctx, key); err != nil {
+			return nil, err
+		}
+		return nil, iu.idempotencyRepository.CreateRecord(ctx, record)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stored.Matches(requestHash); err != nil {
+		return nil, err
+	}
+
+	return &IdempotentResponseDTO{
+		StatusCode:  stored.StatusCode,
+		ContentType: stored.ContentType,
+		Body:        stored.Body,
+	}, nil
+}
+
+func (iu *idempotencyUseCase) Complete(ctx context.Context, key string, response IdempotentResponseDTO) error {
+	return iu.idempotencyRepository.CompleteRecord(ctx, &entity.IdempotencyRecord{
+		Key:         key,
+		Completed:   true,
+		StatusCode:  response.StatusCode,
+		ContentType: response.ContentType,
+		Body:        response.Body,
+	})
+}
+
+func (iu *idempotencyUseCase) Abort(ctx context.Context, key string) error {
+	return iu.idempotencyRepository.DeleteRecord(ctx, key)
+}
+
+func getIdempotencyKeyTTL() time.Duration {
+	idempotencyKeyTTL := os.Getenv("IDEMPOTENCY_KEY_TTL")
+	duration, err := time.ParseDuration(idempotencyKeyTTL)
+	if err != nil {
+		return 24 * time.Hour
+	}
+	return duration
+}
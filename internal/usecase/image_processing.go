@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"bytes"
+	"fmt"
+	"fullcycle-auction_go/internal/internal_error"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+)
+
+const (
+	thumbnailSize        = 320
+	thumbnailQuality     = 80
+	thumbnailContentType = "image/jpeg"
+	maxImagePixels       = 24_000_000
+)
+
+// imageExtensions lists the accepted upload formats, sniffed from the file
+// content rather than trusted from the client.
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+type processedImage struct {
+	contentType string
+	extension   string
+	width       int
+	height      int
+	thumbnail   []byte
+}
+
+// processImage checks an upload is an image of an accepted format and a sane
+// size, and renders its thumbnail.
+func processImage(data []byte) (*processedImage, error) {
+	contentType := http.DetectContentType(data)
+	extension, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, internal_error.NewBadRequestError("Image must be a JPEG, PNG or GIF file")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, internal_error.NewBadRequestError("Image file is corrupted")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, internal_error.NewBadRequestError(
+			fmt.Sprintf("Image must have at most %d megapixels", maxImagePixels/1_000_000))
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, internal_error.NewBadRequestError("Image file is corrupted")
+	}
+
+	var thumbnail bytes.Buffer
+	options := &jpeg.Options{Quality: thumbnailQuality}
+	if err := jpeg.Encode(&thumbnail, renderThumbnail(source), options); err != nil {
+		return nil, internal_error.NewInternalServerError("Error trying to render image thumbnail")
+	}
+
+	return &processedImage{
+		contentType: contentType,
+		extension:   extension,
+		width:       config.Width,
+		height:      config.Height,
+		thumbnail:   thumbnail.Bytes(),
+	}, nil
+}
+
+// renderThumbnail scales the image to fit a thumbnailSize square by averaging
+// source pixels, painting transparent areas white.
+func renderThumbnail(source image.Image) image.Image {
+	bounds := source.Bounds()
+	flat := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), source, bounds.Min, draw.Over)
+
+	width, height := fitThumbnail(bounds.Dx(), bounds.Dy())
+	sums := make([][3]uint64, width*height)
+	counts := make([]uint64, width*height)
+	for y := 0; y < bounds.Dy(); y++ {
+		row := y * height / bounds.Dy() * width
+		for x := 0; x < bounds.Dx(); x++ {
+			cell := row + x*width/bounds.Dx()
+			pixel := flat.Pix[y*flat.Stride+x*4:]
+			sums[cell][0] += uint64(pixel[0])
+			sums[cell][1] += uint64(pixel[1])
+			sums[cell][2] += uint64(pixel[2])
+			counts[cell]++
+		}
+	}
+
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	for cell, sum := range sums {
+		count := counts[cell]
+		thumbnail.Pix[cell*4] = uint8(sum[0] / count)
+		thumbnail.Pix[cell*4+1] = uint8(sum[1] / count)
+		thumbnail.Pix[cell*4+2] = uint8(sum[2] / count)
+		thumbnail.Pix[cell*4+3] = 0xff
+	}
+	return thumbnail
+}
+
+// fitThumbnail keeps the aspect ratio; images already small enough are not
+// enlarged.
+func fitThumbnail(width, height int) (int, int) {
+	if width <= thumbnailSize && height <= thumbnailSize {
+		return width, height
+	}
+	if width >= height {
+		return thumbnailSize, max(1, height*thumbnailSize/width)
+	}
+	return max(1, width*thumbnailSize/height), thumbnailSize
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/require"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestProcessImage(t *testing.T) {
+	tests := []struct {
+		name              string
+		data              []byte
+		wantExtension     string
+		wantWidth         int
+		wantHeight        int
+		wantThumbnailSize image.Point
+		wantErr           string
+	}{
+		{name: "large PNG", data: encodeTestImage(t, "png", 800, 400), wantExtension: "png",
+			wantWidth: 800, wantHeight: 400, wantThumbnailSize: image.Pt(320, 160)},
+		{name: "portrait JPEG", data: encodeTestImage(t, "jpeg", 300, 600), wantExtension: "jpg",
+			wantWidth: 300, wantHeight: 600, wantThumbnailSize: image.Pt(160, 320)},
+		{name: "small GIF kept at its size", data: encodeTestImage(t, "gif", 40, 30), wantExtension: "gif",
+			wantWidth: 40, wantHeight: 30, wantThumbnailSize: image.Pt(40, 30)},
+		{name: "not an image", data: []byte("just some text"), wantErr: "JPEG, PNG or GIF"},
+		{name: "corrupted PNG", data: encodeTestImage(t, "png", 10, 10)[:40], wantErr: "corrupted"},
+		{name: "too many pixels", data: resizedPNGHeader(t, 6000, 5000), wantErr: "megapixels"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, err := processImage(tt.data)
+
+			if tt.wantErr != "" {
+				require.True(t, hasErrorKind(err, "bad_request"), "got %v", err)
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantExtension, processed.extension)
+			require.Equal(t, tt.wantWidth, processed.width)
+			require.Equal(t, tt.wantHeight, processed.height)
+
+			thumbnail, format, err := image.Decode(bytes.NewReader(processed.thumbnail))
+			require.NoError(t, err)
+			require.Equal(t, "jpeg", format)
+			require.Equal(t, tt.wantThumbnailSize, thumbnail.Bounds().Size())
+		})
+	}
+}
+
+func TestRenderThumbnailFlattensTransparency(t *testing.T) {
+	source := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	source.Set(1, 0, color.NRGBA{R: 0xff, A: 0xff})
+
+	thumbnail := renderThumbnail(source).(*image.RGBA)
+
+	require.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, thumbnail.RGBAAt(0, 0))
+	require.Equal(t, color.RGBA{R: 0xff, A: 0xff}, thumbnail.RGBAAt(1, 0))
+}
+
+func TestFitThumbnail(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height         int
+		wantWidth, wantHeight int
+	}{
+		{name: "small", width: 100, height: 50, wantWidth: 100, wantHeight: 50},
+		{name: "exact", width: 320, height: 320, wantWidth: 320, wantHeight: 320},
+		{name: "landscape", width: 1280, height: 720, wantWidth: 320, wantHeight: 180},
+		{name: "portrait", width: 720, height: 1280, wantWidth: 180, wantHeight: 320},
+		{name: "thin strip", width: 10000, height: 1, wantWidth: 320, wantHeight: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width, height := fitThumbnail(tt.width, tt.height)
+			require.Equal(t, tt.wantWidth, width)
+			require.Equal(t, tt.wantHeight, height)
+		})
+	}
+}
+
+func encodeTestImage(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	source := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			source.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+
+	var data bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&data, source)
+	case "jpeg":
+		err = jpeg.Encode(&data, source, nil)
+	case "gif":
+		err = gif.Encode(&data, source, nil)
+	}
+	require.NoError(t, err)
+	return data.Bytes()
+}
+
+// resizedPNGHeader returns a small PNG whose header claims another size, so
+// the size check can be exercised without encoding a huge image.
+func resizedPNGHeader(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	data := encodeTestImage(t, "png", 1, 1)
+
+	// The IHDR chunk follows the 8 byte signature: length, type, then the
+	// width and height, and its CRC covers the type and data.
+	const ihdr = 8 + 4
+	binary.BigEndian.PutUint32(data[ihdr+4:], width)
+	binary.BigEndian.PutUint32(data[ihdr+8:], height)
+	binary.BigEndian.PutUint32(data[ihdr+4+13:], crc32.ChecksumIEEE(data[ihdr:ihdr+4+13]))
+	return data
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+func (au *auctionUseCase) SubmitAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error) {
+	return au.changeAuctionStatus(ctx, auctionId, func(auction *entity.Auction) error {
+		return auction.Submit()
+	})
+}
+
+func (au *auctionUseCase) FindPendingAuctions(
+	ctx context.Context,
+	input AuctionListInputDTO,
+) (*AuctionListOutputDTO, error) {
+	input.Status = AuctionStatus(entity.PendingApproval)
+	return au.FindAuctions(ctx, input)
+}
+
+func (au *auctionUseCase) ApproveAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error) {
+	return au.changeAuctionStatus(ctx, auctionId, func(auction *entity.Auction) error {
+		return auction.Approve()
+	})
+}
+
+func (au *auctionUseCase) RejectAuction(
+	ctx context.Context,
+	auctionId string,
+	input AuctionRejectInputDTO,
+) (*AuctionOutputDTO, error) {
+	return au.changeAuctionStatus(ctx, auctionId, func(auction *entity.Auction) error {
+		return auction.Reject(input.Reason)
+	})
+}
+
+func (au *auctionUseCase) CloseAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error) {
+	return au.changeAuctionStatus(ctx, auctionId, func(auction *entity.Auction) error {
+		return auction.ForceClose()
+	})
+}
+
+func (au *auctionUseCase) ReopenAuction(ctx context.Context, auctionId string) (*AuctionOutputDTO, error) {
+	return au.changeAuctionStatus(ctx, auctionId, func(auction *entity.Auction) error {
+		return auction.Reopen()
+	})
+}
+
+func (au *auctionUseCase) changeAuctionStatus(
+	ctx context.Context,
+	auctionId string,
+	transition func(auction *entity.Auction) error,
+) (*AuctionOutputDTO, error) {
+	auction, err := au.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := auction.Status
+	if err := transition(auction); err != nil {
+		return nil, err
+	}
+
+	if err := au.auctionRepository.UpdateAuctionStatus(ctx, auction, previousStatus); err != nil {
+		return nil, err
+	}
+
+	output := newAuctionOutputDTO(auction)
+	return &output, nil
+}
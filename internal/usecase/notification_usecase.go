@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/repository"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// NotificationPreferencesDTO maps every notification type to whether the
+	// user receives it.
+	NotificationPreferencesDTO struct {
+		Preferences map[string]bool `json:"preferences" binding:"required,dive,keys,oneof=outbid winning auction_won auction_ended,endkeys"`
+	}
+
+	NotificationUseCase interface {
+		// HandleAuctionEvent sends the notifications an auction event calls for.
+		HandleAuctionEvent(ctx context.Context, event entity.AuctionEvent)
+
+		FindPreferences(ctx context.Context, userId string) (*NotificationPreferencesDTO, error)
+
+		UpdatePreferences(
+			ctx context.Context, userId string, input NotificationPreferencesDTO) (*NotificationPreferencesDTO, error)
+	}
+
+	notificationUseCase struct {
+		notifier              repository.Notifier
+		preferencesRepository repository.NotificationPreferencesRepository
+		auctionRepository     repository.AuctionRepository
+		userRepository        repository.UserRepository
+		limiter               *notificationLimiter
+	}
+
+	// notificationLimiter caps how many notifications of each type a user gets
+	// per window, so a bidding war does not flood anyone's inbox.
+	notificationLimiter struct {
+		limit     int
+		window    time.Duration
+		windows   map[string]notificationWindow
+		lastSweep time.Time
+		mutex     *sync.Mutex
+	}
+
+	notificationWindow struct {
+		start time.Time
+		count int
+	}
+)
+
+func NewNotificationUseCase(
+	notifier repository.Notifier,
+	preferencesRepository repository.NotificationPreferencesRepository,
+	auctionRepository repository.AuctionRepository,
+	userRepository repository.UserRepository,
+) NotificationUseCase {
+	return &notificationUseCase{
+		notifier:              notifier,
+		preferencesRepository: preferencesRepository,
+		auctionRepository:     auctionRepository,
+		userRepository:        userRepository,
+		limiter: &notificationLimiter{
+			limit:   getNotificationRateLimit(),
+			window:  getNotificationRateWindow(),
+			windows: make(map[string]notificationWindow),
+			mutex:   &sync.Mutex{},
+		},
+	}
+}
+
+func (nu *notificationUseCase) HandleAuctionEvent(ctx context.Context, event entity.AuctionEvent) {
+	switch event.Type {
+	case entity.EventBidAccepted:
+		nu.notifyBid(ctx, event)
+	case entity.EventAuctionCompleted:
+		nu.notifyCompletion(ctx, event)
+	}
+}
+
+// notifyBid tells a bidder taking the lead that they are winning, and the
+// bidder they overtook that they were outbid.
+func (nu *notificationUseCase) notifyBid(ctx context.Context, event entity.AuctionEvent) {
+	if !event.Leading {
+		return
+	}
+
+	auction, err := nu.auctionRepository.FindAuctionById(ctx, event.AuctionId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find auction %s to notify bidders", event.AuctionId), err)
+		return
+	}
+
+	nu.send(ctx, entity.Notification{
+		Type:      entity.NotificationWinning,
+		UserId:    event.Bid.UserId,
+		AuctionId: auction.Id,
+		Subject:   fmt.Sprintf("You are the highest bidder on %s", auction.ProductName),
+		Body: fmt.Sprintf("Your bid of %.2f on %s is currently the highest.\n",
+			event.Bid.Amount, auction.ProductName),
+	})
+
+	if event.PreviousBid != nil && event.PreviousBid.UserId != event.Bid.UserId {
+		nu.send(ctx, entity.Notification{
+			Type:      entity.NotificationOutbid,
+			UserId:    event.PreviousBid.UserId,
+			AuctionId: auction.Id,
+			Subject:   fmt.Sprintf("You were outbid on %s", auction.ProductName),
+			Body: fmt.Sprintf("Someone bid %.2f on %s, above your bid of %.2f.\n",
+				event.Bid.Amount, auction.ProductName, event.PreviousBid.Amount),
+		})
+	}
+}
+
+// notifyCompletion tells the winner they won and the seller how the auction
+// ended.
+func (nu *notificationUseCase) notifyCompletion(ctx context.Context, event entity.AuctionEvent) {
+	auction := event.Auction
+	sold := !auction.IsUnsold(event.Bid)
+
+	sellerNotification := entity.Notification{
+		Type:      entity.NotificationAuctionEnded,
+		UserId:    auction.SellerId,
+		AuctionId: auction.Id,
+		Subject:   fmt.Sprintf("Your auction of %s has ended", auction.ProductName),
+		Body:      fmt.Sprintf("%s ended without reaching its reserve price.\n", auction.ProductName),
+	}
+	if sold {
+		sellerNotification.Body = fmt.Sprintf("%s sold for %.2f.\n", auction.ProductName, event.Bid.Amount)
+	}
+	nu.send(ctx, sellerNotification)
+
+	if sold {
+		nu.send(ctx, entity.Notification{
+			Type:      entity.NotificationAuctionWon,
+			UserId:    event.Bid.UserId,
+			AuctionId: auction.Id,
+			Subject:   fmt.Sprintf("You won %s", auction.ProductName),
+			Body:      fmt.Sprintf("Your bid of %.2f won %s.\n", event.Bid.Amount, auction.ProductName),
+		})
+	}
+}
+
+// send delivers the notification unless the user opted out of its type or is
+// over the rate limit. Failures are logged, never returned to the event bus.
+func (nu *notificationUseCase) send(ctx context.Context, notification entity.Notification) {
+	preferences, err := nu.preferencesRepository.FindNotificationPreferences(ctx, notification.UserId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find notification preferences of user %s", notification.UserId), err)
+		return
+	}
+	if !preferences.IsEnabled(notification.Type) {
+		return
+	}
+	if notification.Type.IsRateLimited() && !nu.limiter.allow(notification.UserId, notification.Type) {
+		return
+	}
+
+	user, err := nu.userRepository.FindUserById(ctx, notification.UserId)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Error trying to find user %s to notify", notification.UserId), err)
+		return
+	}
+	notification.Email = user.Email
+	notification.Name = user.DisplayName
+
+	if err := nu.notifier.Notify(ctx, notification); err != nil {
+		logger.Error(fmt.Sprintf("Error trying to send %s notification to user %s", notification.Type, user.Id), err)
+	}
+}
+
+func (nu *notificationUseCase) FindPreferences(ctx context.Context, userId string) (*NotificationPreferencesDTO, error) {
+	if _, err := nu.userRepository.FindUserById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	preferences, err := nu.preferencesRepository.FindNotificationPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return newNotificationPreferencesDTO(preferences), nil
+}
+
+// UpdatePreferences changes the types given in the input and leaves the
+// others as they were.
+func (nu *notificationUseCase) UpdatePreferences(
+	ctx context.Context,
+	userId string,
+	input NotificationPreferencesDTO,
+) (*NotificationPreferencesDTO, error) {
+	if _, err := nu.userRepository.FindUserById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	preferences, err := nu.preferencesRepository.FindNotificationPreferences(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for notificationType, enabled := range input.Preferences {
+		preferences.Set(entity.NotificationType(notificationType), enabled)
+	}
+
+	if err := nu.preferencesRepository.SaveNotificationPreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+
+	return newNotificationPreferencesDTO(preferences), nil
+}
+
+func (nl *notificationLimiter) allow(userId string, notificationType entity.NotificationType) bool {
+	nl.mutex.Lock()
+	defer nl.mutex.Unlock()
+
+	key := userId + "/" + string(notificationType)
+	now := time.Now()
+	window, ok := nl.windows[key]
+	if !ok || now.Sub(window.start) >= nl.window {
+		window = notificationWindow{start: now}
+	}
+	if window.count >= nl.limit {
+		return false
+	}
+
+	window.count++
+	nl.windows[key] = window
+
+	// Expired windows are swept once per window so idle users do not pile up.
+	if now.Sub(nl.lastSweep) >= nl.window {
+		for id, other := range nl.windows {
+			if now.Sub(other.start) >= nl.window {
+				delete(nl.windows, id)
+			}
+		}
+		nl.lastSweep = now
+	}
+	return true
+}
+
+func newNotificationPreferencesDTO(preferences *entity.NotificationPreferences) *NotificationPreferencesDTO {
+	output := &NotificationPreferencesDTO{Preferences: make(map[string]bool, len(entity.NotificationTypes))}
+	for _, notificationType := range entity.NotificationTypes {
+		output.Preferences[string(notificationType)] = preferences.IsEnabled(notificationType)
+	}
+	return output
+}
+
+func getNotificationRateLimit() int {
+	value, err := strconv.Atoi(os.Getenv("NOTIFICATION_RATE_LIMIT"))
+	if err != nil || value <= 0 {
+		return 20
+	}
+	return value
+}
+
+func getNotificationRateWindow() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("NOTIFICATION_RATE_WINDOW"))
+	if err != nil || duration <= 0 {
+		return time.Hour
+	}
+	return duration
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type sentNotification struct {
+	notificationType entity.NotificationType
+	userId           string
+}
+
+func TestNotificationUseCaseHandleAuctionEvent(t *testing.T) {
+	auction := &entity.Auction{Id: "auction", SellerId: "seller", ProductName: "Camera", Status: entity.Active}
+	bid := func(userId string, amount float64) *entity.Bid {
+		return &entity.Bid{UserId: userId, AuctionId: auction.Id, Amount: amount}
+	}
+	accepted := func(leading bool, newBid, previousBid *entity.Bid) entity.AuctionEvent {
+		event := entity.NewAuctionEvent(entity.EventBidAccepted, auction.Id)
+		event.Bid, event.Leading, event.PreviousBid = newBid, leading, previousBid
+		return event
+	}
+	completed := func(highestBid *entity.Bid) entity.AuctionEvent {
+		event := entity.NewAuctionEvent(entity.EventAuctionCompleted, auction.Id)
+		event.Auction, event.Bid = auction, highestBid
+		return event
+	}
+
+	tests := []struct {
+		name     string
+		events   []entity.AuctionEvent
+		disabled map[string]entity.NotificationType
+		limit    int
+		want     []sentNotification
+	}{
+		{name: "first leading bid", events: []entity.AuctionEvent{accepted(true, bid("a", 10), nil)},
+			want: []sentNotification{{entity.NotificationWinning, "a"}}},
+		{name: "bid overtaking another bidder",
+			events: []entity.AuctionEvent{accepted(true, bid("b", 12), bid("a", 10))},
+			want:   []sentNotification{{entity.NotificationWinning, "b"}, {entity.NotificationOutbid, "a"}}},
+		{name: "leader raising their own bid",
+			events: []entity.AuctionEvent{accepted(true, bid("a", 12), bid("a", 10))},
+			want:   []sentNotification{{entity.NotificationWinning, "a"}}},
+		{name: "bid not leading", events: []entity.AuctionEvent{accepted(false, bid("b", 8), nil)}},
+		{name: "opted out of outbid", disabled: map[string]entity.NotificationType{"a": entity.NotificationOutbid},
+			events: []entity.AuctionEvent{accepted(true, bid("b", 12), bid("a", 10))},
+			want:   []sentNotification{{entity.NotificationWinning, "b"}}},
+		{name: "sold", events: []entity.AuctionEvent{completed(bid("a", 10))},
+			want: []sentNotification{{entity.NotificationAuctionEnded, "seller"}, {entity.NotificationAuctionWon, "a"}}},
+		{name: "unsold", events: []entity.AuctionEvent{completed(nil)},
+			want: []sentNotification{{entity.NotificationAuctionEnded, "seller"}}},
+		{name: "rate limit holds back bidding updates", limit: 1,
+			events: []entity.AuctionEvent{
+				accepted(true, bid("a", 10), nil),
+				accepted(true, bid("b", 12), bid("a", 10)),
+				accepted(true, bid("a", 14), bid("b", 12)),
+			},
+			want: []sentNotification{
+				{entity.NotificationWinning, "a"},
+				{entity.NotificationWinning, "b"},
+				{entity.NotificationOutbid, "a"},
+				{entity.NotificationOutbid, "b"},
+			}},
+		{name: "rate limit never holds back the outcome", limit: 1,
+			events: []entity.AuctionEvent{
+				accepted(true, bid("a", 10), nil),
+				accepted(true, bid("a", 12), bid("a", 10)),
+				completed(bid("a", 12)),
+			},
+			want: []sentNotification{
+				{entity.NotificationWinning, "a"},
+				{entity.NotificationAuctionEnded, "seller"},
+				{entity.NotificationAuctionWon, "a"},
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeNotifier{}
+			preferences := &fakeNotificationPreferencesRepository{preferences: map[string]*entity.NotificationPreferences{}}
+			for userId, notificationType := range tt.disabled {
+				userPreferences := &entity.NotificationPreferences{UserId: userId}
+				userPreferences.Set(notificationType, false)
+				preferences.preferences[userId] = userPreferences
+			}
+			limit := tt.limit
+			if limit == 0 {
+				limit = 100
+			}
+			notificationUseCase := &notificationUseCase{
+				notifier:              notifier,
+				preferencesRepository: preferences,
+				auctionRepository:     newFakeAuctionRepository(auction),
+				userRepository: newFakeUserRepository(
+					&entity.User{Id: "a", Email: "a@example.com"},
+					&entity.User{Id: "b", Email: "b@example.com"},
+					&entity.User{Id: "seller", Email: "seller@example.com"},
+				),
+				limiter: &notificationLimiter{
+					limit:   limit,
+					window:  time.Hour,
+					windows: make(map[string]notificationWindow),
+					mutex:   &sync.Mutex{},
+				},
+			}
+
+			for _, event := range tt.events {
+				notificationUseCase.HandleAuctionEvent(context.Background(), event)
+			}
+
+			var sent []sentNotification
+			for _, notification := range notifier.notifications {
+				sent = append(sent, sentNotification{notification.Type, notification.UserId})
+				require.NotEmpty(t, notification.Email)
+			}
+			require.Equal(t, tt.want, sent)
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"time"
+)
+
+type (
+	QuestionInputDTO struct {
+		Text string `json:"text" binding:"required,min=5,max=500"`
+	}
+
+	AnswerInputDTO struct {
+		Text string `json:"text" binding:"required,min=5,max=500"`
+	}
+
+	QuestionFlagInputDTO struct {
+		Reason string `json:"reason" binding:"required,oneof=spam offensive off_topic other"`
+	}
+
+	QuestionListInputDTO struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+
+	QuestionOutputDTO struct {
+		Id        string           `json:"id"`
+		AuctionId string           `json:"auction_id"`
+		AskerId   string           `json:"asker_id"`
+		Text      string           `json:"text"`
+		Answer    *AnswerOutputDTO `json:"answer,omitempty"`
+		Status    QuestionStatus   `json:"status"`
+		FlagCount int              `json:"flag_count"`
+		Version   int64            `json:"version"`
+		CreatedAt time.Time        `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	}
+
+	AnswerOutputDTO struct {
+		Text       string    `json:"text"`
+		AnsweredAt time.Time `json:"answered_at" time_format:"2006-01-02 15:04:05"`
+	}
+
+	// QuestionListOutputDTO is one page of questions. Closed tells, for the
+	// questions of an auction, that no more can be asked or answered.
+	QuestionListOutputDTO struct {
+		Questions  []QuestionOutputDTO `json:"questions"`
+		Closed     bool                `json:"closed,omitempty"`
+		NextCursor string              `json:"next_cursor,omitempty"`
+		HasMore    bool                `json:"has_more"`
+		Total      int64               `json:"total"`
+	}
+
+	QuestionStatus int64
+
+	QuestionUseCase interface {
+		AskQuestion(ctx context.Context, auctionId, askerId string, input QuestionInputDTO) (*QuestionOutputDTO, error)
+
+		AnswerQuestion(ctx context.Context, auctionId, questionId string, input AnswerInputDTO) (*QuestionOutputDTO, error)
+
+		FlagQuestion(
+			ctx context.Context, auctionId, questionId, userId string, input QuestionFlagInputDTO) (*QuestionOutputDTO, error)
+
+		FindQuestions(ctx context.Context, auctionId string, input QuestionListInputDTO) (*QuestionListOutputDTO, error)
+
+		FindFlaggedQuestions(ctx context.Context, input QuestionListInputDTO) (*QuestionListOutputDTO, error)
+
+		HideQuestion(ctx context.Context, questionId string) (*QuestionOutputDTO, error)
+
+		RestoreQuestion(ctx context.Context, questionId string) (*QuestionOutputDTO, error)
+	}
+
+	questionUseCase struct {
+		questionRepository repository.QuestionRepository
+		auctionRepository  repository.AuctionRepository
+	}
+)
+
+func NewQuestionUseCase(
+	questionRepository repository.QuestionRepository,
+	auctionRepository repository.AuctionRepository,
+) QuestionUseCase {
+	return &questionUseCase{
+		questionRepository: questionRepository,
+		auctionRepository:  auctionRepository,
+	}
+}
+
+func (qu *questionUseCase) AskQuestion(
+	ctx context.Context,
+	auctionId, askerId string,
+	input QuestionInputDTO,
+) (*QuestionOutputDTO, error) {
+	auction, err := qu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := entity.AskQuestion(auction, askerId, input.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := qu.questionRepository.CreateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	output := newQuestionOutputDTO(question)
+	return &output, nil
+}
+
+func (qu *questionUseCase) AnswerQuestion(
+	ctx context.Context,
+	auctionId, questionId string,
+	input AnswerInputDTO,
+) (*QuestionOutputDTO, error) {
+	auction, err := qu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	question, err := qu.findQuestion(ctx, auctionId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := question.AnswerWith(auction, input.Text); err != nil {
+		return nil, err
+	}
+
+	if err := qu.questionRepository.UpdateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	output := newQuestionOutputDTO(question)
+	return &output, nil
+}
+
+func (qu *questionUseCase) FlagQuestion(
+	ctx context.Context,
+	auctionId, questionId, userId string,
+	input QuestionFlagInputDTO,
+) (*QuestionOutputDTO, error) {
+	question, err := qu.findQuestion(ctx, auctionId, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := question.Flag(userId, entity.FlagReason(input.Reason)); err != nil {
+		return nil, err
+	}
+
+	if err := qu.questionRepository.UpdateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	output := newQuestionOutputDTO(question)
+	return &output, nil
+}
+
+func (qu *questionUseCase) FindQuestions(
+	ctx context.Context,
+	auctionId string,
+	input QuestionListInputDTO,
+) (*QuestionListOutputDTO, error) {
+	auction, err := qu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	questionsPage, err := qu.questionRepository.FindQuestionsByAuctionId(ctx, auctionId, newQuestionPageRequest(input))
+	if err != nil {
+		return nil, err
+	}
+
+	output := newQuestionListOutputDTO(questionsPage)
+	output.Closed = !auction.IsOpenForQuestions()
+	return output, nil
+}
+
+func (qu *questionUseCase) FindFlaggedQuestions(
+	ctx context.Context,
+	input QuestionListInputDTO,
+) (*QuestionListOutputDTO, error) {
+	questionsPage, err := qu.questionRepository.FindFlaggedQuestions(ctx, newQuestionPageRequest(input))
+	if err != nil {
+		return nil, err
+	}
+
+	return newQuestionListOutputDTO(questionsPage), nil
+}
+
+func (qu *questionUseCase) HideQuestion(ctx context.Context, questionId string) (*QuestionOutputDTO, error) {
+	return qu.moderateQuestion(ctx, questionId, (*entity.Question).Hide)
+}
+
+func (qu *questionUseCase) RestoreQuestion(ctx context.Context, questionId string) (*QuestionOutputDTO, error) {
+	return qu.moderateQuestion(ctx, questionId, (*entity.Question).Restore)
+}
+
+func (qu *questionUseCase) moderateQuestion(
+	ctx context.Context,
+	questionId string,
+	decide func(question *entity.Question),
+) (*QuestionOutputDTO, error) {
+	question, err := qu.questionRepository.FindQuestionById(ctx, questionId)
+	if err != nil {
+		return nil, err
+	}
+
+	decide(question)
+
+	if err := qu.questionRepository.UpdateQuestion(ctx, question); err != nil {
+		return nil, err
+	}
+
+	output := newQuestionOutputDTO(question)
+	return &output, nil
+}
+
+// findQuestion loads a question through the auction it was asked on, so a
+// question id cannot be used under another auction's path.
+func (qu *questionUseCase) findQuestion(ctx context.Context, auctionId, questionId string) (*entity.Question, error) {
+	question, err := qu.questionRepository.FindQuestionById(ctx, questionId)
+	if err != nil {
+		return nil, err
+	}
+	if question.AuctionId != auctionId {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Question not found with this id = %s", questionId))
+	}
+
+	return question, nil
+}
+
+func newQuestionPageRequest(input QuestionListInputDTO) entity.PageRequest {
+	page := entity.PageRequest{Cursor: input.Cursor, Limit: input.Limit, Sort: entity.QuestionSortNewest}
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+	return page
+}
+
+func newQuestionListOutputDTO(questionsPage *entity.Page[entity.Question]) *QuestionListOutputDTO {
+	questions := make([]QuestionOutputDTO, 0, len(questionsPage.Items))
+	for _, question := range questionsPage.Items {
+		questions = append(questions, newQuestionOutputDTO(&question))
+	}
+
+	return &QuestionListOutputDTO{
+		Questions:  questions,
+		NextCursor: questionsPage.NextCursor,
+		HasMore:    questionsPage.HasMore,
+		Total:      questionsPage.Total,
+	}
+}
+
+func newQuestionOutputDTO(question *entity.Question) QuestionOutputDTO {
+	output := QuestionOutputDTO{
+		Id:        question.Id,
+		AuctionId: question.AuctionId,
+		AskerId:   question.AskerId,
+		Text:      question.Text,
+		Status:    QuestionStatus(question.Status),
+		FlagCount: len(question.Flags),
+		Version:   question.Version,
+		CreatedAt: question.CreatedAt,
+	}
+	if question.Answer != nil {
+		output.Answer = &AnswerOutputDTO{
+			Text:       question.Answer.Text,
+			AnsweredAt: question.Answer.AnsweredAt,
+		}
+	}
+	return output
+}
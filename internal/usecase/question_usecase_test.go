@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newTestQuestionUseCase(t *testing.T, flags ...string) (*questionUseCase, *fakeQuestionRepository, *entity.Question) {
+	t.Helper()
+
+	auction := &entity.Auction{Id: "auction", SellerId: "seller", Status: entity.Active}
+	question, err := entity.AskQuestion(auction, "bidder", "Does it ship abroad?")
+	require.NoError(t, err)
+	for _, userId := range flags {
+		require.NoError(t, question.Flag(userId, entity.FlagSpam))
+	}
+
+	questionRepository := newFakeQuestionRepository(question)
+	return &questionUseCase{
+		questionRepository: questionRepository,
+		auctionRepository:  newFakeAuctionRepository(auction),
+	}, questionRepository, question
+}
+
+func TestFlagQuestion(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      []string
+		auctionId  string
+		userId     string
+		wantStatus QuestionStatus
+		wantCount  int
+		wantErr    string
+	}{
+		{name: "first flag", auctionId: "auction", userId: "a", wantStatus: QuestionStatus(entity.QuestionVisible),
+			wantCount: 1},
+		{name: "flag hiding the question", flags: []string{"a", "b"}, auctionId: "auction", userId: "c",
+			wantStatus: QuestionStatus(entity.QuestionHidden), wantCount: 3},
+		{name: "flagged twice", flags: []string{"a"}, auctionId: "auction", userId: "a", wantErr: "conflict"},
+		{name: "question of another auction", auctionId: "other", userId: "a", wantErr: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questionUseCase, questionRepository, question := newTestQuestionUseCase(t, tt.flags...)
+
+			output, err := questionUseCase.FlagQuestion(context.Background(), tt.auctionId, question.Id, tt.userId,
+				QuestionFlagInputDTO{Reason: string(entity.FlagSpam)})
+
+			if tt.wantErr != "" {
+				require.True(t, hasErrorKind(err, tt.wantErr), "got %v", err)
+				require.Len(t, questionRepository.questions[question.Id].Flags, len(tt.flags))
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantStatus, output.Status)
+			require.Equal(t, tt.wantCount, output.FlagCount)
+			require.False(t, questionRepository.questions[question.Id].Reviewed)
+		})
+	}
+}
+
+func TestModerateQuestion(t *testing.T) {
+	tests := []struct {
+		name       string
+		flags      []string
+		moderate   func(uc QuestionUseCase, ctx context.Context, questionId string) (*QuestionOutputDTO, error)
+		wantStatus entity.QuestionStatus
+	}{
+		{name: "hide", flags: []string{"a"}, moderate: QuestionUseCase.HideQuestion,
+			wantStatus: entity.QuestionHidden},
+		{name: "restore auto-hidden", flags: []string{"a", "b", "c"}, moderate: QuestionUseCase.RestoreQuestion,
+			wantStatus: entity.QuestionVisible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questionUseCase, questionRepository, question := newTestQuestionUseCase(t, tt.flags...)
+			ctx := context.Background()
+
+			output, err := tt.moderate(questionUseCase, ctx, question.Id)
+
+			require.NoError(t, err)
+			require.Equal(t, QuestionStatus(tt.wantStatus), output.Status)
+			require.Equal(t, len(tt.flags), output.FlagCount, "flags are kept")
+			stored := questionRepository.questions[question.Id]
+			require.True(t, stored.Reviewed)
+			require.Zero(t, stored.PendingFlags())
+
+			// A new flag puts the question back in the moderation queue
+			// without hiding it again.
+			output, err = questionUseCase.FlagQuestion(ctx, "auction", question.Id, "z",
+				QuestionFlagInputDTO{Reason: string(entity.FlagOther)})
+			require.NoError(t, err)
+			require.Equal(t, QuestionStatus(tt.wantStatus), output.Status)
+			require.False(t, questionRepository.questions[question.Id].Reviewed)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+)
+
+type (
+	AuctionSearchInputDTO struct {
+		Text  string `form:"q" binding:"required,min=2,max=100"`
+		Limit int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	}
+
+	AuctionSearchResultDTO struct {
+		Auction    AuctionOutputDTO  `json:"auction"`
+		Score      float64           `json:"score"`
+		Highlights map[string]string `json:"highlights,omitempty"`
+	}
+
+	AuctionSearchOutputDTO struct {
+		Results []AuctionSearchResultDTO `json:"results"`
+	}
+)
+
+func (au *auctionUseCase) SearchAuctions(
+	ctx context.Context,
+	input AuctionSearchInputDTO,
+) (*AuctionSearchOutputDTO, error) {
+	limit := input.Limit
+	if limit == 0 {
+		limit = defaultPageSize
+	}
+
+	hits, err := au.auctionSearchRepository.SearchAuctions(ctx, input.Text, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := entity.SearchTerms(input.Text)
+	results := make([]AuctionSearchResultDTO, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, AuctionSearchResultDTO{
+			Auction:    newAuctionOutputDTO(&hit.Auction),
+			Score:      hit.Score,
+			Highlights: highlightAuction(&hit.Auction, terms),
+		})
+	}
+
+	return &AuctionSearchOutputDTO{Results: results}, nil
+}
+
+// highlightAuction returns the searched fields of an auction that contain any
+// of the terms, keyed by their JSON name, with the matches marked.
+func highlightAuction(auction *entity.Auction, terms []string) map[string]string {
+	fields := map[string]string{
+		"product_name": auction.ProductName,
+		"category":     auction.Category,
+		"description":  auction.Description,
+	}
+
+	highlights := make(map[string]string)
+	for name, value := range fields {
+		if highlighted, ok := entity.Highlight(value, terms); ok {
+			highlights[name] = highlighted
+		}
+	}
+
+	return highlights
+}
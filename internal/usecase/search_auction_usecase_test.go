@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/infra/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSearchAuctions(t *testing.T) {
+	auctionUseCase := &auctionUseCase{auctionSearchRepository: memory.NewAuctionSearchRepository(
+		entity.Auction{Id: "lens", ProductName: "Leica lens", Category: "cameras",
+			Description: "Sharp <50mm> lens", Status: entity.Active},
+		entity.Auction{Id: "bag", ProductName: "Camera bag", Category: "bags",
+			Description: "Room for a lens", Status: entity.Active},
+	)}
+
+	tests := []struct {
+		name           string
+		input          AuctionSearchInputDTO
+		wantIds        []string
+		wantHighlights []map[string]string
+	}{
+		{
+			name:    "ranked with highlights",
+			input:   AuctionSearchInputDTO{Text: "lenses"},
+			wantIds: []string{"lens", "bag"},
+			wantHighlights: []map[string]string{
+				{
+					"product_name": "Leica <em>lens</em>",
+					"description":  "Sharp &lt;50mm&gt; <em>lens</em>",
+				},
+				{"description": "Room for a <em>lens</em>"},
+			},
+		},
+		{
+			name:           "limited",
+			input:          AuctionSearchInputDTO{Text: "camera", Limit: 1},
+			wantIds:        []string{"bag"},
+			wantHighlights: []map[string]string{{"product_name": "<em>Camera</em> bag"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := auctionUseCase.SearchAuctions(context.Background(), tt.input)
+			require.NoError(t, err)
+
+			var ids []string
+			var highlights []map[string]string
+			for _, result := range output.Results {
+				ids = append(ids, result.Auction.Id)
+				highlights = append(highlights, result.Highlights)
+			}
+			require.Equal(t, tt.wantIds, ids)
+			require.Equal(t, tt.wantHighlights, highlights)
+		})
+	}
+}
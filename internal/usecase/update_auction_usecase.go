@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+)
+
+func (au *auctionUseCase) UpdateAuction(
+	ctx context.Context,
+	auctionId string,
+	input AuctionUpdateInputDTO,
+) (*AuctionOutputDTO, error) {
+	// Queued bids restrict the edit as much as stored ones.
+	if err := au.bidQueue.FlushBids(ctx); err != nil {
+		return nil, err
+	}
+
+	auction, err := au.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+	if auction.Version != input.Version {
+		return nil, internal_error.NewVersionConflictError("Auction was modified by another request, reload it and try again")
+	}
+
+	highestBid, err := au.bidRepository.FindWinningBidByAuctionId(ctx, auctionId)
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	changes := entity.AuctionChanges{
+		ProductName:  input.ProductName,
+		Description:  input.Description,
+		ReservePrice: input.ReservePrice,
+	}
+	if input.Category != nil || input.Attributes != nil {
+		categorySlug := auction.Category
+		if input.Category != nil {
+			categorySlug = *input.Category
+		}
+		category, err := findCategoryBySlug(ctx, au.categoryRepository, categorySlug)
+		if err != nil {
+			return nil, err
+		}
+
+		// Moving to another category checks the current attributes against
+		// its schema unless new ones are given.
+		attributes := input.Attributes
+		if attributes == nil {
+			attributes = auction.Attributes
+		}
+		if changes.Attributes, err = category.ValidateAttributes(attributes); err != nil {
+			return nil, err
+		}
+		if input.Category != nil {
+			changes.Category = &category.Slug
+		}
+	}
+	if input.Condition != nil {
+		condition := entity.ProductCondition(*input.Condition)
+		changes.Condition = &condition
+	}
+
+	if err := auction.Update(changes, highestBid != nil); err != nil {
+		return nil, err
+	}
+
+	// A bid arriving between the check above and the write must not let
+	// changes bidders relied on through.
+	requireNoBids := highestBid == nil && changes.AffectsBidders()
+	if err := au.auctionRepository.UpdateAuction(ctx, auction, requireNoBids); err != nil {
+		return nil, err
+	}
+
+	output := newAuctionOutputDTO(auction)
+	return &output, nil
+}
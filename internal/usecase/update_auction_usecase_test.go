@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestUpdateAuction(t *testing.T) {
+	productName := "Vintage camera"
+	reservePrice := 80.0
+
+	tests := []struct {
+		name       string
+		input      AuctionUpdateInputDTO
+		storedBids []float64
+		queuedBids []float64
+		wantErr    bool
+		wantKind   string
+	}{
+		{name: "reserve price without bids", input: AuctionUpdateInputDTO{ReservePrice: &reservePrice, Version: 1}},
+		{name: "stale version", input: AuctionUpdateInputDTO{ProductName: &productName, Version: 2}, wantErr: true,
+			wantKind: "version_conflict"},
+		{name: "product name with bids", input: AuctionUpdateInputDTO{ProductName: &productName, Version: 1},
+			storedBids: []float64{10}},
+		{name: "reserve price with stored bid", input: AuctionUpdateInputDTO{ReservePrice: &reservePrice, Version: 1},
+			storedBids: []float64{10}, wantErr: true},
+		{name: "reserve price with queued bid", input: AuctionUpdateInputDTO{ReservePrice: &reservePrice, Version: 1},
+			queuedBids: []float64{10}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &entity.Auction{
+				Id:           uuid.New().String(),
+				SellerId:     uuid.New().String(),
+				ProductName:  "Camera",
+				Category:     "cameras",
+				Description:  "A camera in good shape",
+				Condition:    entity.Used,
+				Status:       entity.Active,
+				ReservePrice: 100,
+				Version:      1,
+				Timestamp:    time.Now(),
+			}
+			auctionRepository := newFakeAuctionRepository(auction)
+			bidRepository := &fakeBidRepository{bids: testBids(auction.Id, tt.storedBids)}
+			bidQueue := &fakeBidQueue{bids: bidRepository, pending: testBids(auction.Id, tt.queuedBids)}
+			auctionUseCase := &auctionUseCase{
+				auctionRepository: auctionRepository,
+				bidRepository:     bidRepository,
+				bidQueue:          bidQueue,
+			}
+
+			output, err := auctionUseCase.UpdateAuction(context.Background(), auction.Id, tt.input)
+
+			require.Equal(t, 1, bidQueue.flushes)
+			stored := auctionRepository.auctions[auction.Id]
+			if tt.wantErr {
+				require.Error(t, err)
+				if tt.wantKind != "" {
+					require.True(t, hasErrorKind(err, tt.wantKind))
+				}
+				require.EqualValues(t, 1, stored.Version)
+				require.Equal(t, 100.0, stored.ReservePrice)
+				return
+			}
+			require.NoError(t, err)
+			require.EqualValues(t, 2, output.Version)
+			require.EqualValues(t, 2, stored.Version)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/repository"
+	"time"
+)
+
+type (
+	WatchlistInputDTO struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+
+	// WatchedAuctionOutputDTO is an auction of a watchlist. EndsAt and the
+	// remaining seconds are only set while the auction is running.
+	WatchedAuctionOutputDTO struct {
+		Auction              AuctionOutputDTO `json:"auction"`
+		WatchedAt            time.Time        `json:"watched_at" time_format:"2006-01-02 15:04:05"`
+		EndsAt               *time.Time       `json:"ends_at,omitempty" time_format:"2006-01-02 15:04:05"`
+		TimeRemainingSeconds int64            `json:"time_remaining_seconds"`
+	}
+
+	WatchlistOutputDTO struct {
+		Auctions   []WatchedAuctionOutputDTO `json:"auctions"`
+		NextCursor string                    `json:"next_cursor,omitempty"`
+		HasMore    bool                      `json:"has_more"`
+		Total      int64                     `json:"total"`
+	}
+
+	WatchlistUseCase interface {
+		WatchAuction(ctx context.Context, userId, auctionId string) (*WatchedAuctionOutputDTO, error)
+
+		UnwatchAuction(ctx context.Context, userId, auctionId string) error
+
+		FindWatchlist(ctx context.Context, userId string, input WatchlistInputDTO) (*WatchlistOutputDTO, error)
+	}
+
+	watchlistUseCase struct {
+		watchlistRepository repository.WatchlistRepository
+		auctionRepository   repository.AuctionRepository
+		userRepository      repository.UserRepository
+		auctionDuration     time.Duration
+	}
+)
+
+func NewWatchlistUseCase(
+	watchlistRepository repository.WatchlistRepository,
+	auctionRepository repository.AuctionRepository,
+	userRepository repository.UserRepository,
+) WatchlistUseCase {
+	return &watchlistUseCase{
+		watchlistRepository: watchlistRepository,
+		auctionRepository:   auctionRepository,
+		userRepository:      userRepository,
+		auctionDuration:     entity.AuctionDuration(),
+	}
+}
+
+func (wu *watchlistUseCase) WatchAuction(
+	ctx context.Context,
+	userId, auctionId string,
+) (*WatchedAuctionOutputDTO, error) {
+	if _, err := wu.userRepository.FindUserById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	auction, err := wu.auctionRepository.FindAuctionById(ctx, auctionId)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := entity.WatchAuction(auction, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if auction.WatcherCount, err = wu.watchlistRepository.AddToWatchlist(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	output := wu.newWatchedAuctionOutputDTO(entry, auction)
+	return &output, nil
+}
+
+func (wu *watchlistUseCase) UnwatchAuction(ctx context.Context, userId, auctionId string) error {
+	return wu.watchlistRepository.RemoveFromWatchlist(ctx, userId, auctionId)
+}
+
+func (wu *watchlistUseCase) FindWatchlist(
+	ctx context.Context,
+	userId string,
+	input WatchlistInputDTO,
+) (*WatchlistOutputDTO, error) {
+	page := entity.PageRequest{Cursor: input.Cursor, Limit: input.Limit, Sort: entity.WatchlistSortNewest}
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+
+	entriesPage, err := wu.watchlistRepository.FindWatchlistByUserId(ctx, userId, page)
+	if err != nil {
+		return nil, err
+	}
+
+	auctionIds := make([]string, 0, len(entriesPage.Items))
+	for _, entry := range entriesPage.Items {
+		auctionIds = append(auctionIds, entry.AuctionId)
+	}
+	auctions, err := wu.auctionRepository.FindAuctionsByIds(ctx, auctionIds)
+	if err != nil {
+		return nil, err
+	}
+
+	auctionsById := make(map[string]*entity.Auction, len(auctions))
+	for index := range auctions {
+		auctionsById[auctions[index].Id] = &auctions[index]
+	}
+
+	outputs := make([]WatchedAuctionOutputDTO, 0, len(entriesPage.Items))
+	for _, entry := range entriesPage.Items {
+		if auction, ok := auctionsById[entry.AuctionId]; ok {
+			outputs = append(outputs, wu.newWatchedAuctionOutputDTO(&entry, auction))
+		}
+	}
+
+	return &WatchlistOutputDTO{
+		Auctions:   outputs,
+		NextCursor: entriesPage.NextCursor,
+		HasMore:    entriesPage.HasMore,
+		Total:      entriesPage.Total,
+	}, nil
+}
+
+func (wu *watchlistUseCase) newWatchedAuctionOutputDTO(
+	entry *entity.WatchlistEntry,
+	auction *entity.Auction,
+) WatchedAuctionOutputDTO {
+	output := WatchedAuctionOutputDTO{
+		Auction:   newAuctionOutputDTO(auction),
+		WatchedAt: entry.CreatedAt,
+	}
+	if auction.Status == entity.Active {
+		endsAt := auction.EndsAt(wu.auctionDuration)
+		output.EndsAt = &endsAt
+		output.TimeRemainingSeconds = int64(max(time.Until(endsAt), 0) / time.Second)
+	}
+	return output
+}
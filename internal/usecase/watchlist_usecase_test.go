@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWatchAuction(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           entity.AuctionStatus
+		watchers         int64
+		alreadyWatching  bool
+		userId           string
+		wantWatcherCount int64
+		wantEndsAt       bool
+		wantErr          string
+	}{
+		{name: "first watcher", status: entity.Active, userId: "user", wantWatcherCount: 1, wantEndsAt: true},
+		{name: "count from the repository", status: entity.Active, watchers: 41, userId: "user",
+			wantWatcherCount: 42, wantEndsAt: true},
+		{name: "already watching", status: entity.Active, alreadyWatching: true, userId: "user", wantErr: "conflict"},
+		{name: "completed auction", status: entity.Completed, userId: "user", wantErr: "bad_request"},
+		{name: "unknown user", status: entity.Active, userId: "ghost", wantErr: "not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &entity.Auction{Id: "auction", Status: tt.status, WatcherCount: tt.watchers, Timestamp: time.Now()}
+			auctionRepository := newFakeAuctionRepository(auction)
+			watchlistRepository := &fakeWatchlistRepository{auctions: auctionRepository}
+			if tt.alreadyWatching {
+				watchlistRepository.entries = []entity.WatchlistEntry{{UserId: "user", AuctionId: "auction"}}
+			}
+			watchlistUseCase := &watchlistUseCase{
+				watchlistRepository: watchlistRepository,
+				auctionRepository:   auctionRepository,
+				userRepository:      newFakeUserRepository(&entity.User{Id: "user"}),
+				auctionDuration:     time.Hour,
+			}
+
+			output, err := watchlistUseCase.WatchAuction(context.Background(), tt.userId, "auction")
+
+			if tt.wantErr != "" {
+				require.True(t, hasErrorKind(err, tt.wantErr), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.wantWatcherCount, output.Auction.WatcherCount)
+			require.Equal(t, tt.wantEndsAt, output.EndsAt != nil)
+		})
+	}
+}
+
+func TestFindWatchlistTimeRemaining(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name              string
+		status            entity.AuctionStatus
+		startedAgo        time.Duration
+		wantEndsAt        bool
+		wantRemainingSecs int64
+	}{
+		{name: "running", status: entity.Active, startedAgo: 15 * time.Minute, wantEndsAt: true,
+			wantRemainingSecs: 45 * 60},
+		{name: "past its end, not closed yet", status: entity.Active, startedAgo: 2 * time.Hour, wantEndsAt: true},
+		{name: "completed", status: entity.Completed, startedAgo: 2 * time.Hour},
+		{name: "cancelled", status: entity.Cancelled, startedAgo: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auction := &entity.Auction{Id: "auction", Status: tt.status, Timestamp: now.Add(-tt.startedAgo)}
+			auctionRepository := newFakeAuctionRepository(auction)
+			watchlistUseCase := &watchlistUseCase{
+				watchlistRepository: &fakeWatchlistRepository{
+					auctions: auctionRepository,
+					entries: []entity.WatchlistEntry{
+						{UserId: "user", AuctionId: "auction"},
+						{UserId: "user", AuctionId: "deleted"},
+						{UserId: "other", AuctionId: "auction"},
+					},
+				},
+				auctionRepository: auctionRepository,
+				auctionDuration:   time.Hour,
+			}
+
+			output, err := watchlistUseCase.FindWatchlist(context.Background(), "user", WatchlistInputDTO{})
+
+			require.NoError(t, err)
+			require.Len(t, output.Auctions, 1)
+			watched := output.Auctions[0]
+			require.Equal(t, tt.wantEndsAt, watched.EndsAt != nil)
+			require.InDelta(t, tt.wantRemainingSecs, watched.TimeRemainingSeconds, 2)
+		})
+	}
+}
@@ -0,0 +1,511 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"fullcycle-auction_go/configuration/logger"
+	"fullcycle-auction_go/internal/entity"
+	"fullcycle-auction_go/internal/internal_error"
+	"fullcycle-auction_go/internal/repository"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	webhookRetryBackoff    = 10 * time.Second
+	webhookMaxRetryBackoff = time.Hour
+
+	// webhookDeliveryLease must outlast a send, or a slow receiver could get
+	// the same delivery twice.
+	webhookDeliveryLease = time.Minute
+
+	// webhookOutboxBatch is how many auctions have their outbox relayed at a
+	// time.
+	webhookOutboxBatch = 100
+)
+
+type (
+	WebhookInputDTO struct {
+		URL        string   `json:"url" binding:"required,url,max=2048"`
+		EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=auction.created bid.accepted auction.completed"`
+		Secret     string   `json:"secret" binding:"omitempty,min=16,max=128"`
+	}
+
+	WebhookOutputDTO struct {
+		Id         string    `json:"id"`
+		OwnerId    string    `json:"owner_id"`
+		URL        string    `json:"url"`
+		EventTypes []string  `json:"event_types"`
+		CreatedAt  time.Time `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	}
+
+	// CreatedWebhookOutputDTO carries the signing secret, which is only ever
+	// returned when the webhook is created.
+	CreatedWebhookOutputDTO struct {
+		WebhookOutputDTO
+		Secret string `json:"secret"`
+	}
+
+	DeliveryListInputDTO struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	}
+
+	// DeliveryOutputDTO is an entry of the delivery log. NextAttemptAt is only
+	// set while the delivery is pending.
+	DeliveryOutputDTO struct {
+		Id            string               `json:"id"`
+		WebhookId     string               `json:"webhook_id"`
+		EventId       string               `json:"event_id"`
+		EventType     string               `json:"event_type"`
+		Status        string               `json:"status"`
+		Payload       json.RawMessage      `json:"payload"`
+		Attempts      []DeliveryAttemptDTO `json:"attempts"`
+		NextAttemptAt *time.Time           `json:"next_attempt_at,omitempty" time_format:"2006-01-02 15:04:05"`
+		RedeliveryOf  string               `json:"redelivery_of,omitempty"`
+		CreatedAt     time.Time            `json:"created_at" time_format:"2006-01-02 15:04:05"`
+	}
+
+	DeliveryAttemptDTO struct {
+		At         time.Time `json:"at" time_format:"2006-01-02 15:04:05"`
+		StatusCode int       `json:"status_code,omitempty"`
+		Error      string    `json:"error,omitempty"`
+		DurationMs int64     `json:"duration_ms"`
+	}
+
+	DeliveryListOutputDTO struct {
+		Deliveries []DeliveryOutputDTO `json:"deliveries"`
+		NextCursor string              `json:"next_cursor,omitempty"`
+		HasMore    bool                `json:"has_more"`
+		Total      int64               `json:"total"`
+	}
+
+	// webhookPayload is the body posted to webhooks. Data depends on the
+	// event type.
+	webhookPayload struct {
+		Id         string      `json:"id"`
+		Type       string      `json:"type"`
+		OccurredAt time.Time   `json:"occurred_at"`
+		Data       interface{} `json:"data"`
+	}
+
+	BidAcceptedEventDTO struct {
+		Bid         BidOutputDTO  `json:"bid"`
+		Leading     bool          `json:"leading"`
+		PreviousBid *BidOutputDTO `json:"previous_bid,omitempty"`
+	}
+
+	AuctionCompletedEventDTO struct {
+		Auction    AuctionOutputDTO `json:"auction"`
+		Sold       bool             `json:"sold"`
+		WinningBid *BidOutputDTO    `json:"winning_bid,omitempty"`
+		EndsAt     time.Time        `json:"ends_at"`
+	}
+
+	WebhookUseCase interface {
+		CreateWebhook(ctx context.Context, ownerId string, input WebhookInputDTO) (*CreatedWebhookOutputDTO, error)
+
+		FindWebhooks(ctx context.Context) ([]WebhookOutputDTO, error)
+
+		FindWebhookById(ctx context.Context, webhookId string) (*WebhookOutputDTO, error)
+
+		DeleteWebhook(ctx context.Context, webhookId string) error
+
+		FindDeliveries(ctx context.Context, webhookId string, input DeliveryListInputDTO) (*DeliveryListOutputDTO, error)
+
+		// Redeliver queues an earlier delivery again, whatever its outcome.
+		Redeliver(ctx context.Context, webhookId, deliveryId string) (*DeliveryOutputDTO, error)
+
+		// HandleAuctionEvent wakes the dispatcher up to relay the outbox.
+		HandleAuctionEvent(ctx context.Context, event entity.AuctionEvent)
+	}
+
+	webhookUseCase struct {
+		webhookRepository repository.WebhookRepository
+		outbox            repository.AuctionEventOutbox
+		sender            repository.WebhookSender
+		maxAttempts       int
+		workers           int
+		dispatchInterval  time.Duration
+		wakeup            chan struct{}
+	}
+)
+
+// NewWebhookUseCase also starts the dispatcher relaying the outbox and
+// sending due deliveries in the background.
+func NewWebhookUseCase(
+	webhookRepository repository.WebhookRepository,
+	outbox repository.AuctionEventOutbox,
+	sender repository.WebhookSender,
+) WebhookUseCase {
+	webhookUseCase := &webhookUseCase{
+		webhookRepository: webhookRepository,
+		outbox:            outbox,
+		sender:            sender,
+		maxAttempts:       getWebhookMaxAttempts(),
+		workers:           getWebhookWorkers(),
+		dispatchInterval:  getWebhookDispatchInterval(),
+		wakeup:            make(chan struct{}, 1),
+	}
+
+	go webhookUseCase.dispatch()
+
+	return webhookUseCase
+}
+
+func (wu *webhookUseCase) CreateWebhook(
+	ctx context.Context,
+	ownerId string,
+	input WebhookInputDTO,
+) (*CreatedWebhookOutputDTO, error) {
+	eventTypes := make([]entity.AuctionEventType, 0, len(input.EventTypes))
+	for _, eventType := range input.EventTypes {
+		eventTypes = append(eventTypes, entity.AuctionEventType(eventType))
+	}
+
+	webhook, err := entity.CreateWebhook(ownerId, input.URL, eventTypes, input.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wu.webhookRepository.CreateWebhook(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	return &CreatedWebhookOutputDTO{
+		WebhookOutputDTO: newWebhookOutputDTO(webhook),
+		Secret:           webhook.Secret,
+	}, nil
+}
+
+func (wu *webhookUseCase) FindWebhooks(ctx context.Context) ([]WebhookOutputDTO, error) {
+	webhooks, err := wu.webhookRepository.FindWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]WebhookOutputDTO, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		outputs = append(outputs, newWebhookOutputDTO(&webhook))
+	}
+
+	return outputs, nil
+}
+
+func (wu *webhookUseCase) FindWebhookById(ctx context.Context, webhookId string) (*WebhookOutputDTO, error) {
+	webhook, err := wu.webhookRepository.FindWebhookById(ctx, webhookId)
+	if err != nil {
+		return nil, err
+	}
+
+	output := newWebhookOutputDTO(webhook)
+	return &output, nil
+}
+
+// DeleteWebhook removes the subscription. Its delivery log is kept, and any
+// pending delivery fails the next time the dispatcher picks it up.
+func (wu *webhookUseCase) DeleteWebhook(ctx context.Context, webhookId string) error {
+	return wu.webhookRepository.DeleteWebhook(ctx, webhookId)
+}
+
+func (wu *webhookUseCase) FindDeliveries(
+	ctx context.Context,
+	webhookId string,
+	input DeliveryListInputDTO,
+) (*DeliveryListOutputDTO, error) {
+	if _, err := wu.webhookRepository.FindWebhookById(ctx, webhookId); err != nil {
+		return nil, err
+	}
+
+	page := entity.PageRequest{Cursor: input.Cursor, Limit: input.Limit, Sort: entity.DeliverySortNewest}
+	if page.Limit == 0 {
+		page.Limit = defaultPageSize
+	}
+
+	deliveriesPage, err := wu.webhookRepository.FindDeliveriesByWebhookId(ctx, webhookId, page)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]DeliveryOutputDTO, 0, len(deliveriesPage.Items))
+	for _, delivery := range deliveriesPage.Items {
+		deliveries = append(deliveries, newDeliveryOutputDTO(&delivery))
+	}
+
+	return &DeliveryListOutputDTO{
+		Deliveries: deliveries,
+		NextCursor: deliveriesPage.NextCursor,
+		HasMore:    deliveriesPage.HasMore,
+		Total:      deliveriesPage.Total,
+	}, nil
+}
+
+func (wu *webhookUseCase) Redeliver(
+	ctx context.Context,
+	webhookId, deliveryId string,
+) (*DeliveryOutputDTO, error) {
+	if _, err := wu.webhookRepository.FindWebhookById(ctx, webhookId); err != nil {
+		return nil, err
+	}
+
+	delivery, err := wu.webhookRepository.FindDeliveryById(ctx, deliveryId)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.WebhookId != webhookId {
+		return nil, internal_error.NewNotFoundError(fmt.Sprintf("Delivery not found with this id = %s", deliveryId))
+	}
+
+	redelivery := delivery.Redeliver()
+	if err := wu.webhookRepository.CreateDeliveries(ctx, []entity.WebhookDelivery{redelivery}); err != nil {
+		return nil, err
+	}
+	wu.nudge()
+
+	output := newDeliveryOutputDTO(&redelivery)
+	return &output, nil
+}
+
+func (wu *webhookUseCase) HandleAuctionEvent(_ context.Context, _ entity.AuctionEvent) {
+	wu.nudge()
+}
+
+// nudge wakes the dispatcher up so new events and deliveries go out right
+// away instead of on the next tick.
+func (wu *webhookUseCase) nudge() {
+	select {
+	case wu.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (wu *webhookUseCase) dispatch() {
+	ticker := time.NewTicker(wu.dispatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-wu.wakeup:
+		}
+
+		wu.relayEvents(context.Background())
+		wu.sendDueDeliveries(context.Background())
+	}
+}
+
+// relayEvents queues the deliveries of the outbox events. Delivery ids are
+// derived from the event, so relaying an event again is harmless.
+func (wu *webhookUseCase) relayEvents(ctx context.Context) {
+	for {
+		events, err := wu.outbox.FindOutboxEvents(ctx, webhookOutboxBatch)
+		if err != nil || len(events) == 0 {
+			return
+		}
+
+		relayed := make([]entity.AuctionEvent, 0, len(events))
+		for _, event := range events {
+			if err := wu.queueDeliveries(ctx, event); err != nil {
+				logger.Error(fmt.Sprintf("Error trying to queue webhook deliveries of event %s", event.Id), err)
+				continue
+			}
+			relayed = append(relayed, event)
+		}
+
+		if len(relayed) == 0 || wu.outbox.AcknowledgeOutboxEvents(ctx, relayed) != nil {
+			return
+		}
+		if len(relayed) < len(events) {
+			// The others are tried again on the next tick.
+			return
+		}
+	}
+}
+
+// queueDeliveries queues a delivery of the event to every webhook subscribed
+// to it.
+func (wu *webhookUseCase) queueDeliveries(ctx context.Context, event entity.AuctionEvent) error {
+	webhooks, err := wu.webhookRepository.FindWebhooksByEventType(ctx, event.Type)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(newWebhookPayload(event))
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, entity.NewWebhookDelivery(&webhook, event.Id, event.Type, payload))
+	}
+
+	return wu.webhookRepository.CreateDeliveries(ctx, deliveries)
+}
+
+// sendDueDeliveries sends the due deliveries with a bounded pool of workers.
+func (wu *webhookUseCase) sendDueDeliveries(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < wu.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				delivery, err := wu.webhookRepository.ClaimDueDelivery(ctx, webhookDeliveryLease)
+				if err != nil || delivery == nil {
+					return
+				}
+
+				wu.send(ctx, delivery)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// send makes one attempt at a delivery and records its outcome.
+func (wu *webhookUseCase) send(ctx context.Context, delivery *entity.WebhookDelivery) {
+	webhook, err := wu.webhookRepository.FindWebhookById(ctx, delivery.WebhookId)
+	if err != nil {
+		if !isNotFound(err) {
+			return
+		}
+
+		delivery.RecordAttempt(entity.DeliveryAttempt{At: time.Now(), Error: "webhook was deleted"},
+			1, webhookRetryBackoff, webhookMaxRetryBackoff)
+		_ = wu.webhookRepository.UpdateDelivery(ctx, delivery)
+		return
+	}
+
+	timestamp := time.Now()
+	headers := map[string]string{
+		"Content-Type":        "application/json",
+		"User-Agent":          "fullcycle-auction-webhooks",
+		"X-Webhook-Id":        delivery.Id,
+		"X-Webhook-Event":     string(delivery.EventType),
+		"X-Webhook-Timestamp": strconv.FormatInt(timestamp.Unix(), 10),
+		"X-Webhook-Signature": entity.SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload),
+	}
+
+	statusCode, err := wu.sender.Send(ctx, webhook.URL, headers, delivery.Payload)
+	attempt := entity.DeliveryAttempt{At: timestamp, StatusCode: statusCode, Duration: time.Since(timestamp)}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+
+	delivery.RecordAttempt(attempt, wu.maxAttempts, webhookRetryBackoff, webhookMaxRetryBackoff)
+	_ = wu.webhookRepository.UpdateDelivery(ctx, delivery)
+}
+
+func newWebhookPayload(event entity.AuctionEvent) webhookPayload {
+	payload := webhookPayload{Id: event.Id, Type: string(event.Type), OccurredAt: event.OccurredAt}
+
+	switch event.Type {
+	case entity.EventAuctionCreated:
+		payload.Data = newAuctionOutputDTO(event.Auction)
+	case entity.EventBidAccepted:
+		data := BidAcceptedEventDTO{Bid: newBidOutputDTO(event.Bid), Leading: event.Leading}
+		if event.PreviousBid != nil {
+			previousBid := newBidOutputDTO(event.PreviousBid)
+			data.PreviousBid = &previousBid
+		}
+		payload.Data = data
+	case entity.EventAuctionCompleted:
+		payload.Data = newAuctionCompletedEventDTO(event)
+	}
+
+	return payload
+}
+
+func newAuctionCompletedEventDTO(event entity.AuctionEvent) AuctionCompletedEventDTO {
+	data := AuctionCompletedEventDTO{
+		Auction: newAuctionOutputDTO(event.Auction),
+		Sold:    !event.Auction.IsUnsold(event.Bid),
+		EndsAt:  event.EndsAt,
+	}
+	if data.Sold {
+		winningBid := newBidOutputDTO(event.Bid)
+		data.WinningBid = &winningBid
+	}
+	return data
+}
+
+func newBidOutputDTO(bid *entity.Bid) BidOutputDTO {
+	return BidOutputDTO{
+		Id:        bid.Id,
+		UserId:    bid.UserId,
+		AuctionId: bid.AuctionId,
+		Amount:    bid.Amount,
+		Timestamp: bid.Timestamp,
+	}
+}
+
+func newWebhookOutputDTO(webhook *entity.Webhook) WebhookOutputDTO {
+	eventTypes := make([]string, 0, len(webhook.EventTypes))
+	for _, eventType := range webhook.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	return WebhookOutputDTO{
+		Id:         webhook.Id,
+		OwnerId:    webhook.OwnerId,
+		URL:        webhook.URL,
+		EventTypes: eventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+func newDeliveryOutputDTO(delivery *entity.WebhookDelivery) DeliveryOutputDTO {
+	output := DeliveryOutputDTO{
+		Id:           delivery.Id,
+		WebhookId:    delivery.WebhookId,
+		EventId:      delivery.EventId,
+		EventType:    string(delivery.EventType),
+		Status:       string(delivery.Status),
+		Payload:      json.RawMessage(delivery.Payload),
+		Attempts:     make([]DeliveryAttemptDTO, 0, len(delivery.Attempts)),
+		RedeliveryOf: delivery.RedeliveryOf,
+		CreatedAt:    delivery.CreatedAt,
+	}
+	if delivery.Status == entity.DeliveryPending {
+		nextAttemptAt := delivery.NextAttemptAt
+		output.NextAttemptAt = &nextAttemptAt
+	}
+	for _, attempt := range delivery.Attempts {
+		output.Attempts = append(output.Attempts, DeliveryAttemptDTO{
+			At:         attempt.At,
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			DurationMs: attempt.Duration.Milliseconds(),
+		})
+	}
+	return output
+}
+
+func getWebhookMaxAttempts() int {
+	value, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || value <= 0 {
+		return 8
+	}
+	return value
+}
+
+func getWebhookWorkers() int {
+	value, err := strconv.Atoi(os.Getenv("WEBHOOK_WORKERS"))
+	if err != nil || value <= 0 {
+		return 4
+	}
+	return value
+}
+
+func getWebhookDispatchInterval() time.Duration {
+	duration, err := time.ParseDuration(os.Getenv("WEBHOOK_DISPATCH_INTERVAL"))
+	if err != nil || duration <= 0 {
+		return 5 * time.Second
+	}
+	return duration
+}
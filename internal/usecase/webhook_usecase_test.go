@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"fullcycle-auction_go/internal/entity"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhookUseCaseRelayEvents(t *testing.T) {
+	bidWebhook := entity.Webhook{Id: "bids", URL: "https://example.com/bids",
+		EventTypes: []entity.AuctionEventType{entity.EventBidAccepted}}
+	allWebhook := entity.Webhook{Id: "all", URL: "https://example.com/all", EventTypes: entity.WebhookEventTypes}
+
+	created := entity.NewAuctionEvent(entity.EventAuctionCreated, "auction")
+	created.Auction = &entity.Auction{Id: "auction"}
+	accepted := entity.NewAuctionEvent(entity.EventBidAccepted, "auction")
+	accepted.Bid = &entity.Bid{Id: "bid", AuctionId: "auction", Amount: 10}
+
+	tests := []struct {
+		name            string
+		webhooks        []entity.Webhook
+		failCreate      bool
+		failAcknowledge bool
+		relays          int
+		wantDeliveries  int
+		wantLeft        int
+	}{
+		{name: "no webhook subscribed", relays: 1},
+		{name: "delivered to each subscribed webhook", webhooks: []entity.Webhook{bidWebhook, allWebhook},
+			relays: 1, wantDeliveries: 3},
+		{name: "kept in the outbox when deliveries cannot be stored", webhooks: []entity.Webhook{allWebhook},
+			failCreate: true, relays: 1, wantLeft: 2},
+		{name: "relayed again after an unacknowledged relay", webhooks: []entity.Webhook{allWebhook},
+			failAcknowledge: true, relays: 2, wantDeliveries: 2, wantLeft: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepository := newFakeWebhookRepository(tt.webhooks...)
+			webhookRepository.failCreate = tt.failCreate
+			outbox := &fakeAuctionEventOutbox{
+				events:          []entity.AuctionEvent{created, accepted},
+				failAcknowledge: tt.failAcknowledge,
+			}
+			webhookUseCase := &webhookUseCase{webhookRepository: webhookRepository, outbox: outbox}
+
+			for i := 0; i < tt.relays; i++ {
+				webhookUseCase.relayEvents(context.Background())
+			}
+
+			require.Len(t, webhookRepository.deliveries, tt.wantDeliveries)
+			require.Len(t, outbox.events, tt.wantLeft)
+			for _, delivery := range webhookRepository.deliveries {
+				webhook, err := webhookRepository.FindWebhookById(context.Background(), delivery.WebhookId)
+				require.NoError(t, err)
+				require.True(t, webhook.Subscribes(delivery.EventType))
+			}
+		})
+	}
+}
+
+// countingSender answers every delivery after a while, counting how many
+// are in flight at once.
+type countingSender struct {
+	sent, inFlight, maxInFlight int
+	mutex                       sync.Mutex
+}
+
+func (s *countingSender) Send(_ context.Context, _ string, _ map[string]string, _ []byte) (int, error) {
+	s.mutex.Lock()
+	s.inFlight++
+	s.maxInFlight = max(s.maxInFlight, s.inFlight)
+	s.mutex.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.inFlight--
+	s.sent++
+	return 200, nil
+}
+
+func TestWebhookUseCaseSendDueDeliveries(t *testing.T) {
+	webhook := entity.Webhook{Id: "webhook", URL: "https://example.com", Secret: "whsec_0123456789abcdef",
+		EventTypes: entity.WebhookEventTypes}
+
+	tests := []struct {
+		name       string
+		workers    int
+		deliveries int
+	}{
+		{name: "single worker", workers: 1, deliveries: 5},
+		{name: "pool smaller than the backlog", workers: 3, deliveries: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepository := newFakeWebhookRepository(webhook)
+			for i := 0; i < tt.deliveries; i++ {
+				event := entity.NewAuctionEvent(entity.EventBidAccepted, "auction")
+				delivery := entity.NewWebhookDelivery(&webhook, event.Id, event.Type, []byte("{}"))
+				require.NoError(t, webhookRepository.CreateDeliveries(context.Background(),
+					[]entity.WebhookDelivery{delivery}))
+			}
+			sender := &countingSender{}
+			webhookUseCase := &webhookUseCase{
+				webhookRepository: webhookRepository,
+				sender:            sender,
+				maxAttempts:       3,
+				workers:           tt.workers,
+			}
+
+			webhookUseCase.sendDueDeliveries(context.Background())
+
+			require.Equal(t, tt.deliveries, sender.sent)
+			require.LessOrEqual(t, sender.maxInFlight, tt.workers)
+			for _, delivery := range webhookRepository.deliveries {
+				require.Equal(t, entity.DeliverySucceeded, delivery.Status)
+			}
+		})
+	}
+}
+
+func TestGetWebhookWorkers(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "16", want: 16},
+		{value: "", want: 4},
+		{value: "0", want: 4},
+		{value: "many", want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Setenv("WEBHOOK_WORKERS", tt.value)
+			require.Equal(t, tt.want, getWebhookWorkers())
+		})
+	}
+}